@@ -8,6 +8,7 @@ if [ $# -ne 2 ]; then
 fi
 
 LOGS_DIR="$USER_HOME/cloud_monitoring_tool.log"
+DATA_DIR="$USER_HOME/cloud_monitoring_tool_data"
 
 echo "Deploying $NAME"
 
@@ -35,9 +36,11 @@ else
     exit 1
 fi
 
+mkdir -p "$DATA_DIR"
+
 echo "Running $NAME >> $LOGS_DIR"
 
-if docker run --env-file "$ENV_FILE" "$AWS_ECR_URI":latest > "$LOGS_DIR" 2>&1; then
+if docker run --env-file "$ENV_FILE" -v "$DATA_DIR":/data "$AWS_ECR_URI":latest > "$LOGS_DIR" 2>&1; then
   echo "Finished running $NAME"
 else
   echo "Something went wrong when running $NAME"
@@ -49,6 +52,6 @@ crontab -r
 
 echo "Adding scheduled cronjob"
 
-echo "0 8 * * MON docker run --env-file $ENV_FILE $AWS_ECR_URI:latest >> $LOGS_DIR 2>&1" | crontab -
+echo "0 8 * * MON docker run --env-file $ENV_FILE -v $DATA_DIR:/data $AWS_ECR_URI:latest >> $LOGS_DIR 2>&1" | crontab -
 
 echo "Deployment of $NAME is complete!"
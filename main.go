@@ -1,24 +1,220 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/couchbaselabs/cloud-monitoring-tool/views/export"
+	"github.com/couchbaselabs/cloud-monitoring-tool/views/graph"
+	"github.com/couchbaselabs/cloud-monitoring-tool/views/html"
+	"github.com/couchbaselabs/cloud-monitoring-tool/views/metrics"
 	"github.com/couchbaselabs/cloud-monitoring-tool/views/slackbot"
 	"log"
+	"strings"
+	"time"
 )
 
+const modeReport = "report"
+const modeInteractions = "interactions"
+const modeExporter = "exporter"
+
+const (
+	viewSlack = "slack"
+	viewGraph = "graph"
+	viewJSON  = "json"
+	viewCSV   = "csv"
+	viewHTML  = "html"
+)
+
+var allViews = []string{viewSlack, viewGraph, viewJSON, viewCSV, viewHTML}
+
+type reportOptions struct {
+	views      map[string]bool
+	jsonOutput string
+	csvOutput  string
+	htmlOutput string
+	htmlSlack  bool
+}
+
 func main() {
-	ctx, err := monitoring.AnalyseAWS()
+	mode := flag.String("mode", modeReport, "report to scan and post to Slack, interactions to serve Slack button clicks, exporter to serve Prometheus metrics")
+	views := flag.String("views", strings.Join([]string{viewSlack, viewGraph}, ","), fmt.Sprintf("comma separated views to run after a scan, any of %s", strings.Join(allViews, ", ")))
+	jsonOutput := flag.String("json-output", export.StdoutPath, "file the json view writes the inventory to, - for stdout")
+	csvOutput := flag.String("csv-output", export.StdoutPath, "directory the csv view writes one file per resource type to, - for a single CSV on stdout")
+	htmlOutput := flag.String("html-output", "report.html", "file the html view writes the report to, empty to skip writing it to disk")
+	htmlSlack := flag.Bool("html-slack", false, "upload the html report to the Slack channel")
+	flag.Parse()
+
+	switch *mode {
+	case modeReport:
+		enabledViews, err := parseViews(*views)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if enabledViews[viewJSON] && enabledViews[viewCSV] && *jsonOutput == export.StdoutPath && *csvOutput == export.StdoutPath {
+			log.Fatalf("The %s and %s views cannot both write to stdout", viewJSON, viewCSV)
+		}
+
+		report(&reportOptions{
+			views:      enabledViews,
+			jsonOutput: *jsonOutput,
+			csvOutput:  *csvOutput,
+			htmlOutput: *htmlOutput,
+			htmlSlack:  *htmlSlack,
+		})
+	case modeInteractions:
+		serveInteractions()
+	case modeExporter:
+		log.Fatal(metrics.NewExporter(monitoring.Analyse).Run())
+	default:
+		log.Fatalf("Unknown mode %q, expected %s, %s or %s", *mode, modeReport, modeInteractions, modeExporter)
+	}
+}
+
+func parseViews(value string) (map[string]bool, error) {
+	enabledViews := map[string]bool{}
+
+	for _, view := range strings.Split(value, ",") {
+		view = strings.TrimSpace(view)
+		if view == "" {
+			continue
+		}
+
+		known := false
+		for _, knownView := range allViews {
+			known = known || view == knownView
+		}
+
+		if !known {
+			return nil, fmt.Errorf("unknown view %q, expected any of %s", view, strings.Join(allViews, ", "))
+		}
+
+		enabledViews[view] = true
+	}
+
+	return enabledViews, nil
+}
+
+func report(options *reportOptions) {
+	ctx, err := monitoring.Analyse()
 
 	if err != nil {
 		log.Fatalf("Something went horribly wrong when analysing clouds: %s", err)
 	}
 
-	slackBot := &slackbot.CloudMonitoringSlackBot{GlobalCloudContext: ctx}
+	if monitoring.ExpiryReaperEnabled() {
+		reaper, err := monitoring.NewExpiryReaper()
+
+		if err != nil {
+			log.Fatalf("Something went horribly wrong when preparing the expiry reaper: %s", err)
+		}
+
+		reaper.Reap(ctx)
+	}
+
+	if historyDBPath := monitoring.GetHistoryDBPath(); historyDBPath != "" {
+		recordHistory(ctx, historyDBPath)
+	}
+
+	if options.views[viewGraph] {
+		graphExporter := &graph.OwnershipGraphExporter{GlobalCloudContext: ctx}
+
+		if err := graphExporter.Export(); err != nil {
+			log.Printf("Unable to export ownership graph: %s", err)
+		}
+	}
 
-	err = slackBot.PostThreadedReport()
+	inventoryExporter := &export.InventoryExporter{GlobalCloudContext: ctx}
+
+	if options.views[viewJSON] {
+		if err := inventoryExporter.ExportJSON(options.jsonOutput); err != nil {
+			log.Fatalf("Something went horribly wrong when exporting JSON: %s", err)
+		}
+	}
+
+	if options.views[viewCSV] {
+		if err := inventoryExporter.ExportCSV(options.csvOutput); err != nil {
+			log.Fatalf("Something went horribly wrong when exporting CSV: %s", err)
+		}
+	}
+
+	if options.views[viewHTML] {
+		exportHTMLReport(ctx, options)
+	}
+
+	if options.views[viewSlack] {
+		slackBot := &slackbot.CloudMonitoringSlackBot{GlobalCloudContext: ctx}
+
+		err = slackBot.PostThreadedReport()
+
+		if err != nil {
+			log.Fatalf("Something went horribly wrong when posting to Slack: %s", err)
+		}
+
+		if slackbot.OwnerDigestsEnabled() {
+			err = slackBot.PostOwnerDigests()
+
+			if err != nil {
+				log.Fatalf("Something went horribly wrong when sending Slack digests: %s", err)
+			}
+		}
+	}
+}
+
+func exportHTMLReport(ctx *monitoring.GlobalCloudContext, options *reportOptions) {
+	htmlReport := &html.HTMLReport{GlobalCloudContext: ctx}
+
+	if options.htmlOutput != "" {
+		if err := htmlReport.WriteFile(options.htmlOutput); err != nil {
+			log.Fatalf("Something went horribly wrong when writing the HTML report: %s", err)
+		}
+	}
+
+	if options.htmlSlack {
+		content, err := htmlReport.RenderBytes()
+
+		if err != nil {
+			log.Fatalf("Something went horribly wrong when rendering the HTML report: %s", err)
+		}
+
+		if err := slackbot.UploadFile(html.GetReportFileName(time.Now()), "Cloud report", content); err != nil {
+			log.Fatalf("Something went horribly wrong when uploading the HTML report: %s", err)
+		}
+	}
+}
+
+// recordHistory only logs failures, the report is still worth posting without the diff
+func recordHistory(ctx *monitoring.GlobalCloudContext, historyDBPath string) {
+	store, err := monitoring.OpenHistoryStore(historyDBPath)
+
+	if err != nil {
+		log.Printf("Unable to record scan history: %s", err)
+		return
+	}
+
+	defer store.Close()
+
+	ctx.History, err = store.Record(ctx, time.Now())
 
 	if err != nil {
-		log.Fatalf("Something went horribly wrong when posting to Slack: %s", err)
+		log.Printf("Unable to record scan history: %s", err)
 	}
 }
 
+func serveInteractions() {
+	actioner, err := monitoring.NewAWSResourceActioner()
+
+	if err != nil {
+		log.Fatalf("Something went horribly wrong when preparing AWS actions: %s", err)
+	}
+
+	server, err := slackbot.NewInteractionServer(actioner)
+
+	if err != nil {
+		log.Fatalf("Something went horribly wrong when starting the Slack interaction server: %s", err)
+	}
+
+	log.Fatal(server.ListenAndServe())
+}
@@ -0,0 +1,323 @@
+package monitoring
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ResourceActionKeep    = "keep"
+	ResourceActionStop    = "stop"
+	ResourceActionDelete  = "delete"
+	ResourceActionNotMine = "not-mine"
+)
+
+const KeepUntilTag = "cloud-monitoring-tool:keep-until"
+const KeptByTag = "cloud-monitoring-tool:kept-by"
+
+// NotMineTag holds the Slack user ID of whoever marked the resource as not theirs
+const NotMineTag = "cloud-monitoring-tool:not-mine"
+
+const KeepDuration = 7 * 24 * time.Hour
+const keepUntilLayout = "2006-01-02"
+
+// Stacks can only be updated, and so tagged, when they are in one of these states
+var cloudformationStableStatuses = map[string]bool{
+	cloudformation.StackStatusCreateComplete:         true,
+	cloudformation.StackStatusUpdateComplete:         true,
+	cloudformation.StackStatusUpdateRollbackComplete: true,
+	cloudformation.StackStatusImportComplete:         true,
+}
+
+// Assumed role credentials are refreshed this long before they expire
+const credentialsExpiryMargin = 5 * time.Minute
+
+// ResourceReference identifies a single resource well enough to act on it outside of a scan
+type ResourceReference struct {
+	Account string   `json:"a"`
+	Region  string   `json:"r"`
+	Type    NodeType `json:"t"`
+	ID      string   `json:"i"`
+}
+
+func (resource CloudResource) GetKeptUntil() (time.Time, bool) {
+	value, ok := resource.Tags[KeepUntilTag]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	keepUntil, err := time.Parse(keepUntilLayout, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return keepUntil, keepUntil.After(time.Now())
+}
+
+type AWSResourceActioner struct {
+	session     *session.Session
+	roleArns    map[string]string
+	credentials map[string]*sts.Credentials
+	mutex       sync.Mutex
+}
+
+func NewAWSResourceActioner() (*AWSResourceActioner, error) {
+	awsSession, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create AWS session: %s", err)
+	}
+
+	roleArns := map[string]string{}
+
+	for _, awsRoleArn := range split(os.Getenv(awsRoleArns)) {
+		roleArns[getStringInBetween(awsRoleArn, "arn:aws:iam::", ":")] = awsRoleArn
+	}
+
+	return &AWSResourceActioner{
+		session:     awsSession,
+		roleArns:    roleArns,
+		credentials: make(map[string]*sts.Credentials),
+	}, nil
+}
+
+// getCredentials assumes the same role the scan used for the account, reusing credentials until they are about to expire
+func (actioner *AWSResourceActioner) getCredentials(account string) (*sts.Credentials, error) {
+	actioner.mutex.Lock()
+	defer actioner.mutex.Unlock()
+
+	if awsCredentials, ok := actioner.credentials[account]; ok && awsCredentials.Expiration != nil && time.Now().Add(credentialsExpiryMargin).Before(*awsCredentials.Expiration) {
+		return awsCredentials, nil
+	}
+
+	awsRoleArn, ok := actioner.roleArns[account]
+	if !ok {
+		return nil, fmt.Errorf("no AWS role configured for account %s", account)
+	}
+
+	awsCredentials, err := assumeRole(awsRoleArn, actioner.session, awsSessionName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to assume AWS role %s: %s", awsRoleArn, err)
+	}
+
+	actioner.credentials[account] = awsCredentials
+	return awsCredentials, nil
+}
+
+// Perform carries out the action on behalf of actor and describes the outcome
+func (actioner *AWSResourceActioner) Perform(resource ResourceReference, action string, actor string) (string, error) {
+	awsCredentials, err := actioner.getCredentials(resource.Account)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("%s requested %s of %s %s in account %s region %s", actor, action, resource.Type, resource.ID, resource.Account, resource.Region)
+	config := getAWSConfig(awsCredentials, resource.Region)
+
+	switch action {
+	case ResourceActionKeep:
+		keepUntil := time.Now().Add(KeepDuration).UTC().Format(keepUntilLayout)
+		err = actioner.tag(config, resource, map[string]string{KeepUntilTag: keepUntil, KeptByTag: actor})
+		return fmt.Sprintf("Kept until %s", keepUntil), err
+	case ResourceActionNotMine:
+		err = actioner.tag(config, resource, map[string]string{NotMineTag: actor})
+		return "Marked as not theirs", err
+	case ResourceActionStop:
+		return actioner.stop(config, resource)
+	case ResourceActionDelete:
+		return actioner.delete(config, resource)
+	}
+
+	return "", fmt.Errorf("unknown action %s", action)
+}
+
+func (actioner *AWSResourceActioner) stop(config *aws.Config, resource ResourceReference) (string, error) {
+	switch resource.Type {
+	case NodeEC2Instance:
+		_, err := ec2.New(actioner.session, config).StopInstances(&ec2.StopInstancesInput{
+			InstanceIds: []*string{aws.String(resource.ID)},
+		})
+		return "Stopped", err
+	}
+
+	return "", fmt.Errorf("%s cannot be stopped, only deleted", resource.Type)
+}
+
+func (actioner *AWSResourceActioner) delete(config *aws.Config, resource ResourceReference) (string, error) {
+	switch resource.Type {
+	case NodeEC2Instance:
+		_, err := ec2.New(actioner.session, config).TerminateInstances(&ec2.TerminateInstancesInput{
+			InstanceIds: []*string{aws.String(resource.ID)},
+		})
+		return "Terminated", err
+	case NodeEBSVolume:
+		_, err := ec2.New(actioner.session, config).DeleteVolume(&ec2.DeleteVolumeInput{
+			VolumeId: aws.String(resource.ID),
+		})
+		return "Deleted", err
+	case NodeEKSCluster:
+		_, err := eks.New(actioner.session, config).DeleteCluster(&eks.DeleteClusterInput{
+			Name: aws.String(resource.ID),
+		})
+		return "Deletion started", err
+	case NodeCloudformationStack:
+		_, err := cloudformation.New(actioner.session, config).DeleteStack(&cloudformation.DeleteStackInput{
+			StackName: aws.String(resource.ID),
+		})
+		return "Deletion started", err
+	}
+
+	return "", fmt.Errorf("%s cannot be deleted", resource.Type)
+}
+
+func (actioner *AWSResourceActioner) tag(config *aws.Config, resource ResourceReference, tags map[string]string) error {
+	switch resource.Type {
+	case NodeEC2Instance, NodeEBSVolume:
+		var ec2Tags []*ec2.Tag
+		for key, value := range tags {
+			ec2Tags = append(ec2Tags, &ec2.Tag{Key: aws.String(key), Value: aws.String(value)})
+		}
+
+		_, err := ec2.New(actioner.session, config).CreateTags(&ec2.CreateTagsInput{
+			Resources: []*string{aws.String(resource.ID)},
+			Tags:      ec2Tags,
+		})
+		return err
+	case NodeEKSCluster:
+		eksService := eks.New(actioner.session, config)
+
+		clusterDescription, err := eksService.DescribeCluster(&eks.DescribeClusterInput{Name: aws.String(resource.ID)})
+		if err != nil {
+			return err
+		}
+
+		_, err = eksService.TagResource(&eks.TagResourceInput{
+			ResourceArn: clusterDescription.Cluster.Arn,
+			Tags:        aws.StringMap(tags),
+		})
+		return err
+	case NodeCloudformationStack:
+		return tagCloudformationStack(cloudformation.New(actioner.session, config), resource.ID, tags)
+	}
+
+	return fmt.Errorf("%s cannot be tagged", resource.Type)
+}
+
+// Stacks can only be tagged through a stack update, which keeps the previous template and parameters. The update is made
+// through a change set so it can be abandoned unless it only changes tags, since an update can also pick up changed
+// dynamic references or parameter store values and modify or replace the stack's resources.
+func tagCloudformationStack(cloudformationService *cloudformation.CloudFormation, stackId string, tags map[string]string) error {
+	result, err := cloudformationService.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(stackId)})
+	if err != nil {
+		return err
+	}
+
+	if len(result.Stacks) == 0 {
+		return fmt.Errorf("stack %s not found", stackId)
+	}
+
+	stack := result.Stacks[0]
+
+	if !cloudformationStableStatuses[aws.StringValue(stack.StackStatus)] {
+		return fmt.Errorf("stack %s cannot be tagged while it is %s", stackId, aws.StringValue(stack.StackStatus))
+	}
+
+	stackTags := map[string]string{}
+
+	for _, tag := range stack.Tags {
+		stackTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	for key, value := range tags {
+		stackTags[key] = value
+	}
+
+	changeSetName := fmt.Sprintf("cloud-monitoring-tool-tags-%d", time.Now().Unix())
+	input := &cloudformation.CreateChangeSetInput{
+		StackName:           aws.String(stackId),
+		ChangeSetName:       aws.String(changeSetName),
+		ChangeSetType:       aws.String(cloudformation.ChangeSetTypeUpdate),
+		UsePreviousTemplate: aws.Bool(true),
+		Capabilities:        stack.Capabilities,
+	}
+
+	for _, parameter := range stack.Parameters {
+		input.Parameters = append(input.Parameters, &cloudformation.Parameter{
+			ParameterKey:     parameter.ParameterKey,
+			UsePreviousValue: aws.Bool(true),
+		})
+	}
+
+	for key, value := range stackTags {
+		input.Tags = append(input.Tags, &cloudformation.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	if _, err := cloudformationService.CreateChangeSet(input); err != nil {
+		return err
+	}
+
+	changeSet := &cloudformation.DescribeChangeSetInput{StackName: aws.String(stackId), ChangeSetName: aws.String(changeSetName)}
+
+	if err := checkTagOnlyChangeSet(cloudformationService, changeSet); err != nil {
+		if _, deleteErr := cloudformationService.DeleteChangeSet(&cloudformation.DeleteChangeSetInput{StackName: aws.String(stackId), ChangeSetName: aws.String(changeSetName)}); deleteErr != nil {
+			log.Printf("Unable to delete change set %s of stack %s: %s", changeSetName, stackId, deleteErr)
+		}
+
+		return err
+	}
+
+	_, err = cloudformationService.ExecuteChangeSet(&cloudformation.ExecuteChangeSetInput{StackName: aws.String(stackId), ChangeSetName: aws.String(changeSetName)})
+	return err
+}
+
+// checkTagOnlyChangeSet waits for the change set to be created and fails if it would do more than update tags in place
+func checkTagOnlyChangeSet(cloudformationService *cloudformation.CloudFormation, changeSet *cloudformation.DescribeChangeSetInput) error {
+	if err := cloudformationService.WaitUntilChangeSetCreateComplete(changeSet); err != nil {
+		description, describeErr := cloudformationService.DescribeChangeSet(changeSet)
+		if describeErr == nil && description.StatusReason != nil {
+			return fmt.Errorf("unable to create change set: %s", aws.StringValue(description.StatusReason))
+		}
+
+		return fmt.Errorf("unable to create change set: %s", err)
+	}
+
+	input := *changeSet
+
+	for {
+		description, err := cloudformationService.DescribeChangeSet(&input)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range description.Changes {
+			resourceChange := change.ResourceChange
+			if resourceChange == nil {
+				continue
+			}
+
+			if aws.StringValue(resourceChange.Action) != cloudformation.ChangeActionModify || aws.StringValue(resourceChange.Replacement) != cloudformation.ReplacementFalse {
+				return fmt.Errorf("tagging would %s %s, tag the stack by hand instead", strings.ToLower(aws.StringValue(resourceChange.Action)), aws.StringValue(resourceChange.LogicalResourceId))
+			}
+
+			for _, scope := range resourceChange.Scope {
+				if aws.StringValue(scope) != cloudformation.ResourceAttributeTags {
+					return fmt.Errorf("tagging would change the %s of %s, tag the stack by hand instead", strings.ToLower(aws.StringValue(scope)), aws.StringValue(resourceChange.LogicalResourceId))
+				}
+			}
+		}
+
+		if description.NextToken == nil {
+			return nil
+		}
+		input.NextToken = description.NextToken
+	}
+}
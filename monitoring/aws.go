@@ -0,0 +1,121 @@
+package monitoring
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/elasticsearchservice"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+// Adapters from the aws-sdk-go types to the provider neutral model, so SDK structs never end up in resources
+
+func getEC2VolumeIDs(blockDeviceMappings []*ec2.InstanceBlockDeviceMapping) []string {
+	var volumeIds []string
+
+	for _, blockDevice := range blockDeviceMappings {
+		if blockDevice == nil || blockDevice.Ebs == nil || blockDevice.Ebs.VolumeId == nil {
+			continue
+		}
+
+		volumeIds = append(volumeIds, *blockDevice.Ebs.VolumeId)
+	}
+
+	return volumeIds
+}
+
+func getEC2Tags(tags []*ec2.Tag) map[string]string {
+	ec2Tags := map[string]string{}
+
+	for _, tag := range tags {
+		if tag == nil || tag.Key == nil {
+			continue
+		}
+
+		ec2Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return ec2Tags
+}
+
+func newCloudformationStackResource(summary *cloudformation.StackResourceSummary) StackResource {
+	return StackResource{
+		LogicalID:  aws.StringValue(summary.LogicalResourceId),
+		PhysicalID: aws.StringValue(summary.PhysicalResourceId),
+		Type:       aws.StringValue(summary.ResourceType),
+		Status:     aws.StringValue(summary.ResourceStatus),
+	}
+}
+
+func getRDSTags(tags []*rds.Tag) map[string]string {
+	rdsTags := map[string]string{}
+
+	for _, tag := range tags {
+		if tag == nil || tag.Key == nil {
+			continue
+		}
+
+		rdsTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return rdsTags
+}
+
+func getElastiCacheTags(tags []*elasticache.Tag) map[string]string {
+	elastiCacheTags := map[string]string{}
+
+	for _, tag := range tags {
+		if tag == nil || tag.Key == nil {
+			continue
+		}
+
+		elastiCacheTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return elastiCacheTags
+}
+
+func getOpenSearchTags(tags []*elasticsearchservice.Tag) map[string]string {
+	openSearchTags := map[string]string{}
+
+	for _, tag := range tags {
+		if tag == nil || tag.Key == nil {
+			continue
+		}
+
+		openSearchTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return openSearchTags
+}
+
+func getELBTags(tags []*elb.Tag) map[string]string {
+	elbTags := map[string]string{}
+
+	for _, tag := range tags {
+		if tag == nil || tag.Key == nil {
+			continue
+		}
+
+		elbTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return elbTags
+}
+
+func getELBv2Tags(tags []*elbv2.Tag) map[string]string {
+	elbv2Tags := map[string]string{}
+
+	for _, tag := range tags {
+		if tag == nil || tag.Key == nil {
+			continue
+		}
+
+		elbv2Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	return elbv2Tags
+}
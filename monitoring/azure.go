@@ -0,0 +1,700 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const azureSubscriptionIdsEnv = "AZURE_SUBSCRIPTION_IDS"
+const azureTenantIdEnv = "AZURE_TENANT_ID"
+const azureClientIdEnv = "AZURE_CLIENT_ID"
+const azureClientSecretEnv = "AZURE_CLIENT_SECRET"
+const azureManagementEndpointEnv = "AZURE_MANAGEMENT_ENDPOINT"
+const azureAuthorityHostEnv = "AZURE_AUTHORITY_HOST"
+
+const LaunchedBySourceAzureSystemData = "Azure systemData"
+
+const defaultAzureManagementEndpoint = "https://management.azure.com"
+const defaultAzureAuthorityHost = "https://login.microsoftonline.com"
+
+// The token is always requested for the public cloud management API, a replaced endpoint is expected to ignore it
+const azureManagementScope = "https://management.azure.com/.default"
+
+const (
+	azureResourceGroupsApiVersion  = "2021-04-01"
+	azureVirtualMachinesApiVersion = "2021-07-01"
+	azureScaleSetsApiVersion       = "2021-07-01"
+	azureDisksApiVersion           = "2021-04-01"
+	azureAKSApiVersion             = "2021-05-01"
+)
+
+// AzureClient lists resources from the Azure Resource Manager REST API. Both endpoints can be replaced to run against
+// a local server.
+type AzureClient struct {
+	ManagementEndpoint string
+	AuthorityHost      string
+	TenantID           string
+	ClientID           string
+	ClientSecret       string
+	HTTPClient         *http.Client
+	token              string
+	tokenExpiry        time.Time
+}
+
+// AzureInventory holds everything listed in one subscription, collectors pick out the resources of their region
+type AzureInventory struct {
+	ResourceGroups  []AzureResourceGroup
+	VirtualMachines []AzureVirtualMachine
+	ScaleSets       []AzureVirtualMachineScaleSet
+	ManagedDisks    []AzureManagedDisk
+	AKSClusters     []AKSCluster
+}
+
+type azureResource struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Location   string            `json:"location"`
+	Tags       map[string]string `json:"tags"`
+	ManagedBy  string            `json:"managedBy"`
+	SKU        *azureSKU         `json:"sku"`
+	SystemData *azureSystemData  `json:"systemData"`
+	Properties json.RawMessage   `json:"properties"`
+}
+
+type azureSKU struct {
+	Name     string `json:"name"`
+	Capacity int64  `json:"capacity"`
+}
+
+type azureSystemData struct {
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type azureListResponse struct {
+	Value    []azureResource `json:"value"`
+	NextLink string          `json:"nextLink"`
+}
+
+type azureTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type azureResourceGroupProperties struct {
+	ProvisioningState string `json:"provisioningState"`
+}
+
+type azureVirtualMachineProperties struct {
+	TimeCreated     time.Time `json:"timeCreated"`
+	HardwareProfile struct {
+		VMSize string `json:"vmSize"`
+	} `json:"hardwareProfile"`
+	StorageProfile struct {
+		OSDisk struct {
+			ManagedDisk *azureManagedDiskReference `json:"managedDisk"`
+		} `json:"osDisk"`
+		DataDisks []struct {
+			ManagedDisk *azureManagedDiskReference `json:"managedDisk"`
+		} `json:"dataDisks"`
+	} `json:"storageProfile"`
+	InstanceView *struct {
+		Statuses []struct {
+			Code string `json:"code"`
+		} `json:"statuses"`
+	} `json:"instanceView"`
+}
+
+type azureScaleSetProperties struct {
+	TimeCreated       time.Time `json:"timeCreated"`
+	ProvisioningState string    `json:"provisioningState"`
+}
+
+type azureManagedDiskReference struct {
+	ID string `json:"id"`
+}
+
+type azureDiskProperties struct {
+	TimeCreated time.Time `json:"timeCreated"`
+	DiskSizeGB  int64     `json:"diskSizeGB"`
+	DiskState   string    `json:"diskState"`
+}
+
+type azureAKSProperties struct {
+	KubernetesVersion string `json:"kubernetesVersion"`
+	NodeResourceGroup string `json:"nodeResourceGroup"`
+	ProvisioningState string `json:"provisioningState"`
+	AgentPoolProfiles []struct {
+		Count int `json:"count"`
+	} `json:"agentPoolProfiles"`
+}
+
+func NewAzureClient() *AzureClient {
+	managementEndpoint := os.Getenv(azureManagementEndpointEnv)
+	if managementEndpoint == "" {
+		managementEndpoint = defaultAzureManagementEndpoint
+	}
+
+	authorityHost := os.Getenv(azureAuthorityHostEnv)
+	if authorityHost == "" {
+		authorityHost = defaultAzureAuthorityHost
+	}
+
+	return &AzureClient{
+		ManagementEndpoint: strings.TrimSuffix(managementEndpoint, "/"),
+		AuthorityHost:      strings.TrimSuffix(authorityHost, "/"),
+		TenantID:           os.Getenv(azureTenantIdEnv),
+		ClientID:           os.Getenv(azureClientIdEnv),
+		ClientSecret:       os.Getenv(azureClientSecretEnv),
+		HTTPClient:         &http.Client{Timeout: time.Minute},
+	}
+}
+
+func getAzureSubscriptionIds() []string {
+	var subscriptionIds []string
+
+	for _, subscriptionId := range split(os.Getenv(azureSubscriptionIdsEnv)) {
+		if subscriptionId = strings.TrimSpace(subscriptionId); subscriptionId != "" {
+			subscriptionIds = append(subscriptionIds, subscriptionId)
+		}
+	}
+
+	return subscriptionIds
+}
+
+func AzureConfigured() bool {
+	return len(getAzureSubscriptionIds()) > 0
+}
+
+func (client *AzureClient) getToken() (string, error) {
+	if client.token != "" && time.Now().Before(client.tokenExpiry) {
+		return client.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {client.ClientID},
+		"client_secret": {client.ClientSecret},
+		"scope":         {azureManagementScope},
+	}
+
+	response, err := client.HTTPClient.PostForm(fmt.Sprintf("%s/%s/oauth2/v2.0/token", client.AuthorityHost, client.TenantID), form)
+	if err != nil {
+		return "", fmt.Errorf("unable to get Azure access token: %s", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		return "", fmt.Errorf("unable to get Azure access token: %s %s", response.Status, strings.TrimSpace(string(body)))
+	}
+
+	var tokenResponse azureTokenResponse
+	if err := json.NewDecoder(response.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("unable to read Azure access token: %s", err)
+	}
+
+	// Renew a minute early so the token does not expire part way through a listing
+	client.token = tokenResponse.AccessToken
+	client.tokenExpiry = time.Now().Add(time.Duration(tokenResponse.ExpiresIn)*time.Second - time.Minute)
+
+	return client.token, nil
+}
+
+func (client *AzureClient) list(path string, apiVersion string, query url.Values) ([]azureResource, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api-version", apiVersion)
+
+	var resources []azureResource
+	next := fmt.Sprintf("%s%s?%s", client.ManagementEndpoint, path, query.Encode())
+
+	for next != "" {
+		token, err := client.getToken()
+		if err != nil {
+			return nil, err
+		}
+
+		var page azureListResponse
+		if err := getJSON(client.HTTPClient, next, token, &page); err != nil {
+			return nil, fmt.Errorf("unable to list %s: %s", path, err)
+		}
+
+		resources = append(resources, page.Value...)
+		next = page.NextLink
+	}
+
+	return resources, nil
+}
+
+func newAzureCloudResource(resource azureResource, subscriptionId string) CloudResource {
+	cloudResource := CloudResource{
+		ID:       resource.ID,
+		Name:     resource.Name,
+		Provider: ProviderAzure,
+		Region:   getAzureLocation(resource.Location),
+		Tags:     resource.Tags,
+		Account:  subscriptionId,
+	}
+
+	if cloudResource.Tags == nil {
+		cloudResource.Tags = map[string]string{}
+	}
+
+	if resource.SystemData == nil {
+		return setLaunchedBy(cloudResource, "")
+	}
+
+	cloudResource.CreatedAt = resource.SystemData.CreatedAt
+
+	if resource.SystemData.CreatedBy == "" {
+		return setLaunchedBy(cloudResource, "")
+	}
+
+	cloudResource.LaunchedBy = resource.SystemData.CreatedBy
+	cloudResource.LaunchedBySource = LaunchedBySourceAzureSystemData
+
+	return cloudResource
+}
+
+// Locations are case and space insensitive in the API, e.g. "East US" and "eastus"
+func getAzureLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// Resource IDs are case insensitive, so they are normalised before being compared
+func getAzureResourceKey(id string) string {
+	return strings.ToLower(id)
+}
+
+func getAzureResourceGroupName(id string) string {
+	parts := strings.Split(id, "/")
+
+	for idx := 0; idx < len(parts)-1; idx++ {
+		if strings.EqualFold(parts[idx], "resourceGroups") {
+			return parts[idx+1]
+		}
+	}
+
+	return ""
+}
+
+func (client *AzureClient) getResourceGroups(subscriptionId string) ([]AzureResourceGroup, error) {
+	resources, err := client.list(fmt.Sprintf("/subscriptions/%s/resourcegroups", subscriptionId), azureResourceGroupsApiVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure resource groups %w", err)
+	}
+
+	var resourceGroups []AzureResourceGroup
+
+	for _, resource := range resources {
+		var properties azureResourceGroupProperties
+		if len(resource.Properties) > 0 {
+			if err := json.Unmarshal(resource.Properties, &properties); err != nil {
+				return nil, fmt.Errorf("unable to read Azure resource group %s: %s", resource.ID, err)
+			}
+		}
+
+		resourceGroup := NewAzureResourceGroup()
+		resourceGroup.CloudResource = newAzureCloudResource(resource, subscriptionId)
+		resourceGroup.ProvisioningState = properties.ProvisioningState
+		resourceGroup.ManagedBy = resource.ManagedBy
+		resourceGroups = append(resourceGroups, *resourceGroup)
+	}
+
+	log.Printf("Found %d Azure resource groups in subscription %s", len(resourceGroups), subscriptionId)
+	return resourceGroups, nil
+}
+
+func (client *AzureClient) getVirtualMachines(subscriptionId string) ([]AzureVirtualMachine, error) {
+	// statusOnly adds the instance view, which holds the power state
+	query := url.Values{"statusOnly": {"true"}}
+	resources, err := client.list(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/virtualMachines", subscriptionId), azureVirtualMachinesApiVersion, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure virtual machines %w", err)
+	}
+
+	var virtualMachines []AzureVirtualMachine
+
+	for _, resource := range resources {
+		var properties azureVirtualMachineProperties
+		if len(resource.Properties) > 0 {
+			if err := json.Unmarshal(resource.Properties, &properties); err != nil {
+				return nil, fmt.Errorf("unable to read Azure virtual machine %s: %s", resource.ID, err)
+			}
+		}
+
+		virtualMachine := NewAzureVirtualMachine()
+		virtualMachine.CloudResource = newAzureCloudResource(resource, subscriptionId)
+		virtualMachine.ResourceGroup = getAzureResourceGroupName(resource.ID)
+		virtualMachine.InstanceType = properties.HardwareProfile.VMSize
+
+		if virtualMachine.CreatedAt.IsZero() {
+			virtualMachine.CreatedAt = properties.TimeCreated
+		}
+
+		if managedDisk := properties.StorageProfile.OSDisk.ManagedDisk; managedDisk != nil {
+			virtualMachine.VolumeIDs = append(virtualMachine.VolumeIDs, managedDisk.ID)
+		}
+
+		for _, dataDisk := range properties.StorageProfile.DataDisks {
+			if dataDisk.ManagedDisk != nil {
+				virtualMachine.VolumeIDs = append(virtualMachine.VolumeIDs, dataDisk.ManagedDisk.ID)
+			}
+		}
+
+		if properties.InstanceView != nil {
+			for _, status := range properties.InstanceView.Statuses {
+				if strings.HasPrefix(status.Code, "PowerState/") {
+					virtualMachine.State = strings.TrimPrefix(status.Code, "PowerState/")
+				}
+			}
+		}
+
+		virtualMachines = append(virtualMachines, *virtualMachine)
+	}
+
+	log.Printf("Found %d Azure virtual machines in subscription %s", len(virtualMachines), subscriptionId)
+	return virtualMachines, nil
+}
+
+// getVirtualMachineScaleSets lists the scale sets that back AKS node pools and other autoscaled workloads. Their
+// instances are not listed as virtual machines, so they are reported through the scale set.
+func (client *AzureClient) getVirtualMachineScaleSets(subscriptionId string) ([]AzureVirtualMachineScaleSet, error) {
+	resources, err := client.list(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/virtualMachineScaleSets", subscriptionId), azureScaleSetsApiVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure virtual machine scale sets %w", err)
+	}
+
+	var scaleSets []AzureVirtualMachineScaleSet
+
+	for _, resource := range resources {
+		var properties azureScaleSetProperties
+		if len(resource.Properties) > 0 {
+			if err := json.Unmarshal(resource.Properties, &properties); err != nil {
+				return nil, fmt.Errorf("unable to read Azure virtual machine scale set %s: %s", resource.ID, err)
+			}
+		}
+
+		scaleSet := NewAzureVirtualMachineScaleSet()
+		scaleSet.CloudResource = newAzureCloudResource(resource, subscriptionId)
+		scaleSet.ResourceGroup = getAzureResourceGroupName(resource.ID)
+		scaleSet.State = properties.ProvisioningState
+
+		if resource.SKU != nil {
+			scaleSet.InstanceType = resource.SKU.Name
+			scaleSet.Capacity = resource.SKU.Capacity
+		}
+
+		if scaleSet.CreatedAt.IsZero() {
+			scaleSet.CreatedAt = properties.TimeCreated
+		}
+
+		scaleSets = append(scaleSets, *scaleSet)
+	}
+
+	log.Printf("Found %d Azure virtual machine scale sets in subscription %s", len(scaleSets), subscriptionId)
+	return scaleSets, nil
+}
+
+func (client *AzureClient) getManagedDisks(subscriptionId string) ([]AzureManagedDisk, error) {
+	resources, err := client.list(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/disks", subscriptionId), azureDisksApiVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure managed disks %w", err)
+	}
+
+	var managedDisks []AzureManagedDisk
+
+	for _, resource := range resources {
+		var properties azureDiskProperties
+		if len(resource.Properties) > 0 {
+			if err := json.Unmarshal(resource.Properties, &properties); err != nil {
+				return nil, fmt.Errorf("unable to read Azure managed disk %s: %s", resource.ID, err)
+			}
+		}
+
+		managedDisk := NewAzureManagedDisk()
+		managedDisk.CloudResource = newAzureCloudResource(resource, subscriptionId)
+		managedDisk.ResourceGroup = getAzureResourceGroupName(resource.ID)
+		managedDisk.SizeGiB = properties.DiskSizeGB
+		managedDisk.State = properties.DiskState
+		managedDisk.ManagedBy = resource.ManagedBy
+
+		if resource.SKU != nil {
+			managedDisk.VolumeType = resource.SKU.Name
+		}
+
+		if managedDisk.CreatedAt.IsZero() {
+			managedDisk.CreatedAt = properties.TimeCreated
+		}
+
+		managedDisks = append(managedDisks, *managedDisk)
+	}
+
+	log.Printf("Found %d Azure managed disks in subscription %s", len(managedDisks), subscriptionId)
+	return managedDisks, nil
+}
+
+func (client *AzureClient) getAKSClusters(subscriptionId string) ([]AKSCluster, error) {
+	resources, err := client.list(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.ContainerService/managedClusters", subscriptionId), azureAKSApiVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AKS clusters %w", err)
+	}
+
+	var aksClusters []AKSCluster
+
+	for _, resource := range resources {
+		var properties azureAKSProperties
+		if len(resource.Properties) > 0 {
+			if err := json.Unmarshal(resource.Properties, &properties); err != nil {
+				return nil, fmt.Errorf("unable to read AKS cluster %s: %s", resource.ID, err)
+			}
+		}
+
+		aksCluster := NewAKSCluster()
+		aksCluster.CloudResource = newAzureCloudResource(resource, subscriptionId)
+		aksCluster.ResourceGroup = getAzureResourceGroupName(resource.ID)
+		aksCluster.Version = properties.KubernetesVersion
+		aksCluster.NodeResourceGroupName = properties.NodeResourceGroup
+		aksCluster.State = properties.ProvisioningState
+
+		for _, agentPool := range properties.AgentPoolProfiles {
+			aksCluster.NodeCount += agentPool.Count
+		}
+
+		aksClusters = append(aksClusters, *aksCluster)
+	}
+
+	log.Printf("Found %d AKS clusters in subscription %s", len(aksClusters), subscriptionId)
+	return aksClusters, nil
+}
+
+// getInventory lists every supported resource type in a subscription. Types that cannot be listed are reported as scan
+// errors and the rest of the subscription is still scanned.
+func (client *AzureClient) getInventory(subscriptionId string, globalCtx *GlobalCloudContext) *AzureInventory {
+	inventory := &AzureInventory{}
+
+	addScanError := func(operation string, nodeType NodeType, err error) {
+		globalCtx.AddScanError(ScanError{
+			Account:   subscriptionId,
+			Kind:      ScanErrorCollect,
+			NodeTypes: []NodeType{nodeType},
+			Operation: operation,
+			Message:   err.Error(),
+		})
+	}
+
+	var err error
+
+	if inventory.ResourceGroups, err = client.getResourceGroups(subscriptionId); err != nil {
+		addScanError("get Azure resource groups", NodeAzureResourceGroup, err)
+	}
+
+	if inventory.VirtualMachines, err = client.getVirtualMachines(subscriptionId); err != nil {
+		addScanError("get Azure virtual machines", NodeAzureVirtualMachine, err)
+	}
+
+	if inventory.ScaleSets, err = client.getVirtualMachineScaleSets(subscriptionId); err != nil {
+		addScanError("get Azure virtual machine scale sets", NodeAzureScaleSet, err)
+	}
+
+	if inventory.ManagedDisks, err = client.getManagedDisks(subscriptionId); err != nil {
+		addScanError("get Azure managed disks", NodeAzureManagedDisk, err)
+	}
+
+	if inventory.AKSClusters, err = client.getAKSClusters(subscriptionId); err != nil {
+		addScanError("get AKS clusters", NodeAKSCluster, err)
+	}
+
+	inventory.resolveVolumeIDs()
+	return inventory
+}
+
+// resolveVolumeIDs replaces the disk IDs referenced by virtual machines with the IDs of the disks themselves, since the
+// two can differ in case
+func (inventory *AzureInventory) resolveVolumeIDs() {
+	diskIds := map[string]string{}
+	for _, managedDisk := range inventory.ManagedDisks {
+		diskIds[getAzureResourceKey(managedDisk.ID)] = managedDisk.ID
+	}
+
+	for vmIdx := range inventory.VirtualMachines {
+		volumeIds := inventory.VirtualMachines[vmIdx].VolumeIDs
+
+		for idx, volumeId := range volumeIds {
+			if diskId, ok := diskIds[getAzureResourceKey(volumeId)]; ok {
+				volumeIds[idx] = diskId
+			}
+		}
+	}
+}
+
+func (inventory *AzureInventory) Locations() []string {
+	locations := map[string]bool{}
+
+	for _, resourceGroup := range inventory.ResourceGroups {
+		locations[resourceGroup.Region] = true
+	}
+
+	for _, virtualMachine := range inventory.VirtualMachines {
+		locations[virtualMachine.Region] = true
+	}
+
+	for _, scaleSet := range inventory.ScaleSets {
+		locations[scaleSet.Region] = true
+	}
+
+	for _, managedDisk := range inventory.ManagedDisks {
+		locations[managedDisk.Region] = true
+	}
+
+	for _, aksCluster := range inventory.AKSClusters {
+		locations[aksCluster.Region] = true
+	}
+
+	var sorted []string
+	for location := range locations {
+		if location != "" {
+			sorted = append(sorted, location)
+		}
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// getAzureScopes lists every configured subscription up front, since Azure resources are listed per subscription
+// rather than per region, and returns a scope for every location something was found in
+func getAzureScopes(globalCtx *GlobalCloudContext) []*ScanScope {
+	client := NewAzureClient()
+	var scopes []*ScanScope
+
+	for _, subscriptionId := range getAzureSubscriptionIds() {
+		log.Printf("Listing Azure subscription %s", subscriptionId)
+		inventory := client.getInventory(subscriptionId, globalCtx)
+
+		locations := inventory.Locations()
+		globalCtx.AccountRegions[subscriptionId] = locations
+		log.Printf("Scanning %d locations in Azure subscription %s", len(locations), subscriptionId)
+
+		for _, location := range locations {
+			scopes = append(scopes, &ScanScope{
+				Provider: ProviderAzure,
+				Azure:    inventory,
+				Account:  subscriptionId,
+				Region:   location,
+			})
+		}
+	}
+
+	return scopes
+}
+
+type AzureResourceGroupCollector struct{}
+
+func (collector *AzureResourceGroupCollector) Name() string {
+	return "Azure resource groups"
+}
+
+func (collector *AzureResourceGroupCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	for _, resourceGroup := range scope.Azure.ResourceGroups {
+		if resourceGroup.Region == scope.Region {
+			ctx.AddResource(resourceGroup)
+		}
+	}
+
+	return nil
+}
+
+type AzureVirtualMachineCollector struct{}
+
+func (collector *AzureVirtualMachineCollector) Name() string {
+	return "Azure virtual machines"
+}
+
+func (collector *AzureVirtualMachineCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	for _, virtualMachine := range scope.Azure.VirtualMachines {
+		if virtualMachine.Region == scope.Region {
+			ctx.AddResource(virtualMachine)
+		}
+	}
+
+	return nil
+}
+
+type AzureVirtualMachineScaleSetCollector struct{}
+
+func (collector *AzureVirtualMachineScaleSetCollector) Name() string {
+	return "Azure virtual machine scale sets"
+}
+
+func (collector *AzureVirtualMachineScaleSetCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	for _, scaleSet := range scope.Azure.ScaleSets {
+		if scaleSet.Region == scope.Region {
+			ctx.AddResource(scaleSet)
+		}
+	}
+
+	return nil
+}
+
+type AzureManagedDiskCollector struct{}
+
+func (collector *AzureManagedDiskCollector) Name() string {
+	return "Azure managed disks"
+}
+
+func (collector *AzureManagedDiskCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	for _, managedDisk := range scope.Azure.ManagedDisks {
+		if managedDisk.Region == scope.Region {
+			ctx.AddResource(managedDisk)
+		}
+	}
+
+	return nil
+}
+
+type AKSClusterCollector struct{}
+
+func (collector *AKSClusterCollector) Name() string {
+	return "AKS clusters"
+}
+
+func (collector *AKSClusterCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	for _, aksCluster := range scope.Azure.AKSClusters {
+		if aksCluster.Region == scope.Region {
+			ctx.AddResource(aksCluster)
+		}
+	}
+
+	return nil
+}
+
+var azureCollectorRegistry []Collector
+
+func RegisterAzureCollector(collector Collector) {
+	azureCollectorRegistry = append(azureCollectorRegistry, collector)
+}
+
+func RegisteredAzureCollectors() []Collector {
+	registered := make([]Collector, len(azureCollectorRegistry))
+	copy(registered, azureCollectorRegistry)
+	return registered
+}
+
+func init() {
+	RegisterAzureCollector(&AzureResourceGroupCollector{})
+	RegisterAzureCollector(&AzureVirtualMachineCollector{})
+	RegisterAzureCollector(&AzureVirtualMachineScaleSetCollector{})
+	RegisterAzureCollector(&AzureManagedDiskCollector{})
+	RegisterAzureCollector(&AKSClusterCollector{})
+}
@@ -0,0 +1,481 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ResourceTypeAzureResourceGroup  = "Azure resource groups"
+	ResourceTypeAzureVirtualMachine = "Azure virtual machines"
+	ResourceTypeAzureScaleSet       = "Azure virtual machine scale sets"
+	ResourceTypeAzureManagedDisk    = "Azure managed disks"
+	ResourceTypeAKSCluster          = "AKS clusters"
+)
+
+const (
+	NodeAzureResourceGroup  NodeType = "azure-resource-group"
+	NodeAzureVirtualMachine NodeType = "azure-virtual-machine"
+	NodeAzureScaleSet       NodeType = "azure-scale-set"
+	NodeAzureManagedDisk    NodeType = "azure-managed-disk"
+	NodeAKSCluster          NodeType = "aks-cluster"
+)
+
+const (
+	EdgeMemberOfAKS         EdgeType = "member-of-aks"
+	EdgeNodeResourceGroupOf EdgeType = "node-resource-group-of"
+)
+
+// Couchbase Cloud tags the AKS clusters and virtual machines it creates with the same cloud ID tag as on AWS
+const AzureCloudIdTag = EKSClusterCloudIdTag
+
+type AzureResourceGroup struct {
+	CloudResource
+	ProvisioningState string
+	ManagedBy         string
+}
+
+type AzureVirtualMachine struct {
+	Compute
+	ResourceGroup string
+	ManagedDisks  map[string]AzureManagedDisk
+}
+
+// AzureVirtualMachineScaleSet reports its instances as a whole, InstanceType is the size of every instance
+type AzureVirtualMachineScaleSet struct {
+	Compute
+	ResourceGroup string
+	Capacity      int64
+}
+
+type AzureManagedDisk struct {
+	Volume
+	ResourceGroup string
+	ManagedBy     string
+}
+
+type AKSCluster struct {
+	KubernetesCluster
+	ResourceGroup         string
+	NodeResourceGroupName string
+	VirtualMachines       map[string]AzureVirtualMachine
+	ScaleSets             map[string]AzureVirtualMachineScaleSet
+	NodeResourceGroup     *AzureResourceGroup
+}
+
+func NewAzureResourceGroup() *AzureResourceGroup {
+	return &AzureResourceGroup{}
+}
+
+func NewAzureVirtualMachine() *AzureVirtualMachine {
+	return &AzureVirtualMachine{
+		ManagedDisks: make(map[string]AzureManagedDisk),
+	}
+}
+
+func NewAzureVirtualMachineScaleSet() *AzureVirtualMachineScaleSet {
+	return &AzureVirtualMachineScaleSet{}
+}
+
+func NewAzureManagedDisk() *AzureManagedDisk {
+	return &AzureManagedDisk{}
+}
+
+func NewAKSCluster() *AKSCluster {
+	return &AKSCluster{
+		VirtualMachines: make(map[string]AzureVirtualMachine),
+		ScaleSets:       make(map[string]AzureVirtualMachineScaleSet),
+	}
+}
+
+func (resourceGroup AzureResourceGroup) Resource() CloudResource {
+	return resourceGroup.CloudResource
+}
+
+func (resourceGroup AzureResourceGroup) WithCloudResource(resource CloudResource) ReportableResource {
+	resourceGroup.CloudResource = resource
+	return resourceGroup
+}
+
+func (resourceGroup AzureResourceGroup) Kind() string {
+	return ResourceTypeAzureResourceGroup
+}
+
+func (resourceGroup AzureResourceGroup) ReportFields() []ReportField {
+	fields := []ReportField{{Label: "State", Value: resourceGroup.ProvisioningState}}
+
+	if resourceGroup.ManagedBy != "" {
+		fields = append(fields, ReportField{Label: "Managed by", Value: resourceGroup.ManagedBy})
+	}
+
+	return fields
+}
+
+func (virtualMachine AzureVirtualMachine) Resource() CloudResource {
+	return virtualMachine.CloudResource
+}
+
+func (virtualMachine AzureVirtualMachine) WithCloudResource(resource CloudResource) ReportableResource {
+	virtualMachine.CloudResource = resource
+	return virtualMachine
+}
+
+func (virtualMachine AzureVirtualMachine) Kind() string {
+	return ResourceTypeAzureVirtualMachine
+}
+
+func (virtualMachine AzureVirtualMachine) ReportFields() []ReportField {
+	return []ReportField{
+		{Label: "Size", Value: virtualMachine.InstanceType},
+		{Label: "Power state", Value: virtualMachine.State},
+		{Label: "Resource group", Value: virtualMachine.ResourceGroup},
+		{Label: "Managed disks", Value: strconv.Itoa(len(virtualMachine.ManagedDisks))},
+	}
+}
+
+func (virtualMachine AzureVirtualMachine) ClaimedResources() []ReportableResource {
+	var resources []ReportableResource
+
+	for _, managedDisk := range virtualMachine.ManagedDisks {
+		resources = append(resources, managedDisk)
+	}
+
+	return resources
+}
+
+func (scaleSet AzureVirtualMachineScaleSet) Resource() CloudResource {
+	return scaleSet.CloudResource
+}
+
+func (scaleSet AzureVirtualMachineScaleSet) WithCloudResource(resource CloudResource) ReportableResource {
+	scaleSet.CloudResource = resource
+	return scaleSet
+}
+
+func (scaleSet AzureVirtualMachineScaleSet) Kind() string {
+	return ResourceTypeAzureScaleSet
+}
+
+func (scaleSet AzureVirtualMachineScaleSet) ReportFields() []ReportField {
+	return []ReportField{
+		{Label: "Size", Value: scaleSet.InstanceType},
+		{Label: "Instances", Value: strconv.FormatInt(scaleSet.Capacity, 10)},
+		{Label: "State", Value: scaleSet.State},
+		{Label: "Resource group", Value: scaleSet.ResourceGroup},
+	}
+}
+
+func (managedDisk AzureManagedDisk) Resource() CloudResource {
+	return managedDisk.CloudResource
+}
+
+func (managedDisk AzureManagedDisk) WithCloudResource(resource CloudResource) ReportableResource {
+	managedDisk.CloudResource = resource
+	return managedDisk
+}
+
+func (managedDisk AzureManagedDisk) Kind() string {
+	return ResourceTypeAzureManagedDisk
+}
+
+func (managedDisk AzureManagedDisk) ReportFields() []ReportField {
+	return []ReportField{
+		{Label: "Size", Value: fmt.Sprintf("%d GiB", managedDisk.SizeGiB)},
+		{Label: "SKU", Value: managedDisk.VolumeType},
+		{Label: "State", Value: managedDisk.State},
+		{Label: "Resource group", Value: managedDisk.ResourceGroup},
+	}
+}
+
+func (aksCluster AKSCluster) Resource() CloudResource {
+	return aksCluster.CloudResource
+}
+
+func (aksCluster AKSCluster) WithCloudResource(resource CloudResource) ReportableResource {
+	aksCluster.CloudResource = resource
+	return aksCluster
+}
+
+func (aksCluster AKSCluster) Kind() string {
+	return ResourceTypeAKSCluster
+}
+
+func (aksCluster AKSCluster) ReportFields() []ReportField {
+	return []ReportField{
+		{Label: "Kubernetes version", Value: aksCluster.Version},
+		{Label: "State", Value: aksCluster.State},
+		{Label: "Nodes", Value: strconv.Itoa(aksCluster.NodeCount)},
+		{Label: "Node resource group", Value: aksCluster.NodeResourceGroupName},
+		{Label: "Virtual machines", Value: strconv.Itoa(len(aksCluster.VirtualMachines))},
+		{Label: "Scale sets", Value: strconv.Itoa(len(aksCluster.ScaleSets))},
+	}
+}
+
+func (aksCluster AKSCluster) ClaimedResources() []ReportableResource {
+	var resources []ReportableResource
+
+	for _, virtualMachine := range aksCluster.VirtualMachines {
+		resources = append(resources, virtualMachine)
+	}
+
+	for _, scaleSet := range aksCluster.ScaleSets {
+		resources = append(resources, scaleSet)
+	}
+
+	if aksCluster.NodeResourceGroup != nil {
+		resources = append(resources, *aksCluster.NodeResourceGroup)
+	}
+
+	return resources
+}
+
+func (virtualMachine *AzureVirtualMachine) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
+	switch resource.(type) {
+	case AzureManagedDisk:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		managedDisk := resource.(AzureManagedDisk)
+		virtualMachine.ManagedDisks[managedDisk.ID] = managedDisk
+		return claimed()
+	}
+
+	return cannotClaim(resource)
+}
+
+func (aksCluster *AKSCluster) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
+	switch resource.(type) {
+	case AzureVirtualMachine:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		virtualMachine := resource.(AzureVirtualMachine)
+		aksCluster.VirtualMachines[virtualMachine.ID] = virtualMachine
+		return claimed()
+	case AzureVirtualMachineScaleSet:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		scaleSet := resource.(AzureVirtualMachineScaleSet)
+		aksCluster.ScaleSets[scaleSet.ID] = scaleSet
+		return claimed()
+	case AzureResourceGroup:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		resourceGroup := resource.(AzureResourceGroup)
+		aksCluster.NodeResourceGroup = &resourceGroup
+		return claimed()
+	}
+
+	return cannotClaim(resource)
+}
+
+func init() {
+	RegisterResourceNodeType(ResourceTypeAzureResourceGroup, NodeAzureResourceGroup)
+	RegisterResourceNodeType(ResourceTypeAzureVirtualMachine, NodeAzureVirtualMachine)
+	RegisterResourceNodeType(ResourceTypeAzureScaleSet, NodeAzureScaleSet)
+	RegisterResourceNodeType(ResourceTypeAzureManagedDisk, NodeAzureManagedDisk)
+	RegisterResourceNodeType(ResourceTypeAKSCluster, NodeAKSCluster)
+
+	RegisterResourcePricer(ResourceTypeAzureVirtualMachine, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return catalog.GetAzureVirtualMachineCost(resource.(AzureVirtualMachine))
+	})
+	RegisterResourcePricer(ResourceTypeAzureScaleSet, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return catalog.GetAzureVirtualMachineScaleSetCost(resource.(AzureVirtualMachineScaleSet))
+	})
+	// The AKS control plane is free on the default tier, clusters are priced so their total covers what they claim
+	RegisterResourcePricer(ResourceTypeAKSCluster, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return NewHourlyCost(0)
+	})
+}
+
+func (ctx *RegionalCloudContext) GetAzureVirtualMachines() []AzureVirtualMachine {
+	var virtualMachines []AzureVirtualMachine
+
+	for _, resource := range ctx.Resources[ResourceTypeAzureVirtualMachine] {
+		virtualMachines = append(virtualMachines, resource.(AzureVirtualMachine))
+	}
+
+	return virtualMachines
+}
+
+func (ctx *RegionalCloudContext) GetAzureVirtualMachineScaleSets() []AzureVirtualMachineScaleSet {
+	var scaleSets []AzureVirtualMachineScaleSet
+
+	for _, resource := range ctx.Resources[ResourceTypeAzureScaleSet] {
+		scaleSets = append(scaleSets, resource.(AzureVirtualMachineScaleSet))
+	}
+
+	return scaleSets
+}
+
+func (ctx *RegionalCloudContext) GetAKSClusters() []AKSCluster {
+	var aksClusters []AKSCluster
+
+	for _, resource := range ctx.Resources[ResourceTypeAKSCluster] {
+		aksClusters = append(aksClusters, resource.(AKSCluster))
+	}
+
+	return aksClusters
+}
+
+func (ctx *RegionalCloudContext) getAzureVirtualMachinesByResourceGroup() map[string][]AzureVirtualMachine {
+	virtualMachines := map[string][]AzureVirtualMachine{}
+
+	for _, virtualMachine := range ctx.GetAzureVirtualMachines() {
+		resourceGroup := strings.ToLower(virtualMachine.ResourceGroup)
+		virtualMachines[resourceGroup] = append(virtualMachines[resourceGroup], virtualMachine)
+	}
+
+	return virtualMachines
+}
+
+func (ctx *RegionalCloudContext) getAzureScaleSetsByResourceGroup() map[string][]AzureVirtualMachineScaleSet {
+	scaleSets := map[string][]AzureVirtualMachineScaleSet{}
+
+	for _, scaleSet := range ctx.GetAzureVirtualMachineScaleSets() {
+		resourceGroup := strings.ToLower(scaleSet.ResourceGroup)
+		scaleSets[resourceGroup] = append(scaleSets[resourceGroup], scaleSet)
+	}
+
+	return scaleSets
+}
+
+func (ctx *RegionalCloudContext) getAzureResourceGroupsByName() map[string]AzureResourceGroup {
+	resourceGroups := map[string]AzureResourceGroup{}
+
+	for _, resource := range ctx.Resources[ResourceTypeAzureResourceGroup] {
+		resourceGroup := resource.(AzureResourceGroup)
+		resourceGroups[strings.ToLower(resourceGroup.Name)] = resourceGroup
+	}
+
+	return resourceGroups
+}
+
+func getAKSClusterClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+	virtualMachinesByResourceGroup := ctx.getAzureVirtualMachinesByResourceGroup()
+	scaleSetsByResourceGroup := ctx.getAzureScaleSetsByResourceGroup()
+	resourceGroupsByName := ctx.getAzureResourceGroupsByName()
+
+	for _, aksCluster := range ctx.GetAKSClusters() {
+		claimer := aksCluster
+		nodeResourceGroup := strings.ToLower(aksCluster.NodeResourceGroupName)
+
+		if nodeResourceGroup == "" {
+			continue
+		}
+
+		// Like EKS subnets, every virtual machine in the node resource group is assumed to be a node of the cluster
+		for _, virtualMachine := range virtualMachinesByResourceGroup[nodeResourceGroup] {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  &claimer,
+				Resource: virtualMachine,
+				Reason:   fmt.Sprintf("in AKS node resource group %s", aksCluster.NodeResourceGroupName),
+			})
+		}
+
+		// Node pools are usually scale sets, whose instances are not listed as virtual machines
+		for _, scaleSet := range scaleSetsByResourceGroup[nodeResourceGroup] {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  &claimer,
+				Resource: scaleSet,
+				Reason:   fmt.Sprintf("in AKS node resource group %s", aksCluster.NodeResourceGroupName),
+			})
+		}
+
+		if resourceGroup, ok := resourceGroupsByName[nodeResourceGroup]; ok {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  &claimer,
+				Resource: resourceGroup,
+				Reason:   "AKS node resource group",
+			})
+		}
+	}
+
+	return candidates
+}
+
+func getCouchbaseCloudAzureClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+
+	for _, aksCluster := range ctx.GetAKSClusters() {
+		if couchbaseCloud, ok := ctx.CouchbaseClouds[aksCluster.Tags[AzureCloudIdTag]]; ok {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  couchbaseCloud,
+				Resource: aksCluster,
+				Reason:   fmt.Sprintf("tagged with %s", AzureCloudIdTag),
+			})
+		}
+	}
+
+	for _, virtualMachine := range ctx.GetAzureVirtualMachines() {
+		if couchbaseCloud, ok := ctx.CouchbaseClouds[virtualMachine.Tags[AzureCloudIdTag]]; ok {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  couchbaseCloud,
+				Resource: virtualMachine,
+				Reason:   fmt.Sprintf("tagged with %s", AzureCloudIdTag),
+			})
+		}
+	}
+
+	return candidates
+}
+
+// addAzureResources adds every candidate ownership of the Azure resources in the region to the graph
+func addAzureResources(graph *OwnershipGraph, ctx *RegionalCloudContext) {
+	virtualMachinesByResourceGroup := ctx.getAzureVirtualMachinesByResourceGroup()
+	scaleSetsByResourceGroup := ctx.getAzureScaleSetsByResourceGroup()
+	resourceGroupsByName := ctx.getAzureResourceGroupsByName()
+
+	for _, resourceGroup := range resourceGroupsByName {
+		graph.AddNode(NodeAzureResourceGroup, resourceGroup.CloudResource)
+	}
+
+	for _, resource := range ctx.Resources[ResourceTypeAzureManagedDisk] {
+		graph.AddNode(NodeAzureManagedDisk, resource.Resource())
+	}
+
+	for _, scaleSet := range ctx.GetAzureVirtualMachineScaleSets() {
+		graph.AddNode(NodeAzureScaleSet, scaleSet.CloudResource)
+	}
+
+	for _, virtualMachine := range ctx.GetAzureVirtualMachines() {
+		virtualMachineNodeId := graph.AddNode(NodeAzureVirtualMachine, virtualMachine.CloudResource)
+
+		for _, volumeId := range virtualMachine.VolumeIDs {
+			if disk, ok := ctx.Resources[ResourceTypeAzureManagedDisk][volumeId]; ok {
+				graph.AddEdge(GetNodeId(NodeAzureManagedDisk, disk.Resource()), virtualMachineNodeId, EdgeAttachedTo)
+			}
+		}
+
+		if couchbaseCloud, ok := ctx.CouchbaseClouds[virtualMachine.Tags[AzureCloudIdTag]]; ok {
+			graph.AddEdge(virtualMachineNodeId, graph.AddNode(NodeCouchbaseCloud, couchbaseCloud.CloudResource), EdgeBelongsToCloud)
+		}
+	}
+
+	for _, aksCluster := range ctx.GetAKSClusters() {
+		aksNodeId := graph.AddNode(NodeAKSCluster, aksCluster.CloudResource)
+		nodeResourceGroup := strings.ToLower(aksCluster.NodeResourceGroupName)
+
+		if nodeResourceGroup != "" {
+			for _, virtualMachine := range virtualMachinesByResourceGroup[nodeResourceGroup] {
+				graph.AddEdge(GetNodeId(NodeAzureVirtualMachine, virtualMachine.CloudResource), aksNodeId, EdgeMemberOfAKS)
+			}
+
+			for _, scaleSet := range scaleSetsByResourceGroup[nodeResourceGroup] {
+				graph.AddEdge(GetNodeId(NodeAzureScaleSet, scaleSet.CloudResource), aksNodeId, EdgeMemberOfAKS)
+			}
+
+			if resourceGroup, ok := resourceGroupsByName[nodeResourceGroup]; ok {
+				graph.AddEdge(GetNodeId(NodeAzureResourceGroup, resourceGroup.CloudResource), aksNodeId, EdgeNodeResourceGroupOf)
+			}
+		}
+
+		if couchbaseCloud, ok := ctx.CouchbaseClouds[aksCluster.Tags[AzureCloudIdTag]]; ok {
+			graph.AddEdge(aksNodeId, graph.AddNode(NodeCouchbaseCloud, couchbaseCloud.CloudResource), EdgeBelongsToCloud)
+		}
+	}
+}
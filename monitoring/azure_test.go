@@ -0,0 +1,308 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testAzureTenantId       = "test-tenant"
+	testAzureSubscriptionId = "00000000-0000-0000-0000-000000000001"
+	testAzureToken          = "test-token"
+)
+
+// fakeAzureServer answers the token endpoint and the Resource Manager list endpoints, serving each list from its pages
+type fakeAzureServer struct {
+	*httptest.Server
+	pages         map[string][][]map[string]interface{}
+	failing       map[string]bool
+	mutex         sync.Mutex
+	tokenRequests int
+	apiVersions   map[string]string
+}
+
+func newFakeAzureServer(t *testing.T) *fakeAzureServer {
+	server := &fakeAzureServer{
+		pages:       map[string][][]map[string]interface{}{},
+		failing:     map[string]bool{},
+		apiVersions: map[string]string{},
+	}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+
+		if request.URL.Path == fmt.Sprintf("/%s/oauth2/v2.0/token", testAzureTenantId) {
+			if err := request.ParseForm(); err != nil || request.PostForm.Get("grant_type") != "client_credentials" ||
+				request.PostForm.Get("client_id") != "test-client" || request.PostForm.Get("client_secret") != "test-secret" {
+				http.Error(writer, "invalid_client", http.StatusUnauthorized)
+				return
+			}
+
+			server.tokenRequests++
+			json.NewEncoder(writer).Encode(map[string]interface{}{"access_token": testAzureToken, "expires_in": 3600})
+			return
+		}
+
+		if request.Header.Get("Authorization") != "Bearer "+testAzureToken {
+			http.Error(writer, "InvalidAuthenticationToken", http.StatusUnauthorized)
+			return
+		}
+
+		path := strings.ToLower(request.URL.Path)
+		server.apiVersions[path] = request.URL.Query().Get("api-version")
+
+		if server.failing[path] {
+			http.Error(writer, "InternalServerError", http.StatusInternalServerError)
+			return
+		}
+
+		pages, ok := server.pages[path]
+		if !ok {
+			http.NotFound(writer, request)
+			return
+		}
+
+		page := 0
+		fmt.Sscanf(request.URL.Query().Get("$skiptoken"), "%d", &page)
+
+		response := map[string]interface{}{"value": []map[string]interface{}{}}
+		if page < len(pages) {
+			response["value"] = pages[page]
+		}
+		if page+1 < len(pages) {
+			response["nextLink"] = fmt.Sprintf("%s%s?api-version=%s&$skiptoken=%d", server.URL, request.URL.Path, request.URL.Query().Get("api-version"), page+1)
+		}
+
+		json.NewEncoder(writer).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (server *fakeAzureServer) serve(provider string, pages ...[]map[string]interface{}) {
+	path := strings.ToLower(fmt.Sprintf("/subscriptions/%s%s", testAzureSubscriptionId, provider))
+	server.pages[path] = pages
+}
+
+func (server *fakeAzureServer) fail(provider string) {
+	server.failing[strings.ToLower(fmt.Sprintf("/subscriptions/%s%s", testAzureSubscriptionId, provider))] = true
+}
+
+func (server *fakeAzureServer) client() *AzureClient {
+	return &AzureClient{
+		ManagementEndpoint: server.URL,
+		AuthorityHost:      server.URL,
+		TenantID:           testAzureTenantId,
+		ClientID:           "test-client",
+		ClientSecret:       "test-secret",
+		HTTPClient:         server.Client(),
+	}
+}
+
+func testAzureResourceId(resourceGroup string, provider string, name string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s", testAzureSubscriptionId, resourceGroup, provider, name)
+}
+
+func serveTestAzureSubscription(server *fakeAzureServer) {
+	diskId := testAzureResourceId("couchbase-rg", "Microsoft.Compute/disks", "vm-1-osdisk")
+
+	server.serve("/resourcegroups", []map[string]interface{}{
+		{"id": fmt.Sprintf("/subscriptions/%s/resourceGroups/couchbase-rg", testAzureSubscriptionId), "name": "couchbase-rg", "location": "East US",
+			"properties": map[string]interface{}{"provisioningState": "Succeeded"}},
+		{"id": fmt.Sprintf("/subscriptions/%s/resourceGroups/MC_couchbase-rg_aks-1_westeurope", testAzureSubscriptionId), "name": "MC_couchbase-rg_aks-1_westeurope",
+			"location": "westeurope", "managedBy": testAzureResourceId("couchbase-rg", "Microsoft.ContainerService/managedClusters", "aks-1"),
+			"properties": map[string]interface{}{"provisioningState": "Succeeded"}},
+	})
+
+	// Virtual machines are served over two pages to follow nextLink
+	server.serve("/providers/Microsoft.Compute/virtualMachines", []map[string]interface{}{
+		{"id": testAzureResourceId("couchbase-rg", "Microsoft.Compute/virtualMachines", "vm-1"), "name": "vm-1", "location": "eastus",
+			"tags":       map[string]string{"owner": "someone"},
+			"systemData": map[string]interface{}{"createdBy": "someone@example.com", "createdAt": "2026-10-01T10:00:00Z"},
+			"properties": map[string]interface{}{
+				"hardwareProfile": map[string]string{"vmSize": "Standard_D4s_v3"},
+				// Virtual machines reference their disks with different casing to the disk listing
+				"storageProfile": map[string]interface{}{"osDisk": map[string]interface{}{"managedDisk": map[string]string{"id": strings.ToUpper(diskId)}}},
+				"instanceView":   map[string]interface{}{"statuses": []map[string]string{{"code": "ProvisioningState/succeeded"}, {"code": "PowerState/running"}}},
+			}},
+	}, []map[string]interface{}{
+		{"id": testAzureResourceId("couchbase-rg", "Microsoft.Compute/virtualMachines", "vm-2"), "name": "vm-2", "location": "eastus",
+			"properties": map[string]interface{}{
+				"timeCreated":     "2026-09-01T10:00:00Z",
+				"hardwareProfile": map[string]string{"vmSize": "Standard_B2s"},
+				"instanceView":    map[string]interface{}{"statuses": []map[string]string{{"code": "PowerState/deallocated"}}},
+			}},
+	})
+
+	server.serve("/providers/Microsoft.Compute/virtualMachineScaleSets", []map[string]interface{}{
+		{"id": testAzureResourceId("MC_couchbase-rg_aks-1_westeurope", "Microsoft.Compute/virtualMachineScaleSets", "aks-nodepool1-vmss"),
+			"name": "aks-nodepool1-vmss", "location": "westeurope", "sku": map[string]interface{}{"name": "Standard_D2s_v3", "capacity": 3},
+			"properties": map[string]interface{}{"provisioningState": "Succeeded", "timeCreated": "2026-09-15T10:00:00Z"}},
+	})
+
+	server.serve("/providers/Microsoft.Compute/disks", []map[string]interface{}{
+		{"id": diskId, "name": "vm-1-osdisk", "location": "eastus", "managedBy": testAzureResourceId("couchbase-rg", "Microsoft.Compute/virtualMachines", "vm-1"),
+			"sku":        map[string]string{"name": "Premium_LRS"},
+			"properties": map[string]interface{}{"diskSizeGB": 128, "diskState": "Attached"}},
+	})
+
+	server.serve("/providers/Microsoft.ContainerService/managedClusters", []map[string]interface{}{
+		{"id": testAzureResourceId("couchbase-rg", "Microsoft.ContainerService/managedClusters", "aks-1"), "name": "aks-1", "location": "westeurope",
+			"tags": map[string]string{AzureCloudIdTag: "cloud-1"},
+			"properties": map[string]interface{}{
+				"kubernetesVersion": "1.21.2",
+				"nodeResourceGroup": "MC_couchbase-rg_aks-1_westeurope",
+				"provisioningState": "Succeeded",
+				"agentPoolProfiles": []map[string]int{{"count": 3}, {"count": 2}},
+			}},
+	})
+}
+
+func TestAzureClientGetInventory(t *testing.T) {
+	server := newFakeAzureServer(t)
+	serveTestAzureSubscription(server)
+
+	globalCtx := NewGlobalCloudContext()
+	inventory := server.client().getInventory(testAzureSubscriptionId, globalCtx)
+
+	if len(globalCtx.ScanErrors) > 0 {
+		t.Fatalf("unexpected scan errors: %+v", globalCtx.ScanErrors)
+	}
+
+	if server.tokenRequests != 1 {
+		t.Errorf("expected the token to be requested once and reused, got %d requests", server.tokenRequests)
+	}
+
+	for path, apiVersion := range server.apiVersions {
+		if apiVersion == "" {
+			t.Errorf("%s was requested without an api-version", path)
+		}
+	}
+
+	if got, want := inventory.Locations(), []string{"eastus", "westeurope"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Locations() = %v, want %v", got, want)
+	}
+
+	if len(inventory.ResourceGroups) != 2 {
+		t.Fatalf("expected 2 resource groups, got %d", len(inventory.ResourceGroups))
+	}
+	if inventory.ResourceGroups[0].Region != "eastus" || inventory.ResourceGroups[0].ProvisioningState != "Succeeded" {
+		t.Errorf("unexpected resource group %+v", inventory.ResourceGroups[0])
+	}
+	if inventory.ResourceGroups[1].ManagedBy == "" {
+		t.Errorf("expected the node resource group to be managed by its AKS cluster")
+	}
+
+	if len(inventory.VirtualMachines) != 2 {
+		t.Fatalf("expected the virtual machines from both pages, got %d", len(inventory.VirtualMachines))
+	}
+
+	vm := inventory.VirtualMachines[0]
+	if vm.Name != "vm-1" || vm.Provider != ProviderAzure || vm.Account != testAzureSubscriptionId || vm.ResourceGroup != "couchbase-rg" {
+		t.Errorf("unexpected virtual machine %+v", vm)
+	}
+	if vm.InstanceType != "Standard_D4s_v3" || vm.State != "running" {
+		t.Errorf("expected a running Standard_D4s_v3, got %s %s", vm.State, vm.InstanceType)
+	}
+	if vm.LaunchedBy != "someone@example.com" || vm.LaunchedBySource != LaunchedBySourceAzureSystemData || vm.CreatedAt.IsZero() {
+		t.Errorf("expected the creator and creation time from systemData, got %q %q %s", vm.LaunchedBy, vm.LaunchedBySource, vm.CreatedAt)
+	}
+	if len(vm.VolumeIDs) != 1 || vm.VolumeIDs[0] != inventory.ManagedDisks[0].ID {
+		t.Errorf("expected the disk reference to resolve to the listed disk ID, got %v", vm.VolumeIDs)
+	}
+
+	if vm := inventory.VirtualMachines[1]; vm.State != "deallocated" || vm.CreatedAt.IsZero() {
+		t.Errorf("expected a deallocated virtual machine created at timeCreated, got %s %s", vm.State, vm.CreatedAt)
+	}
+
+	if len(inventory.ScaleSets) != 1 {
+		t.Fatalf("expected 1 scale set, got %d", len(inventory.ScaleSets))
+	}
+	if scaleSet := inventory.ScaleSets[0]; scaleSet.InstanceType != "Standard_D2s_v3" || scaleSet.Capacity != 3 ||
+		scaleSet.ResourceGroup != "MC_couchbase-rg_aks-1_westeurope" || scaleSet.Region != "westeurope" {
+		t.Errorf("unexpected scale set %+v", scaleSet)
+	}
+
+	if len(inventory.ManagedDisks) != 1 {
+		t.Fatalf("expected 1 managed disk, got %d", len(inventory.ManagedDisks))
+	}
+	if disk := inventory.ManagedDisks[0]; disk.VolumeType != "Premium_LRS" || disk.SizeGiB != 128 || disk.State != "Attached" {
+		t.Errorf("unexpected managed disk %+v", disk)
+	}
+
+	if len(inventory.AKSClusters) != 1 {
+		t.Fatalf("expected 1 AKS cluster, got %d", len(inventory.AKSClusters))
+	}
+	if aksCluster := inventory.AKSClusters[0]; aksCluster.NodeCount != 5 || aksCluster.Version != "1.21.2" ||
+		aksCluster.NodeResourceGroupName != "MC_couchbase-rg_aks-1_westeurope" || aksCluster.Tags[AzureCloudIdTag] != "cloud-1" {
+		t.Errorf("unexpected AKS cluster %+v", aksCluster)
+	}
+}
+
+func TestAzureClientGetInventoryPartialFailure(t *testing.T) {
+	server := newFakeAzureServer(t)
+	serveTestAzureSubscription(server)
+	server.fail("/providers/Microsoft.Compute/virtualMachineScaleSets")
+
+	globalCtx := NewGlobalCloudContext()
+	inventory := server.client().getInventory(testAzureSubscriptionId, globalCtx)
+
+	if len(globalCtx.ScanErrors) != 1 {
+		t.Fatalf("expected 1 scan error, got %+v", globalCtx.ScanErrors)
+	}
+	if scanError := globalCtx.ScanErrors[0]; scanError.Account != testAzureSubscriptionId || scanError.Operation != "get Azure virtual machine scale sets" {
+		t.Errorf("unexpected scan error %+v", scanError)
+	}
+
+	if len(inventory.ScaleSets) != 0 {
+		t.Errorf("expected no scale sets, got %d", len(inventory.ScaleSets))
+	}
+	if len(inventory.VirtualMachines) != 2 || len(inventory.AKSClusters) != 1 {
+		t.Errorf("expected the rest of the subscription to be listed, got %d virtual machines and %d AKS clusters",
+			len(inventory.VirtualMachines), len(inventory.AKSClusters))
+	}
+}
+
+func TestAzureClientRejectedCredentials(t *testing.T) {
+	server := newFakeAzureServer(t)
+	serveTestAzureSubscription(server)
+
+	client := server.client()
+	client.ClientSecret = "wrong-secret"
+
+	globalCtx := NewGlobalCloudContext()
+	inventory := client.getInventory(testAzureSubscriptionId, globalCtx)
+
+	if len(globalCtx.ScanErrors) != 5 {
+		t.Errorf("expected every listing to fail, got %d scan errors", len(globalCtx.ScanErrors))
+	}
+	if len(inventory.Locations()) != 0 {
+		t.Errorf("expected no locations, got %v", inventory.Locations())
+	}
+}
+
+func TestNewAzureClientEndpoints(t *testing.T) {
+	t.Setenv(azureManagementEndpointEnv, "")
+	t.Setenv(azureAuthorityHostEnv, "")
+
+	client := NewAzureClient()
+	if client.ManagementEndpoint != defaultAzureManagementEndpoint || client.AuthorityHost != defaultAzureAuthorityHost {
+		t.Errorf("expected the public cloud endpoints by default, got %s and %s", client.ManagementEndpoint, client.AuthorityHost)
+	}
+
+	t.Setenv(azureManagementEndpointEnv, "http://localhost:8081/")
+	t.Setenv(azureAuthorityHostEnv, "http://localhost:8082/")
+
+	client = NewAzureClient()
+	if client.ManagementEndpoint != "http://localhost:8081" || client.AuthorityHost != "http://localhost:8082" {
+		t.Errorf("expected the replaced endpoints without a trailing slash, got %s and %s", client.ManagementEndpoint, client.AuthorityHost)
+	}
+}
@@ -0,0 +1,376 @@
+package monitoring
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+const (
+	ResourceTypeEBSVolume             = "EBS volumes"
+	ResourceTypeEC2Instance           = "EC2 instances"
+	ResourceTypeCouchbaseCloudCluster = "Couchbase Cloud clusters"
+	ResourceTypeEKSCluster            = "EKS clusters"
+	ResourceTypeCloudformationStack   = "Cloudformation stacks"
+	ResourceTypeCouchbaseCloud        = "Couchbase Clouds"
+)
+
+type ClaimResult struct {
+	Claimed bool
+	Reason  string
+}
+
+// ClaimCandidate proposes that Claimer owns Resource, Reason explains which relationship was matched
+type ClaimCandidate struct {
+	Claimer  CloudResourceClaimer
+	Resource interface{}
+	Reason   string
+}
+
+// ClaimRule finds the resources of one type that a parent resource type may claim. Rules run in registration order
+// and each one only sees what earlier rules left unclaimed.
+type ClaimRule struct {
+	Name       string
+	Candidates func(ctx *RegionalCloudContext) []ClaimCandidate
+}
+
+type ClaimRecord struct {
+	Rule     string
+	Claimer  string
+	Resource string
+	Claimed  bool
+	Reason   string
+}
+
+type ClaimReport struct {
+	Account   string
+	Region    string
+	Records   []ClaimRecord
+	Unclaimed map[string]int
+}
+
+var claimRules []ClaimRule
+
+// stackResourceTypes maps the resource types found in stack resource lists to the kind of resource they created
+var stackResourceTypes = map[string]string{}
+
+func RegisterClaimRule(rule ClaimRule) {
+	claimRules = append(claimRules, rule)
+}
+
+func RegisteredClaimRules() []ClaimRule {
+	registered := make([]ClaimRule, len(claimRules))
+	copy(registered, claimRules)
+	return registered
+}
+
+// RegisterStackResourceType lets stacks claim the resources of kind that they list with resourceType
+func RegisterStackResourceType(resourceType string, kind string) {
+	stackResourceTypes[resourceType] = kind
+}
+
+func RegisteredStackResourceTypes() map[string]string {
+	registered := make(map[string]string, len(stackResourceTypes))
+	for resourceType, kind := range stackResourceTypes {
+		registered[resourceType] = kind
+	}
+	return registered
+}
+
+// Instances claim their volumes before anything claims the instances, so the volumes move along with them
+func init() {
+	RegisterClaimRule(ClaimRule{Name: "Instances claim attached volumes", Candidates: getComputeVolumeClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "Couchbase Cloud clusters claim EC2 instances", Candidates: getCouchbaseCloudClusterClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "EKS clusters claim Couchbase Cloud clusters", Candidates: getEKSClusterCouchbaseClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "EKS clusters claim EC2 instances", Candidates: getEKSClusterEC2ClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "EKS clusters claim load balancers", Candidates: getEKSClusterLoadBalancerClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "AKS clusters claim Azure virtual machines, scale sets and node resource groups", Candidates: getAKSClusterClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "GKE clusters claim Compute Engine instances", Candidates: getGKEClusterClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "RDS clusters claim member instances", Candidates: getRDSClusterClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "VPCs claim NAT gateways", Candidates: getVPCClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "Stacks claim listed resources", Candidates: getStackClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "Couchbase Clouds claim EKS clusters and Cloudformation stacks", Candidates: getCouchbaseCloudClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "Couchbase Clouds claim AKS clusters and Azure virtual machines", Candidates: getCouchbaseCloudAzureClaimCandidates})
+	RegisterClaimRule(ClaimRule{Name: "Couchbase Clouds claim GKE clusters and Compute Engine instances", Candidates: getCouchbaseCloudGCPClaimCandidates})
+
+	RegisterStackResourceType(cloudformationEc2StackResourceId, ResourceTypeEC2Instance)
+}
+
+func claimed() ClaimResult {
+	return ClaimResult{Claimed: true}
+}
+
+func alreadyClaimed() ClaimResult {
+	return ClaimResult{Reason: "already claimed by another resource"}
+}
+
+func cannotClaim(resource interface{}) ClaimResult {
+	return ClaimResult{Reason: fmt.Sprintf("cannot claim %s", GetResourceType(resource))}
+}
+
+func GetResourceType(resource interface{}) string {
+	switch resource.(type) {
+	case EBSVolume, *EBSVolume:
+		return ResourceTypeEBSVolume
+	case EC2Instance, *EC2Instance:
+		return ResourceTypeEC2Instance
+	case CouchbaseCloudCluster, *CouchbaseCloudCluster:
+		return ResourceTypeCouchbaseCloudCluster
+	case EKSCluster, *EKSCluster:
+		return ResourceTypeEKSCluster
+	case CloudformationStack, *CloudformationStack:
+		return ResourceTypeCloudformationStack
+	case CouchbaseCloud, *CouchbaseCloud:
+		return ResourceTypeCouchbaseCloud
+	case ReportableResource:
+		return resource.(ReportableResource).Kind()
+	}
+
+	return fmt.Sprintf("%T", resource)
+}
+
+func getResourceDescription(resource interface{}) string {
+	var id string
+
+	switch resource.(type) {
+	case EBSVolume:
+		id = resource.(EBSVolume).ID
+	case *EC2Instance:
+		id = resource.(*EC2Instance).ID
+	case EC2Instance:
+		id = resource.(EC2Instance).ID
+	case *CouchbaseCloudCluster:
+		id = resource.(*CouchbaseCloudCluster).ID
+	case CouchbaseCloudCluster:
+		id = resource.(CouchbaseCloudCluster).ID
+	case *EKSCluster:
+		id = resource.(*EKSCluster).Name
+	case EKSCluster:
+		id = resource.(EKSCluster).Name
+	case *CloudformationStack:
+		id = resource.(*CloudformationStack).ID
+	case CloudformationStack:
+		id = resource.(CloudformationStack).ID
+	case *CouchbaseCloud:
+		id = resource.(*CouchbaseCloud).ID
+	case ReportableResource:
+		id = resource.(ReportableResource).Resource().ID
+	}
+
+	return fmt.Sprintf("%s %s", GetResourceType(resource), id)
+}
+
+func processClaims(ctx *RegionalCloudContext) *ClaimReport {
+	report := &ClaimReport{
+		Account: ctx.Account,
+		Region:  ctx.Region,
+	}
+
+	for _, rule := range RegisteredClaimRules() {
+		claimedCount := 0
+		rejectedCount := 0
+
+		for _, candidate := range rule.Candidates(ctx) {
+			result := candidate.Claimer.Claim(ctx, candidate.Resource)
+			record := ClaimRecord{
+				Rule:     rule.Name,
+				Claimer:  getResourceDescription(candidate.Claimer),
+				Resource: getResourceDescription(candidate.Resource),
+				Claimed:  result.Claimed,
+				Reason:   candidate.Reason,
+			}
+
+			if result.Claimed {
+				claimedCount++
+			} else {
+				record.Reason = result.Reason
+				rejectedCount++
+			}
+
+			report.Records = append(report.Records, record)
+		}
+
+		log.Printf("Processed claim rule %q in account %s region %s (%d claimed, %d rejected)", rule.Name, ctx.Account, ctx.Region, claimedCount, rejectedCount)
+	}
+
+	dropIdleClaimers(ctx)
+
+	report.Unclaimed = ctx.CountUnclaimed()
+	ctx.ClaimReport = report
+
+	log.Printf("Unclaimed in account %s region %s: %s", ctx.Account, ctx.Region, report.UnclaimedSummary())
+	return report
+}
+
+// dropIdleClaimers removes the unclaimed resources that were only collected to claim others and claimed nothing
+func dropIdleClaimers(ctx *RegionalCloudContext) {
+	dropped := 0
+
+	for kind, resources := range ctx.Resources {
+		for id, resource := range resources {
+			if claimerOnly, ok := resource.(ClaimerOnly); ok && claimerOnly.ClaimedNothing() {
+				delete(ctx.Resources[kind], id)
+				dropped++
+			}
+		}
+	}
+
+	if dropped > 0 {
+		log.Printf("Dropped %d resources that claimed nothing in account %s region %s", dropped, ctx.Account, ctx.Region)
+	}
+}
+
+func (report *ClaimReport) UnclaimedSummary() string {
+	var resourceTypes []string
+	for resourceType, count := range report.Unclaimed {
+		if count > 0 {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+	}
+
+	if len(resourceTypes) == 0 {
+		return "nothing"
+	}
+
+	sort.Strings(resourceTypes)
+
+	var summary []string
+	for _, resourceType := range resourceTypes {
+		summary = append(summary, fmt.Sprintf("%d %s", report.Unclaimed[resourceType], resourceType))
+	}
+
+	return strings.Join(summary, ", ")
+}
+
+func getComputeVolumeClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+	volumesById := ctx.getVolumesById()
+
+	for _, claimer := range ctx.getComputeClaimers() {
+		for _, volumeId := range claimer.GetCompute().VolumeIDs {
+			if volume, ok := volumesById[volumeId]; ok {
+				candidates = append(candidates, ClaimCandidate{
+					Claimer:  claimer,
+					Resource: volume,
+					Reason:   "attached to the instance",
+				})
+			}
+		}
+	}
+
+	return candidates
+}
+
+func getCouchbaseCloudClusterClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+	ec2InstancesByClusterId := ctx.GetEC2InstancesByClusterId()
+
+	for _, couchbaseCloudCluster := range ctx.CouchbaseCloudClusters {
+		for _, ec2Instance := range ec2InstancesByClusterId[couchbaseCloudCluster.ID] {
+			if eksClusterName, ok := ec2Instance.Tags[ec2EksClusterNameTag]; ok {
+				couchbaseCloudCluster.EKSClusterName = eksClusterName
+			}
+
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  couchbaseCloudCluster,
+				Resource: ec2Instance,
+				Reason:   fmt.Sprintf("tagged with %s", EC2ClusterIdTagName),
+			})
+		}
+	}
+
+	return candidates
+}
+
+func getEKSClusterCouchbaseClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+	couchbaseCloudClustersByEKSName := ctx.GetCouchbaseCloudClustersByEKSName()
+
+	for _, eksCluster := range ctx.EKSClusters {
+		claimer := eksCluster
+
+		for _, couchbaseCluster := range couchbaseCloudClustersByEKSName[eksCluster.Name] {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  &claimer,
+				Resource: couchbaseCluster,
+				Reason:   "nodes tagged with the EKS cluster name",
+			})
+		}
+	}
+
+	return candidates
+}
+
+func getEKSClusterEC2ClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+	ec2InstancesBySubnetId := ctx.GetEC2InstancesBySubnetId()
+
+	for _, eksCluster := range ctx.EKSClusters {
+		claimer := eksCluster
+
+		// Linking EKS clusters to EC2 instances directly and reliably is not possible without K8S permissions.
+		// Assume all EC2 instances within subnets associated with EKS cluster belong to it
+		for _, eksSubnetId := range eksCluster.SubnetIDs {
+			for _, ec2Instance := range ec2InstancesBySubnetId[eksSubnetId] {
+				candidates = append(candidates, ClaimCandidate{
+					Claimer:  &claimer,
+					Resource: ec2Instance,
+					Reason:   fmt.Sprintf("in EKS subnet %s", eksSubnetId),
+				})
+			}
+		}
+	}
+
+	return candidates
+}
+
+func getStackClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+	kindsByResourceType := RegisteredStackResourceTypes()
+
+	for _, claimer := range ctx.getStackClaimers() {
+		for _, stackResource := range claimer.GetStack().Resources {
+			kind, ok := kindsByResourceType[stackResource.Type]
+			if !ok || stackResource.PhysicalID == "" {
+				continue
+			}
+
+			if resource, ok := ctx.getUnclaimedResource(kind, stackResource.PhysicalID); ok {
+				candidates = append(candidates, ClaimCandidate{
+					Claimer:  claimer,
+					Resource: resource,
+					Reason:   fmt.Sprintf("listed in the stack resources as %s", stackResource.LogicalID),
+				})
+			}
+		}
+	}
+
+	return candidates
+}
+
+func getCouchbaseCloudClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+	eksClustersByCloudId := ctx.GetEKSClustersByCloudId()
+	cloudformationStacksByCloudId := ctx.GetCloudformationStacksByCloudId()
+
+	for _, couchbaseCloud := range ctx.CouchbaseClouds {
+		if eksCluster, ok := eksClustersByCloudId[couchbaseCloud.ID]; ok {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  couchbaseCloud,
+				Resource: eksCluster,
+				Reason:   fmt.Sprintf("tagged with %s", EKSClusterCloudIdTag),
+			})
+		}
+
+		if cloudformationStack, ok := cloudformationStacksByCloudId[couchbaseCloud.ID]; ok {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  couchbaseCloud,
+				Resource: cloudformationStack,
+				Reason:   fmt.Sprintf("stack parameter %s", CloudformationCloudIdParameter),
+			})
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,403 @@
+package monitoring
+
+import (
+	"testing"
+)
+
+const (
+	testAccount = "123456789012"
+	testRegion  = "eu-west-1"
+)
+
+func newTestEC2Instance(id string, subnetId string, tags map[string]string, volumeIds ...string) EC2Instance {
+	ec2Instance := NewEC2Instance()
+	ec2Instance.ID = id
+	ec2Instance.Provider = ProviderAWS
+	ec2Instance.Account = testAccount
+	ec2Instance.Region = testRegion
+	ec2Instance.SubnetID = subnetId
+	ec2Instance.VolumeIDs = volumeIds
+	ec2Instance.State = "running"
+	ec2Instance.Tags = tags
+	if ec2Instance.Tags == nil {
+		ec2Instance.Tags = map[string]string{}
+	}
+
+	return *ec2Instance
+}
+
+func newTestEBSVolume(id string) EBSVolume {
+	ebsVolume := NewEBSVolume()
+	ebsVolume.ID = id
+	ebsVolume.Provider = ProviderAWS
+	ebsVolume.Account = testAccount
+	ebsVolume.Region = testRegion
+	ebsVolume.Tags = map[string]string{}
+
+	return *ebsVolume
+}
+
+func newTestEKSCluster(name string, tags map[string]string, subnetIds ...string) EKSCluster {
+	eksCluster := NewEKSCluster()
+	eksCluster.ID = name
+	eksCluster.Name = name
+	eksCluster.Provider = ProviderAWS
+	eksCluster.Account = testAccount
+	eksCluster.Region = testRegion
+	eksCluster.SubnetIDs = subnetIds
+	eksCluster.Tags = tags
+	if eksCluster.Tags == nil {
+		eksCluster.Tags = map[string]string{}
+	}
+
+	return *eksCluster
+}
+
+func newTestCloudformationStack(id string, parameters map[string]string, resources ...StackResource) CloudformationStack {
+	cloudformationStack := NewCloudFormationStack()
+	cloudformationStack.ID = id
+	cloudformationStack.Name = id
+	cloudformationStack.Provider = ProviderAWS
+	cloudformationStack.Account = testAccount
+	cloudformationStack.Region = testRegion
+	cloudformationStack.Tags = map[string]string{}
+	cloudformationStack.Parameters = parameters
+	cloudformationStack.Resources = resources
+
+	return *cloudformationStack
+}
+
+func newTestCouchbaseCloudCluster(id string) *CouchbaseCloudCluster {
+	couchbaseCloudCluster := NewCouchbaseCloudCluster()
+	couchbaseCloudCluster.ID = id
+	couchbaseCloudCluster.Name = id
+
+	return couchbaseCloudCluster
+}
+
+func newTestCouchbaseCloud(id string) *CouchbaseCloud {
+	couchbaseCloud := NewCouchbaseCloud()
+	couchbaseCloud.ID = id
+	couchbaseCloud.Name = id
+
+	return couchbaseCloud
+}
+
+// countRecords counts the claim records made by a rule, by whether they were claimed
+func countRecords(report *ClaimReport, rule string, claimed bool) int {
+	count := 0
+
+	for _, record := range report.Records {
+		if record.Rule == rule && record.Claimed == claimed {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestProcessClaims(t *testing.T) {
+	tests := []struct {
+		name          string
+		collector     *FakeCollector
+		setup         func(ctx *RegionalCloudContext)
+		wantUnclaimed map[string]int
+		check         func(t *testing.T, ctx *RegionalCloudContext, report *ClaimReport)
+	}{
+		{
+			name:      "nothing collected",
+			collector: &FakeCollector{},
+			wantUnclaimed: map[string]int{
+				ResourceTypeEBSVolume:   0,
+				ResourceTypeEC2Instance: 0,
+			},
+			check: func(t *testing.T, ctx *RegionalCloudContext, report *ClaimReport) {
+				if len(report.Records) != 0 {
+					t.Errorf("expected no claim records, got %+v", report.Records)
+				}
+				if report.UnclaimedSummary() != "nothing" {
+					t.Errorf("expected nothing unclaimed, got %s", report.UnclaimedSummary())
+				}
+			},
+		},
+		{
+			name: "instances claim their attached volumes",
+			collector: &FakeCollector{
+				EC2Instances: []EC2Instance{newTestEC2Instance("i-1", "subnet-a", nil, "vol-1")},
+				EBSVolumes:   []EBSVolume{newTestEBSVolume("vol-1"), newTestEBSVolume("vol-2")},
+			},
+			wantUnclaimed: map[string]int{
+				ResourceTypeEBSVolume:   1,
+				ResourceTypeEC2Instance: 1,
+			},
+			check: func(t *testing.T, ctx *RegionalCloudContext, report *ClaimReport) {
+				if _, ok := ctx.EC2Instances["i-1"].EBSVolumes["vol-1"]; !ok {
+					t.Errorf("expected i-1 to hold vol-1")
+				}
+				if _, ok := ctx.EBSVolumes["vol-2"]; !ok {
+					t.Errorf("expected the detached vol-2 to be left unclaimed")
+				}
+			},
+		},
+		{
+			name: "EKS clusters claim instances in their subnets along with their volumes",
+			collector: &FakeCollector{
+				EKSClusters:  []EKSCluster{newTestEKSCluster("eks-1", nil, "subnet-a")},
+				EC2Instances: []EC2Instance{newTestEC2Instance("i-1", "subnet-a", nil, "vol-1"), newTestEC2Instance("i-2", "subnet-b", nil)},
+				EBSVolumes:   []EBSVolume{newTestEBSVolume("vol-1")},
+			},
+			wantUnclaimed: map[string]int{
+				ResourceTypeEBSVolume:   0,
+				ResourceTypeEC2Instance: 1,
+				ResourceTypeEKSCluster:  1,
+			},
+			check: func(t *testing.T, ctx *RegionalCloudContext, report *ClaimReport) {
+				ec2Instance, ok := ctx.EKSClusters["eks-1"].EC2Instances["i-1"]
+				if !ok {
+					t.Fatalf("expected eks-1 to hold i-1")
+				}
+				if _, ok := ec2Instance.EBSVolumes["vol-1"]; !ok {
+					t.Errorf("expected vol-1 to move along with i-1")
+				}
+				if _, ok := ctx.EC2Instances["i-2"]; !ok {
+					t.Errorf("expected i-2 outside the EKS subnets to be left unclaimed")
+				}
+			},
+		},
+		{
+			name: "Couchbase Cloud clusters claim tagged instances before EKS clusters",
+			collector: &FakeCollector{
+				EKSClusters: []EKSCluster{newTestEKSCluster("eks-1", nil, "subnet-a")},
+				EC2Instances: []EC2Instance{
+					newTestEC2Instance("i-1", "subnet-a", map[string]string{EC2ClusterIdTagName: "db-1", ec2EksClusterNameTag: "eks-1"}),
+				},
+			},
+			setup: func(ctx *RegionalCloudContext) {
+				ctx.CouchbaseCloudClusters["db-1"] = newTestCouchbaseCloudCluster("db-1")
+			},
+			wantUnclaimed: map[string]int{
+				ResourceTypeEC2Instance:           0,
+				ResourceTypeCouchbaseCloudCluster: 0,
+				ResourceTypeEKSCluster:            1,
+			},
+			check: func(t *testing.T, ctx *RegionalCloudContext, report *ClaimReport) {
+				couchbaseCloudCluster, ok := ctx.EKSClusters["eks-1"].CouchbaseCloudClusters["db-1"]
+				if !ok {
+					t.Fatalf("expected eks-1 to hold the Couchbase Cloud cluster tagged with its name")
+				}
+				if _, ok := couchbaseCloudCluster.EC2Instances["i-1"]; !ok {
+					t.Errorf("expected db-1 to hold i-1")
+				}
+				if !couchbaseCloudCluster.Seen {
+					t.Errorf("expected db-1 to be seen")
+				}
+				if records := countRecords(report, "EKS clusters claim EC2 instances", true) + countRecords(report, "EKS clusters claim EC2 instances", false); records != 0 {
+					t.Errorf("expected no EKS claims on the already claimed i-1, got %d", records)
+				}
+			},
+		},
+		{
+			name: "the first claimer wins when EKS clusters share a subnet",
+			collector: &FakeCollector{
+				EKSClusters:  []EKSCluster{newTestEKSCluster("eks-1", nil, "subnet-a"), newTestEKSCluster("eks-2", nil, "subnet-a")},
+				EC2Instances: []EC2Instance{newTestEC2Instance("i-1", "subnet-a", nil)},
+			},
+			wantUnclaimed: map[string]int{
+				ResourceTypeEC2Instance: 0,
+				ResourceTypeEKSCluster:  2,
+			},
+			check: func(t *testing.T, ctx *RegionalCloudContext, report *ClaimReport) {
+				if claimed := countRecords(report, "EKS clusters claim EC2 instances", true); claimed != 1 {
+					t.Errorf("expected i-1 to be claimed once, got %d", claimed)
+				}
+				if rejected := countRecords(report, "EKS clusters claim EC2 instances", false); rejected != 1 {
+					t.Errorf("expected the second claim on i-1 to be rejected, got %d", rejected)
+				}
+				for _, record := range report.Records {
+					if !record.Claimed && record.Reason != alreadyClaimed().Reason {
+						t.Errorf("expected the rejection to say i-1 was already claimed, got %q", record.Reason)
+					}
+				}
+				if len(ctx.EKSClusters["eks-1"].EC2Instances)+len(ctx.EKSClusters["eks-2"].EC2Instances) != 1 {
+					t.Errorf("expected i-1 to be held by exactly one EKS cluster")
+				}
+			},
+		},
+		{
+			name: "Couchbase Clouds claim their EKS cluster and stack, which claims the instances it lists",
+			collector: &FakeCollector{
+				EKSClusters: []EKSCluster{newTestEKSCluster("eks-1", map[string]string{EKSClusterCloudIdTag: "cloud-1"})},
+				CloudFormationStacks: []CloudformationStack{
+					newTestCloudformationStack("stack-1", map[string]string{CloudformationCloudIdParameter: "cloud-1"},
+						StackResource{LogicalID: "Bastion", PhysicalID: "i-3", Type: cloudformationEc2StackResourceId},
+						StackResource{LogicalID: "Missing", PhysicalID: "i-404", Type: cloudformationEc2StackResourceId}),
+					newTestCloudformationStack("stack-2", nil),
+				},
+				EC2Instances: []EC2Instance{newTestEC2Instance("i-3", "subnet-c", nil)},
+			},
+			setup: func(ctx *RegionalCloudContext) {
+				ctx.CouchbaseClouds["cloud-1"] = newTestCouchbaseCloud("cloud-1")
+			},
+			wantUnclaimed: map[string]int{
+				ResourceTypeEC2Instance:         0,
+				ResourceTypeEKSCluster:          0,
+				ResourceTypeCloudformationStack: 1,
+			},
+			check: func(t *testing.T, ctx *RegionalCloudContext, report *ClaimReport) {
+				couchbaseCloud := ctx.CouchbaseClouds["cloud-1"]
+				if !couchbaseCloud.Seen {
+					t.Errorf("expected cloud-1 to be seen")
+				}
+				if _, ok := couchbaseCloud.EKSClusters["eks-1"]; !ok {
+					t.Errorf("expected cloud-1 to hold eks-1")
+				}
+				if couchbaseCloud.CloudFormationStack == nil {
+					t.Fatalf("expected cloud-1 to hold stack-1")
+				}
+				if _, ok := couchbaseCloud.CloudFormationStack.EC2Instances["i-3"]; !ok {
+					t.Errorf("expected stack-1 to hold i-3")
+				}
+				if _, ok := ctx.CloudFormationStacks["stack-2"]; !ok {
+					t.Errorf("expected stack-2 without a cloud ID to be left unclaimed")
+				}
+			},
+		},
+		{
+			name: "RDS clusters claim their members and VPCs claim their NAT gateways",
+			collector: &FakeCollector{
+				Resources: []ReportableResource{
+					func() ReportableResource {
+						rdsCluster := NewRDSCluster()
+						rdsCluster.ID = "aurora-1"
+						rdsCluster.MemberIDs = []string{"aurora-1-a", "aurora-1-b"}
+						return *rdsCluster
+					}(),
+					func() ReportableResource {
+						rdsInstance := NewRDSInstance()
+						rdsInstance.ID = "aurora-1-a"
+						return *rdsInstance
+					}(),
+					func() ReportableResource {
+						rdsInstance := NewRDSInstance()
+						rdsInstance.ID = "postgres-1"
+						return *rdsInstance
+					}(),
+					func() ReportableResource {
+						vpc := NewVPC()
+						vpc.ID = "vpc-1"
+						return *vpc
+					}(),
+					func() ReportableResource {
+						natGateway := NewNATGateway()
+						natGateway.ID = "nat-1"
+						natGateway.VPCID = "vpc-1"
+						return *natGateway
+					}(),
+				},
+			},
+			wantUnclaimed: map[string]int{
+				ResourceTypeRDSCluster:  1,
+				ResourceTypeRDSInstance: 1,
+				ResourceTypeVPC:         1,
+				ResourceTypeNATGateway:  0,
+			},
+			check: func(t *testing.T, ctx *RegionalCloudContext, report *ClaimReport) {
+				rdsCluster := ctx.Resources[ResourceTypeRDSCluster]["aurora-1"].(RDSCluster)
+				if len(rdsCluster.Instances) != 1 {
+					t.Errorf("expected aurora-1 to hold its one listed member, got %d", len(rdsCluster.Instances))
+				}
+				if _, ok := ctx.Resources[ResourceTypeRDSInstance]["postgres-1"]; !ok {
+					t.Errorf("expected postgres-1 outside the cluster to be left unclaimed")
+				}
+				vpc := ctx.Resources[ResourceTypeVPC]["vpc-1"].(VPC)
+				if _, ok := vpc.NATGateways["nat-1"]; !ok {
+					t.Errorf("expected vpc-1 to hold nat-1")
+				}
+			},
+		},
+		{
+			name: "VPCs without NAT gateways are only kept by their stack",
+			collector: &FakeCollector{
+				CloudFormationStacks: []CloudformationStack{
+					newTestCloudformationStack("stack-1", nil, StackResource{LogicalID: "VPC", PhysicalID: "vpc-stack", Type: cloudformationVPCStackResourceId}),
+				},
+				Resources: []ReportableResource{
+					func() ReportableResource {
+						vpc := NewVPC()
+						vpc.ID = "vpc-default"
+						vpc.IsDefault = true
+						return *vpc
+					}(),
+					func() ReportableResource {
+						vpc := NewVPC()
+						vpc.ID = "vpc-stack"
+						return *vpc
+					}(),
+					func() ReportableResource {
+						vpc := NewVPC()
+						vpc.ID = "vpc-nat"
+						return *vpc
+					}(),
+					func() ReportableResource {
+						natGateway := NewNATGateway()
+						natGateway.ID = "nat-1"
+						natGateway.VPCID = "vpc-nat"
+						return *natGateway
+					}(),
+				},
+			},
+			wantUnclaimed: map[string]int{
+				ResourceTypeVPC:                 1,
+				ResourceTypeNATGateway:          0,
+				ResourceTypeCloudformationStack: 1,
+			},
+			check: func(t *testing.T, ctx *RegionalCloudContext, report *ClaimReport) {
+				if _, ok := ctx.Resources[ResourceTypeVPC]["vpc-default"]; ok {
+					t.Errorf("expected vpc-default without NAT gateways to be dropped")
+				}
+				if _, ok := ctx.Resources[ResourceTypeVPC]["vpc-nat"]; !ok {
+					t.Errorf("expected vpc-nat holding nat-1 to be kept")
+				}
+				if _, ok := ctx.CloudFormationStacks["stack-1"].ReportableResources[ResourceTypeVPC]["vpc-stack"]; !ok {
+					t.Errorf("expected stack-1 to hold vpc-stack")
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scope := &ScanScope{Provider: ProviderAWS, Account: testAccount, Region: testRegion}
+			ctx, scanErrors := collectRegion(scope, []Collector{test.collector})
+			if len(scanErrors) > 0 {
+				t.Fatalf("unexpected scan errors: %+v", scanErrors)
+			}
+
+			if test.setup != nil {
+				test.setup(ctx)
+			}
+
+			report := processClaims(ctx)
+
+			if ctx.ClaimReport != report {
+				t.Errorf("expected the claim report to be kept on the context")
+			}
+
+			for resourceType, want := range test.wantUnclaimed {
+				if got := report.Unclaimed[resourceType]; got != want {
+					t.Errorf("unclaimed %s = %d, want %d", resourceType, got, want)
+				}
+			}
+
+			for _, record := range report.Records {
+				if record.Reason == "" {
+					t.Errorf("claim record %+v has no reason", record)
+				}
+			}
+
+			if test.check != nil {
+				test.check(t, ctx, report)
+			}
+		})
+	}
+}
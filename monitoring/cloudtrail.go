@@ -0,0 +1,267 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"log"
+	"strings"
+	"time"
+)
+
+// CloudTrail only keeps management events for 90 days
+const cloudTrailRetention = 90 * 24 * time.Hour
+
+const (
+	LaunchedBySourceCloudTrail = "CloudTrail"
+	LaunchedBySourceTag        = "tag"
+)
+
+var launchedByTags = []string{"Owner", "owner", "CreatedBy", "created-by", "created_by", "Creator", "creator"}
+
+type cloudTrailEventDetail struct {
+	UserIdentity struct {
+		Type     string `json:"type"`
+		Arn      string `json:"arn"`
+		UserName string `json:"userName"`
+	} `json:"userIdentity"`
+	RequestParameters map[string]interface{} `json:"requestParameters"`
+	ResponseElements  map[string]interface{} `json:"responseElements"`
+}
+
+type launchEventLookup struct {
+	EventName   string
+	EventSource string
+	ResourceIds map[string]bool
+	Since       time.Time
+	// Extracts resource IDs from the event body for events that do not list them as resources
+	GetResourceIds func(detail cloudTrailEventDetail) []string
+}
+
+type LaunchedByEnricher struct{}
+
+func (enricher *LaunchedByEnricher) Name() string {
+	return "launched by from CloudTrail"
+}
+
+func (enricher *LaunchedByEnricher) Enrich(scope *ScanScope, ctx *RegionalCloudContext) error {
+	cloudTrailService := cloudtrail.New(scope.Session, getAWSConfig(scope.Credentials, scope.Region))
+	now := time.Now()
+
+	ec2Lookup := newLaunchEventLookup("RunInstances", "ec2.amazonaws.com", now)
+	ebsLookup := newLaunchEventLookup("CreateVolume", "ec2.amazonaws.com", now)
+	eksLookup := newLaunchEventLookup("CreateCluster", "eks.amazonaws.com", now)
+	stackLookup := newLaunchEventLookup("CreateStack", "cloudformation.amazonaws.com", now)
+
+	ebsLookup.GetResourceIds = getResponseElementIds("volumeId")
+	eksLookup.GetResourceIds = getRequestParameterIds("name")
+	stackLookup.GetResourceIds = getResponseElementIds("stackId")
+
+	for id, ec2Instance := range ctx.EC2Instances {
+		ec2Lookup.Add(id, ec2Instance.CreatedAt)
+	}
+
+	for id, ebsVolume := range ctx.EBSVolumes {
+		ebsLookup.Add(id, ebsVolume.CreatedAt)
+	}
+
+	for name, eksCluster := range ctx.EKSClusters {
+		eksLookup.Add(name, eksCluster.CreatedAt)
+	}
+
+	for id, cloudformationStack := range ctx.CloudFormationStacks {
+		stackLookup.Add(id, cloudformationStack.CreatedAt)
+	}
+
+	var lookupErr error
+	principals := map[string]string{}
+
+	for _, lookup := range []*launchEventLookup{ec2Lookup, ebsLookup, eksLookup, stackLookup} {
+		if err := lookup.Run(cloudTrailService, principals); err != nil && lookupErr == nil {
+			lookupErr = err
+		}
+	}
+
+	for id, ec2Instance := range ctx.EC2Instances {
+		ec2Instance.CloudResource = setLaunchedBy(ec2Instance.CloudResource, principals[id])
+		ctx.EC2Instances[id] = ec2Instance
+	}
+
+	for id, ebsVolume := range ctx.EBSVolumes {
+		ebsVolume.CloudResource = setLaunchedBy(ebsVolume.CloudResource, principals[id])
+		ctx.EBSVolumes[id] = ebsVolume
+	}
+
+	for name, eksCluster := range ctx.EKSClusters {
+		eksCluster.CloudResource = setLaunchedBy(eksCluster.CloudResource, principals[name])
+		ctx.EKSClusters[name] = eksCluster
+	}
+
+	for id, cloudformationStack := range ctx.CloudFormationStacks {
+		cloudformationStack.CloudResource = setLaunchedBy(cloudformationStack.CloudResource, principals[id])
+		ctx.CloudFormationStacks[id] = cloudformationStack
+	}
+
+	log.Printf("Found launching principals for %d resources in account %s region %s", len(principals), scope.Account, scope.Region)
+	return lookupErr
+}
+
+func newLaunchEventLookup(eventName string, eventSource string, now time.Time) *launchEventLookup {
+	return &launchEventLookup{
+		EventName:   eventName,
+		EventSource: eventSource,
+		ResourceIds: make(map[string]bool),
+		Since:       now,
+	}
+}
+
+func (lookup *launchEventLookup) Add(id string, createdAt time.Time) {
+	lookup.ResourceIds[id] = true
+
+	if !createdAt.IsZero() && createdAt.Before(lookup.Since) {
+		lookup.Since = createdAt
+	}
+}
+
+// Run looks up launch events back to the oldest resource still missing a principal, or as far as CloudTrail retains them
+func (lookup *launchEventLookup) Run(cloudTrailService *cloudtrail.CloudTrail, principals map[string]string) error {
+	if len(lookup.ResourceIds) == 0 {
+		return nil
+	}
+
+	oldestRetained := time.Now().Add(-cloudTrailRetention)
+	if lookup.Since.Before(oldestRetained) {
+		lookup.Since = oldestRetained
+	}
+
+	remaining := len(lookup.ResourceIds)
+
+	err := lookupCloudTrailEvents(cloudTrailService, lookup.EventName, lookup.EventSource, lookup.Since, func(event *cloudtrail.Event, detail cloudTrailEventDetail) bool {
+		principal := getPrincipal(event, detail)
+		if principal == "" {
+			return true
+		}
+
+		var ids []string
+		for _, resource := range event.Resources {
+			ids = append(ids, aws.StringValue(resource.ResourceName))
+		}
+
+		if lookup.GetResourceIds != nil {
+			ids = append(ids, lookup.GetResourceIds(detail)...)
+		}
+
+		for _, id := range ids {
+			if _, ok := principals[id]; lookup.ResourceIds[id] && !ok {
+				principals[id] = principal
+				remaining--
+			}
+		}
+
+		return remaining > 0
+	})
+
+	if err != nil {
+		return fmt.Errorf("unable to look up %s events %w", lookup.EventName, err)
+	}
+
+	return nil
+}
+
+// lookupCloudTrailEvents pages through events until visit returns false
+func lookupCloudTrailEvents(cloudTrailService *cloudtrail.CloudTrail, eventName string, eventSource string, since time.Time, visit func(event *cloudtrail.Event, detail cloudTrailEventDetail) bool) error {
+	input := &cloudtrail.LookupEventsInput{
+		StartTime: aws.Time(since),
+		LookupAttributes: []*cloudtrail.LookupAttribute{
+			{
+				AttributeKey:   aws.String(cloudtrail.LookupAttributeKeyEventName),
+				AttributeValue: aws.String(eventName),
+			},
+		},
+	}
+
+	return cloudTrailService.LookupEventsPages(input, func(page *cloudtrail.LookupEventsOutput, lastPage bool) bool {
+		for _, event := range page.Events {
+			if aws.StringValue(event.EventSource) != eventSource {
+				continue
+			}
+
+			var detail cloudTrailEventDetail
+			if event.CloudTrailEvent != nil {
+				if err := json.Unmarshal([]byte(*event.CloudTrailEvent), &detail); err != nil {
+					log.Printf("Unable to parse CloudTrail event %s: %s", aws.StringValue(event.EventId), err)
+				}
+			}
+
+			if !visit(event, detail) {
+				return false
+			}
+		}
+
+		return !lastPage
+	})
+}
+
+func getPrincipal(event *cloudtrail.Event, detail cloudTrailEventDetail) string {
+	if detail.UserIdentity.Arn != "" {
+		return detail.UserIdentity.Arn
+	}
+
+	return aws.StringValue(event.Username)
+}
+
+func getResponseElementIds(key string) func(detail cloudTrailEventDetail) []string {
+	return func(detail cloudTrailEventDetail) []string {
+		if id, ok := detail.ResponseElements[key].(string); ok {
+			return []string{id}
+		}
+
+		return nil
+	}
+}
+
+func getRequestParameterIds(key string) func(detail cloudTrailEventDetail) []string {
+	return func(detail cloudTrailEventDetail) []string {
+		if id, ok := detail.RequestParameters[key].(string); ok {
+			return []string{id}
+		}
+
+		return nil
+	}
+}
+
+// setLaunchedBy prefers the CloudTrail principal, falling back to ownership tags once CloudTrail has aged out
+func setLaunchedBy(resource CloudResource, principal string) CloudResource {
+	if principal != "" {
+		resource.LaunchedBy = principal
+		resource.LaunchedBySource = LaunchedBySourceCloudTrail
+		return resource
+	}
+
+	for _, tag := range launchedByTags {
+		if owner, ok := resource.Tags[tag]; ok && owner != "" {
+			resource.LaunchedBy = owner
+			resource.LaunchedBySource = fmt.Sprintf("%s %s", LaunchedBySourceTag, tag)
+			return resource
+		}
+	}
+
+	return resource
+}
+
+// GetPrincipalName reduces an IAM principal ARN to the user name or role session name
+func GetPrincipalName(principal string) string {
+	if !strings.HasPrefix(principal, "arn:") {
+		return principal
+	}
+
+	resource := principal[strings.LastIndex(principal, ":")+1:]
+
+	// arn:aws:sts::account:assumed-role/role-name/session-name and arn:aws:iam::account:user/path/user-name
+	if idx := strings.LastIndex(resource, "/"); idx != -1 {
+		return resource[idx+1:]
+	}
+
+	return resource
+}
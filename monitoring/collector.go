@@ -0,0 +1,94 @@
+package monitoring
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const (
+	ProviderAWS   = "aws"
+	ProviderAzure = "azure"
+	ProviderGCP   = "gcp"
+)
+
+// ScanScope is a single account region to scan. AWS scopes carry the assumed role credentials, Azure and GCP scopes
+// carry the inventory already listed for the subscription or project.
+type ScanScope struct {
+	Provider    string
+	Session     *session.Session
+	Credentials *sts.Credentials
+	Azure       *AzureInventory
+	GCP         *GCPInventory
+	Account     string
+	Region      string
+}
+
+type Collector interface {
+	Name() string
+	Collect(scope *ScanScope, ctx *RegionalCloudContext) error
+}
+
+type ReportField struct {
+	Label string
+	Value string
+}
+
+// ReportableResource lets collectors add resource types beyond the built-in EC2/EBS/EKS/Cloudformation ones.
+// They are stored in RegionalCloudContext.Resources and rendered generically by the views.
+type ReportableResource interface {
+	Resource() CloudResource
+	// WithCloudResource returns a copy of the resource with its CloudResource replaced, as resources are stored by value
+	WithCloudResource(resource CloudResource) ReportableResource
+	Kind() string
+	ReportFields() []ReportField
+}
+
+// ReportableParent is implemented by reportable resources that claim other reportable resources, so views can nest them
+type ReportableParent interface {
+	ClaimedResources() []ReportableResource
+}
+
+// NodeTypeCollector is implemented by collectors that say which types of resource they collect, so when one fails only
+// those types are treated as missing rather than everything in the region
+type NodeTypeCollector interface {
+	NodeTypes() []NodeType
+}
+
+// ClaimerOnly is implemented by reportable resources that are only collected so they can claim others. Those left
+// unclaimed are dropped from the report once claims are processed when ClaimedNothing is true.
+type ClaimerOnly interface {
+	ClaimedNothing() bool
+}
+
+// OrphanCandidate is implemented by reportable resources that are left behind once whatever used them is deleted.
+// OrphanReason is empty while the resource is still in use.
+type OrphanCandidate interface {
+	OrphanReason() string
+}
+
+var collectorRegistry []Collector
+
+func RegisterCollector(collector Collector) {
+	collectorRegistry = append(collectorRegistry, collector)
+}
+
+func RegisteredCollectors() []Collector {
+	registered := make([]Collector, len(collectorRegistry))
+	copy(registered, collectorRegistry)
+	return registered
+}
+
+func init() {
+	RegisterCollector(&EBSVolumeCollector{})
+	RegisterCollector(&EC2InstanceCollector{})
+	RegisterCollector(&EKSClusterCollector{})
+	RegisterCollector(&CloudformationStackCollector{})
+	RegisterCollector(&RDSInstanceCollector{})
+	RegisterCollector(&RDSClusterCollector{})
+	RegisterCollector(&ElastiCacheClusterCollector{})
+	RegisterCollector(&OpenSearchDomainCollector{})
+	RegisterCollector(&VPCCollector{})
+	RegisterCollector(&LoadBalancerCollector{})
+	RegisterCollector(&NATGatewayCollector{})
+	RegisterCollector(&ElasticIPCollector{})
+}
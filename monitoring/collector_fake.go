@@ -0,0 +1,70 @@
+package monitoring
+
+// FakeCollector returns canned resources without calling any cloud API, so the claim pipeline can be exercised offline.
+type FakeCollector struct {
+	CollectorName        string
+	EBSVolumes           []EBSVolume
+	EC2Instances         []EC2Instance
+	EKSClusters          []EKSCluster
+	CloudFormationStacks []CloudformationStack
+	Resources            []ReportableResource
+	Err                  error
+}
+
+func (collector *FakeCollector) Name() string {
+	if collector.CollectorName == "" {
+		return "Fake"
+	}
+
+	return collector.CollectorName
+}
+
+func (collector *FakeCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	if collector.Err != nil {
+		return collector.Err
+	}
+
+	for _, ebsVolume := range collector.EBSVolumes {
+		ebsVolume.CloudResource = getFakeCloudResource(scope, ebsVolume.CloudResource)
+		ctx.EBSVolumes[ebsVolume.ID] = ebsVolume
+	}
+
+	for _, ec2Instance := range collector.EC2Instances {
+		ec2Instance.CloudResource = getFakeCloudResource(scope, ec2Instance.CloudResource)
+		ctx.EC2Instances[ec2Instance.ID] = ec2Instance
+	}
+
+	for _, eksCluster := range collector.EKSClusters {
+		eksCluster.CloudResource = getFakeCloudResource(scope, eksCluster.CloudResource)
+		ctx.EKSClusters[eksCluster.Name] = eksCluster
+	}
+
+	for _, cloudformationStack := range collector.CloudFormationStacks {
+		cloudformationStack.CloudResource = getFakeCloudResource(scope, cloudformationStack.CloudResource)
+		ctx.CloudFormationStacks[cloudformationStack.ID] = cloudformationStack
+	}
+
+	for _, resource := range collector.Resources {
+		ctx.AddResource(resource)
+	}
+
+	return nil
+}
+
+// getFakeCloudResource places resources without an account or region in the scope they are collected in, as a real
+// collector would, so the same fake can serve several scopes
+func getFakeCloudResource(scope *ScanScope, resource CloudResource) CloudResource {
+	if resource.Provider == "" {
+		resource.Provider = scope.Provider
+	}
+
+	if resource.Account == "" {
+		resource.Account = scope.Account
+	}
+
+	if resource.Region == "" {
+		resource.Region = scope.Region
+	}
+
+	return resource
+}
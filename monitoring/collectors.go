@@ -0,0 +1,97 @@
+package monitoring
+
+type EBSVolumeCollector struct{}
+
+func (collector *EBSVolumeCollector) Name() string {
+	return "EBS Volumes"
+}
+
+func (collector *EBSVolumeCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeEBSVolume}
+}
+
+func (collector *EBSVolumeCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	ec2Service := getEC2Service(scope.Session, scope.Credentials, scope.Region)
+
+	ebsVolumes, err := getEBSVolumes(ec2Service, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for id, resource := range ebsVolumes {
+		ctx.EBSVolumes[id] = resource
+	}
+
+	return nil
+}
+
+type EC2InstanceCollector struct{}
+
+func (collector *EC2InstanceCollector) Name() string {
+	return "EC2 Instances"
+}
+
+func (collector *EC2InstanceCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeEC2Instance}
+}
+
+func (collector *EC2InstanceCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	ec2Service := getEC2Service(scope.Session, scope.Credentials, scope.Region)
+
+	ec2Instances, err := getEC2Instances(ec2Service, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for id, resource := range ec2Instances {
+		ctx.EC2Instances[id] = resource
+	}
+
+	return nil
+}
+
+type EKSClusterCollector struct{}
+
+func (collector *EKSClusterCollector) Name() string {
+	return "EKS clusters"
+}
+
+func (collector *EKSClusterCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeEKSCluster}
+}
+
+func (collector *EKSClusterCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	eksClusters, err := getEKSClusters(scope.Session, scope.Credentials, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for id, resource := range eksClusters {
+		ctx.EKSClusters[id] = resource
+	}
+
+	return nil
+}
+
+type CloudformationStackCollector struct{}
+
+func (collector *CloudformationStackCollector) Name() string {
+	return "Cloudformation stacks"
+}
+
+func (collector *CloudformationStackCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeCloudformationStack}
+}
+
+func (collector *CloudformationStackCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	cloudformationStacks, err := getCloudformationStacks(scope.Session, scope.Credentials, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for id, resource := range cloudformationStacks {
+		ctx.CloudFormationStacks[id] = resource
+	}
+
+	return nil
+}
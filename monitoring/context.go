@@ -1,5 +1,11 @@
 package monitoring
 
+import (
+	"log"
+	"sort"
+	"sync"
+)
+
 const EC2ClusterIdTagName = "DatabaseID"
 const EKSClusterCloudIdTag = "CloudID"
 const CloudformationCloudIdParameter = "CloudID"
@@ -8,6 +14,32 @@ type GlobalCloudContext struct {
 	CouchbaseClouds        map[string]*CouchbaseCloud
 	CouchbaseCloudClusters map[string]*CouchbaseCloudCluster
 	RegionalCloudContexts []RegionalCloudContext
+	ScanErrors             []ScanError
+	AccountRegions         map[string][]string
+	OwnershipGraph         *OwnershipGraph
+	ReapResults            []ReapResult
+	History                *HistoryDiff
+	mutex                  sync.Mutex
+}
+
+// ScanErrorKind is the stage of a scan that failed. Only collection failures leave resources out of the inventory.
+type ScanErrorKind string
+
+const (
+	ScanErrorCollect   ScanErrorKind = "collect"
+	ScanErrorEnrich    ScanErrorKind = "enrich"
+	ScanErrorDiscovery ScanErrorKind = "discovery"
+)
+
+// ScanError records a part of the estate that could not be scanned. Region is empty when a whole account was skipped.
+// NodeTypes lists the types of resource a failed collection left out, it is empty when every type may be missing.
+type ScanError struct {
+	Account   string
+	Region    string
+	Kind      ScanErrorKind
+	NodeTypes []NodeType
+	Operation string
+	Message   string
 }
 
 type RegionalCloudContext struct {
@@ -19,32 +51,124 @@ type RegionalCloudContext struct {
 	EKSClusters            map[string]EKSCluster
 	CloudFormationStacks   map[string]CloudformationStack
 	CouchbaseClouds        map[string]*CouchbaseCloud
+	Resources              map[string]map[string]ReportableResource
+	ClaimReport            *ClaimReport
+	EstimatedCost          Cost
+	ExpiredResources       []ExpiredResource
+	ExpiryCounts           map[string]int
 }
 
 func (ctx *GlobalCloudContext) Add(regionalCtx RegionalCloudContext) {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
 	ctx.RegionalCloudContexts = append(ctx.RegionalCloudContexts, regionalCtx)
 }
 
-func (ctx *RegionalCloudContext) Claim(resource interface{}) {
+func (ctx *GlobalCloudContext) AddScanError(scanError ScanError) {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	log.Printf("Unable to %s in account: %s, region: %s. %s", scanError.Operation, scanError.Account, scanError.Region, scanError.Message)
+	ctx.ScanErrors = append(ctx.ScanErrors, scanError)
+}
+
+func (ctx *GlobalCloudContext) SortRegionalCloudContexts() {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	sort.Slice(ctx.RegionalCloudContexts, func(i, j int) bool {
+		if ctx.RegionalCloudContexts[i].Account != ctx.RegionalCloudContexts[j].Account {
+			return ctx.RegionalCloudContexts[i].Account < ctx.RegionalCloudContexts[j].Account
+		}
+
+		return ctx.RegionalCloudContexts[i].Region < ctx.RegionalCloudContexts[j].Region
+	})
+
+	sort.SliceStable(ctx.ScanErrors, func(i, j int) bool {
+		if ctx.ScanErrors[i].Account != ctx.ScanErrors[j].Account {
+			return ctx.ScanErrors[i].Account < ctx.ScanErrors[j].Account
+		}
+
+		return ctx.ScanErrors[i].Region < ctx.ScanErrors[j].Region
+	})
+}
+
+// Claim removes a resource from the unclaimed resources of the region, returning false if it was already claimed
+func (ctx *RegionalCloudContext) Claim(resource interface{}) bool {
 	switch resource.(type) {
 	case EBSVolume:
 		ebsVolume := resource.(EBSVolume)
+		_, ok := ctx.EBSVolumes[ebsVolume.ID]
 		delete(ctx.EBSVolumes, ebsVolume.ID)
+		return ok
 	case EC2Instance:
 		ec2Instance := resource.(EC2Instance)
+		_, ok := ctx.EC2Instances[ec2Instance.ID]
 		delete(ctx.EC2Instances, ec2Instance.ID)
+		return ok
 	case CouchbaseCloudCluster:
 		couchbaseCloudCluster := resource.(CouchbaseCloudCluster)
+		_, ok := ctx.CouchbaseCloudClusters[couchbaseCloudCluster.ID]
 		delete(ctx.CouchbaseCloudClusters, couchbaseCloudCluster.ID)
+		return ok
 	case EKSCluster:
 		eksCluster := resource.(EKSCluster)
+		_, ok := ctx.EKSClusters[eksCluster.Name]
 		delete(ctx.EKSClusters, eksCluster.Name)
+		return ok
 	case CloudformationStack:
 		cloudFormationStack := resource.(CloudformationStack)
+		_, ok := ctx.CloudFormationStacks[cloudFormationStack.ID]
 		delete(ctx.CloudFormationStacks, cloudFormationStack.ID)
+		return ok
 	case CouchbaseCloud:
 		couchbaseCloud := resource.(CouchbaseCloud)
+		_, ok := ctx.CouchbaseClouds[couchbaseCloud.ID]
 		delete(ctx.CouchbaseClouds, couchbaseCloud.ID)
+		return ok
+	case ReportableResource:
+		reportableResource := resource.(ReportableResource)
+		_, ok := ctx.Resources[reportableResource.Kind()][reportableResource.Resource().ID]
+		delete(ctx.Resources[reportableResource.Kind()], reportableResource.Resource().ID)
+		return ok
+	}
+
+	return false
+}
+
+func (ctx *RegionalCloudContext) CountUnclaimed() map[string]int {
+	unclaimed := map[string]int{
+		ResourceTypeEBSVolume:             len(ctx.EBSVolumes),
+		ResourceTypeEC2Instance:           len(ctx.EC2Instances),
+		ResourceTypeCouchbaseCloudCluster: len(ctx.CouchbaseCloudClusters),
+		ResourceTypeEKSCluster:            len(ctx.EKSClusters),
+		ResourceTypeCloudformationStack:   len(ctx.CloudFormationStacks),
+	}
+
+	for kind, resources := range ctx.Resources {
+		unclaimed[kind] = len(resources)
+	}
+
+	return unclaimed
+}
+
+func (ctx *RegionalCloudContext) AddResource(resource ReportableResource) {
+	kind := resource.Kind()
+
+	if _, ok := ctx.Resources[kind]; !ok {
+		ctx.Resources[kind] = make(map[string]ReportableResource)
+	}
+
+	ctx.Resources[kind][resource.Resource().ID] = resource
+}
+
+// updateCloudResources replaces the CloudResource of every provider neutral resource with the one update returns
+func (ctx *RegionalCloudContext) updateCloudResources(update func(resource ReportableResource) CloudResource) {
+	for kind, resources := range ctx.Resources {
+		for id, resource := range resources {
+			ctx.Resources[kind][id] = resource.WithCloudResource(update(resource))
+		}
 	}
 }
 
@@ -52,7 +176,9 @@ func NewGlobalCloudContext() *GlobalCloudContext {
 	return &GlobalCloudContext{
 		CouchbaseClouds: make(map[string]*CouchbaseCloud),
 		CouchbaseCloudClusters: make(map[string]*CouchbaseCloudCluster),
-		RegionalCloudContexts: make([]RegionalCloudContext, 100),
+		RegionalCloudContexts: make([]RegionalCloudContext, 0, 100),
+		AccountRegions: make(map[string][]string),
+		OwnershipGraph: NewOwnershipGraph(),
 	}
 }
 
@@ -66,6 +192,8 @@ func NewRegionalCloudContext(account string, region string) *RegionalCloudContex
 		EKSClusters:            make(map[string]EKSCluster),
 		CloudFormationStacks:   make(map[string]CloudformationStack),
 		CouchbaseClouds:        make(map[string]*CouchbaseCloud),
+		Resources:              make(map[string]map[string]ReportableResource),
+		ExpiryCounts:           make(map[string]int),
 	}
 }
 
@@ -128,3 +256,79 @@ func (ctx *RegionalCloudContext) GetCloudformationStacksByCloudId() map[string]C
 	return cloudformationStacks
 }
 
+
+// getUnclaimedResource looks up a resource of any kind by the ID stacks and instances refer to it with
+func (ctx *RegionalCloudContext) getUnclaimedResource(kind string, id string) (interface{}, bool) {
+	switch kind {
+	case ResourceTypeEBSVolume:
+		ebsVolume, ok := ctx.EBSVolumes[id]
+		return ebsVolume, ok
+	case ResourceTypeEC2Instance:
+		ec2Instance, ok := ctx.EC2Instances[id]
+		return ec2Instance, ok
+	case ResourceTypeEKSCluster:
+		eksCluster, ok := ctx.EKSClusters[id]
+		return eksCluster, ok
+	case ResourceTypeCloudformationStack:
+		cloudformationStack, ok := ctx.CloudFormationStacks[id]
+		return cloudformationStack, ok
+	}
+
+	resource, ok := ctx.Resources[kind][id]
+	return resource, ok
+}
+
+func (ctx *RegionalCloudContext) getVolumesById() map[string]interface{} {
+	volumes := map[string]interface{}{}
+
+	for id, ebsVolume := range ctx.EBSVolumes {
+		volumes[id] = ebsVolume
+	}
+
+	for _, kind := range []string{ResourceTypeAzureManagedDisk, ResourceTypeGCPPersistentDisk} {
+		for id, resource := range ctx.Resources[kind] {
+			volumes[id] = resource
+		}
+	}
+
+	return volumes
+}
+
+// getComputeClaimers lists the unclaimed instances of every provider in the region
+func (ctx *RegionalCloudContext) getComputeClaimers() []ComputeClaimer {
+	var claimers []ComputeClaimer
+
+	for _, ec2Instance := range ctx.EC2Instances {
+		claimer := ec2Instance
+		claimers = append(claimers, &claimer)
+	}
+
+	for _, virtualMachine := range ctx.GetAzureVirtualMachines() {
+		claimer := virtualMachine
+		claimers = append(claimers, &claimer)
+	}
+
+	for _, instance := range ctx.GetGCPInstances() {
+		claimer := instance
+		claimers = append(claimers, &claimer)
+	}
+
+	return claimers
+}
+
+// getStackClaimers lists the unclaimed stacks of every provider in the region
+func (ctx *RegionalCloudContext) getStackClaimers() []StackClaimer {
+	var claimers []StackClaimer
+
+	for _, cloudformationStack := range ctx.CloudFormationStacks {
+		claimer := cloudformationStack
+		claimers = append(claimers, &claimer)
+	}
+
+	for _, deployment := range ctx.GetDeploymentManagerDeployments() {
+		claimer := deployment
+		claimers = append(claimers, &claimer)
+	}
+
+	return claimers
+}
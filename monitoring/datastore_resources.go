@@ -0,0 +1,303 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	ResourceTypeRDSInstance        = "RDS instances"
+	ResourceTypeRDSCluster         = "RDS clusters"
+	ResourceTypeElastiCacheCluster = "ElastiCache clusters"
+	ResourceTypeOpenSearchDomain   = "OpenSearch domains"
+)
+
+const (
+	NodeRDSInstance        NodeType = "rds-instance"
+	NodeRDSCluster         NodeType = "rds-cluster"
+	NodeElastiCacheCluster NodeType = "elasticache-cluster"
+	NodeOpenSearchDomain   NodeType = "opensearch-domain"
+)
+
+const EdgeMemberOfRDSCluster EdgeType = "member-of-rds-cluster"
+
+const (
+	cloudformationRDSInstanceStackResourceId         = "AWS::RDS::DBInstance"
+	cloudformationRDSClusterStackResourceId          = "AWS::RDS::DBCluster"
+	cloudformationCacheClusterStackResourceId        = "AWS::ElastiCache::CacheCluster"
+	cloudformationReplicationGroupStackResourceId    = "AWS::ElastiCache::ReplicationGroup"
+	cloudformationElasticsearchDomainStackResourceId = "AWS::Elasticsearch::Domain"
+	cloudformationOpenSearchDomainStackResourceId    = "AWS::OpenSearchService::Domain"
+)
+
+type RDSInstance struct {
+	DataStore
+	ARN         string
+	StorageType string
+	ClusterID   string
+}
+
+// RDSCluster is an Aurora or Multi-AZ DB cluster, its instances are reported under it once claimed
+type RDSCluster struct {
+	DataStore
+	ARN        string
+	EngineMode string
+	MemberIDs  []string
+	Instances  map[string]RDSInstance
+}
+
+// ElastiCacheCluster is a replication group, or a cache cluster that is not part of one
+type ElastiCacheCluster struct {
+	DataStore
+	ARN       string
+	NodeCount int
+	MemberIDs []string
+}
+
+// OpenSearchDomain also covers legacy Elasticsearch domains. The API does not return a creation time for domains.
+type OpenSearchDomain struct {
+	DataStore
+	ARN           string
+	InstanceCount int64
+}
+
+func NewRDSInstance() *RDSInstance {
+	return &RDSInstance{}
+}
+
+func NewRDSCluster() *RDSCluster {
+	return &RDSCluster{
+		Instances: make(map[string]RDSInstance),
+	}
+}
+
+func NewElastiCacheCluster() *ElastiCacheCluster {
+	return &ElastiCacheCluster{}
+}
+
+func NewOpenSearchDomain() *OpenSearchDomain {
+	return &OpenSearchDomain{}
+}
+
+func getDataStoreReportFields(dataStore DataStore) []ReportField {
+	engine := dataStore.Engine
+	if dataStore.EngineVersion != "" {
+		engine = fmt.Sprintf("%s %s", dataStore.Engine, dataStore.EngineVersion)
+	}
+
+	storage := "n/a"
+	if dataStore.StorageGiB > 0 {
+		storage = fmt.Sprintf("%d GiB", dataStore.StorageGiB)
+	}
+
+	multiAZ := "no"
+	if dataStore.MultiAZ {
+		multiAZ = "yes"
+	}
+
+	return []ReportField{
+		{Label: "Engine", Value: engine},
+		{Label: "Class", Value: dataStore.InstanceClass},
+		{Label: "Storage", Value: storage},
+		{Label: "Multi-AZ", Value: multiAZ},
+		{Label: "Status", Value: dataStore.State},
+	}
+}
+
+func (rdsInstance RDSInstance) Resource() CloudResource {
+	return rdsInstance.CloudResource
+}
+
+func (rdsInstance RDSInstance) WithCloudResource(resource CloudResource) ReportableResource {
+	rdsInstance.CloudResource = resource
+	return rdsInstance
+}
+
+func (rdsInstance RDSInstance) Kind() string {
+	return ResourceTypeRDSInstance
+}
+
+func (rdsInstance RDSInstance) ReportFields() []ReportField {
+	return getDataStoreReportFields(rdsInstance.DataStore)
+}
+
+func (rdsCluster RDSCluster) Resource() CloudResource {
+	return rdsCluster.CloudResource
+}
+
+func (rdsCluster RDSCluster) WithCloudResource(resource CloudResource) ReportableResource {
+	rdsCluster.CloudResource = resource
+	return rdsCluster
+}
+
+func (rdsCluster RDSCluster) Kind() string {
+	return ResourceTypeRDSCluster
+}
+
+func (rdsCluster RDSCluster) ReportFields() []ReportField {
+	return append(getDataStoreReportFields(rdsCluster.DataStore),
+		ReportField{Label: "Instances", Value: strconv.Itoa(len(rdsCluster.MemberIDs))})
+}
+
+func (rdsCluster RDSCluster) ClaimedResources() []ReportableResource {
+	var resources []ReportableResource
+
+	for _, rdsInstance := range rdsCluster.Instances {
+		resources = append(resources, rdsInstance)
+	}
+
+	return resources
+}
+
+func (elastiCacheCluster ElastiCacheCluster) Resource() CloudResource {
+	return elastiCacheCluster.CloudResource
+}
+
+func (elastiCacheCluster ElastiCacheCluster) WithCloudResource(resource CloudResource) ReportableResource {
+	elastiCacheCluster.CloudResource = resource
+	return elastiCacheCluster
+}
+
+func (elastiCacheCluster ElastiCacheCluster) Kind() string {
+	return ResourceTypeElastiCacheCluster
+}
+
+func (elastiCacheCluster ElastiCacheCluster) ReportFields() []ReportField {
+	return append(getDataStoreReportFields(elastiCacheCluster.DataStore),
+		ReportField{Label: "Nodes", Value: strconv.Itoa(elastiCacheCluster.NodeCount)})
+}
+
+func (openSearchDomain OpenSearchDomain) Resource() CloudResource {
+	return openSearchDomain.CloudResource
+}
+
+func (openSearchDomain OpenSearchDomain) WithCloudResource(resource CloudResource) ReportableResource {
+	openSearchDomain.CloudResource = resource
+	return openSearchDomain
+}
+
+func (openSearchDomain OpenSearchDomain) Kind() string {
+	return ResourceTypeOpenSearchDomain
+}
+
+func (openSearchDomain OpenSearchDomain) ReportFields() []ReportField {
+	return append(getDataStoreReportFields(openSearchDomain.DataStore),
+		ReportField{Label: "Instances", Value: strconv.FormatInt(openSearchDomain.InstanceCount, 10)})
+}
+
+func (rdsCluster *RDSCluster) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
+	switch resource.(type) {
+	case RDSInstance:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		rdsInstance := resource.(RDSInstance)
+		rdsCluster.Instances[rdsInstance.ID] = rdsInstance
+		return claimed()
+	}
+
+	return cannotClaim(resource)
+}
+
+func init() {
+	RegisterStackResourceType(cloudformationRDSInstanceStackResourceId, ResourceTypeRDSInstance)
+	RegisterStackResourceType(cloudformationRDSClusterStackResourceId, ResourceTypeRDSCluster)
+	RegisterStackResourceType(cloudformationCacheClusterStackResourceId, ResourceTypeElastiCacheCluster)
+	RegisterStackResourceType(cloudformationReplicationGroupStackResourceId, ResourceTypeElastiCacheCluster)
+	RegisterStackResourceType(cloudformationElasticsearchDomainStackResourceId, ResourceTypeOpenSearchDomain)
+	RegisterStackResourceType(cloudformationOpenSearchDomainStackResourceId, ResourceTypeOpenSearchDomain)
+
+	RegisterResourceNodeType(ResourceTypeRDSInstance, NodeRDSInstance)
+	RegisterResourceNodeType(ResourceTypeRDSCluster, NodeRDSCluster)
+	RegisterResourceNodeType(ResourceTypeElastiCacheCluster, NodeElastiCacheCluster)
+	RegisterResourceNodeType(ResourceTypeOpenSearchDomain, NodeOpenSearchDomain)
+
+	RegisterResourcePricer(ResourceTypeRDSInstance, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return catalog.GetRDSInstanceCost(resource.(RDSInstance))
+	})
+	// Clusters are billed through their instances, which are claimed under them
+	RegisterResourcePricer(ResourceTypeRDSCluster, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return NewHourlyCost(0)
+	})
+	RegisterResourcePricer(ResourceTypeElastiCacheCluster, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return catalog.GetElastiCacheClusterCost(resource.(ElastiCacheCluster))
+	})
+	RegisterResourcePricer(ResourceTypeOpenSearchDomain, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return catalog.GetOpenSearchDomainCost(resource.(OpenSearchDomain))
+	})
+}
+
+func (ctx *RegionalCloudContext) GetRDSInstances() []RDSInstance {
+	var rdsInstances []RDSInstance
+
+	for _, resource := range ctx.Resources[ResourceTypeRDSInstance] {
+		rdsInstances = append(rdsInstances, resource.(RDSInstance))
+	}
+
+	return rdsInstances
+}
+
+func (ctx *RegionalCloudContext) GetRDSClusters() []RDSCluster {
+	var rdsClusters []RDSCluster
+
+	for _, resource := range ctx.Resources[ResourceTypeRDSCluster] {
+		rdsClusters = append(rdsClusters, resource.(RDSCluster))
+	}
+
+	return rdsClusters
+}
+
+func getRDSClusterClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+
+	for _, rdsCluster := range ctx.GetRDSClusters() {
+		claimer := rdsCluster
+
+		for _, memberId := range rdsCluster.MemberIDs {
+			if resource, ok := ctx.Resources[ResourceTypeRDSInstance][memberId]; ok {
+				candidates = append(candidates, ClaimCandidate{
+					Claimer:  &claimer,
+					Resource: resource,
+					Reason:   "listed in the cluster members",
+				})
+			}
+		}
+	}
+
+	return candidates
+}
+
+// addDataStoreResources adds the data stores in the region to the graph, along with the RDS clusters and
+// Cloudformation stacks that own them
+func addDataStoreResources(graph *OwnershipGraph, ctx *RegionalCloudContext) {
+	for _, rdsInstance := range ctx.GetRDSInstances() {
+		graph.AddNode(NodeRDSInstance, rdsInstance.CloudResource)
+	}
+
+	for _, rdsCluster := range ctx.GetRDSClusters() {
+		clusterNodeId := graph.AddNode(NodeRDSCluster, rdsCluster.CloudResource)
+
+		for _, memberId := range rdsCluster.MemberIDs {
+			if rdsInstance, ok := ctx.Resources[ResourceTypeRDSInstance][memberId]; ok {
+				graph.AddEdge(GetNodeId(NodeRDSInstance, rdsInstance.Resource()), clusterNodeId, EdgeMemberOfRDSCluster)
+			}
+		}
+	}
+
+	for _, resource := range ctx.Resources[ResourceTypeElastiCacheCluster] {
+		graph.AddNode(NodeElastiCacheCluster, resource.Resource())
+	}
+
+	for _, resource := range ctx.Resources[ResourceTypeOpenSearchDomain] {
+		graph.AddNode(NodeOpenSearchDomain, resource.Resource())
+	}
+
+	addCloudformationStackEdges(graph, ctx, map[string]NodeType{
+		cloudformationRDSInstanceStackResourceId:         NodeRDSInstance,
+		cloudformationRDSClusterStackResourceId:          NodeRDSCluster,
+		cloudformationCacheClusterStackResourceId:        NodeElastiCacheCluster,
+		cloudformationReplicationGroupStackResourceId:    NodeElastiCacheCluster,
+		cloudformationElasticsearchDomainStackResourceId: NodeOpenSearchDomain,
+		cloudformationOpenSearchDomainStackResourceId:    NodeOpenSearchDomain,
+	})
+}
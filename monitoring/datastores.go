@@ -0,0 +1,394 @@
+package monitoring
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/elasticsearchservice"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+// Aurora storage grows with the data, the allocated storage the API reports for it is meaningless
+const auroraEnginePrefix = "aurora"
+
+// Domains created as OpenSearch report their version with this prefix, the rest are Elasticsearch
+const openSearchVersionPrefix = "OpenSearch_"
+
+// DescribeElasticsearchDomains accepts at most this many domain names per call
+const openSearchDescribeBatchSize = 5
+
+type RDSInstanceCollector struct{}
+
+func (collector *RDSInstanceCollector) Name() string {
+	return "RDS instances"
+}
+
+func (collector *RDSInstanceCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeRDSInstance}
+}
+
+func (collector *RDSInstanceCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	rdsService := rds.New(scope.Session, getAWSConfig(scope.Credentials, scope.Region))
+
+	rdsInstances, err := getRDSInstances(rdsService, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for _, rdsInstance := range rdsInstances {
+		ctx.AddResource(rdsInstance)
+	}
+
+	return nil
+}
+
+type RDSClusterCollector struct{}
+
+func (collector *RDSClusterCollector) Name() string {
+	return "RDS clusters"
+}
+
+func (collector *RDSClusterCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeRDSCluster}
+}
+
+func (collector *RDSClusterCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	rdsService := rds.New(scope.Session, getAWSConfig(scope.Credentials, scope.Region))
+
+	rdsClusters, err := getRDSClusters(rdsService, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for _, rdsCluster := range rdsClusters {
+		ctx.AddResource(rdsCluster)
+	}
+
+	return nil
+}
+
+type ElastiCacheClusterCollector struct{}
+
+func (collector *ElastiCacheClusterCollector) Name() string {
+	return "ElastiCache clusters"
+}
+
+func (collector *ElastiCacheClusterCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeElastiCacheCluster}
+}
+
+func (collector *ElastiCacheClusterCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	elastiCacheService := elasticache.New(scope.Session, getAWSConfig(scope.Credentials, scope.Region))
+
+	elastiCacheClusters, err := getElastiCacheClusters(elastiCacheService, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for _, elastiCacheCluster := range elastiCacheClusters {
+		ctx.AddResource(elastiCacheCluster)
+	}
+
+	return nil
+}
+
+type OpenSearchDomainCollector struct{}
+
+func (collector *OpenSearchDomainCollector) Name() string {
+	return "OpenSearch domains"
+}
+
+func (collector *OpenSearchDomainCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeOpenSearchDomain}
+}
+
+func (collector *OpenSearchDomainCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	openSearchService := elasticsearchservice.New(scope.Session, getAWSConfig(scope.Credentials, scope.Region))
+
+	// The domains described before a failed batch are still reported
+	openSearchDomains, err := getOpenSearchDomains(openSearchService, scope.Account, scope.Region)
+	for _, openSearchDomain := range openSearchDomains {
+		ctx.AddResource(openSearchDomain)
+	}
+
+	return err
+}
+
+func getRDSInstances(rdsService *rds.RDS, account string, region string) ([]RDSInstance, error) {
+	var rdsInstances []RDSInstance
+
+	input := &rds.DescribeDBInstancesInput{
+		MaxRecords: aws.Int64(100),
+	}
+
+	err := rdsService.DescribeDBInstancesPages(input, func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+		for _, dbInstance := range page.DBInstances {
+			rdsInstance := NewRDSInstance()
+			rdsInstance.ID = aws.StringValue(dbInstance.DBInstanceIdentifier)
+			rdsInstance.Name = rdsInstance.ID
+			rdsInstance.Provider = ProviderAWS
+			rdsInstance.Account = account
+			rdsInstance.Region = region
+			rdsInstance.ARN = aws.StringValue(dbInstance.DBInstanceArn)
+			rdsInstance.Engine = aws.StringValue(dbInstance.Engine)
+			rdsInstance.EngineVersion = aws.StringValue(dbInstance.EngineVersion)
+			rdsInstance.InstanceClass = aws.StringValue(dbInstance.DBInstanceClass)
+			rdsInstance.StorageType = aws.StringValue(dbInstance.StorageType)
+			rdsInstance.MultiAZ = aws.BoolValue(dbInstance.MultiAZ)
+			rdsInstance.State = aws.StringValue(dbInstance.DBInstanceStatus)
+			rdsInstance.ClusterID = aws.StringValue(dbInstance.DBClusterIdentifier)
+
+			if !strings.HasPrefix(rdsInstance.Engine, auroraEnginePrefix) {
+				rdsInstance.StorageGiB = aws.Int64Value(dbInstance.AllocatedStorage)
+			}
+
+			if dbInstance.InstanceCreateTime != nil {
+				rdsInstance.CreatedAt = *dbInstance.InstanceCreateTime
+			}
+
+			rdsInstance.Tags = getRDSTags(dbInstance.TagList)
+			rdsInstance.CloudResource = setLaunchedBy(rdsInstance.CloudResource, "")
+
+			rdsInstances = append(rdsInstances, *rdsInstance)
+		}
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RDS instances %w", err)
+	}
+
+	log.Printf("Found %d RDS instances in account %s region %s", len(rdsInstances), account, region)
+	return rdsInstances, nil
+}
+
+func getRDSClusters(rdsService *rds.RDS, account string, region string) ([]RDSCluster, error) {
+	var rdsClusters []RDSCluster
+
+	input := &rds.DescribeDBClustersInput{
+		MaxRecords: aws.Int64(100),
+	}
+
+	err := rdsService.DescribeDBClustersPages(input, func(page *rds.DescribeDBClustersOutput, lastPage bool) bool {
+		for _, dbCluster := range page.DBClusters {
+			rdsCluster := NewRDSCluster()
+			rdsCluster.ID = aws.StringValue(dbCluster.DBClusterIdentifier)
+			rdsCluster.Name = rdsCluster.ID
+			rdsCluster.Provider = ProviderAWS
+			rdsCluster.Account = account
+			rdsCluster.Region = region
+			rdsCluster.ARN = aws.StringValue(dbCluster.DBClusterArn)
+			rdsCluster.Engine = aws.StringValue(dbCluster.Engine)
+			rdsCluster.EngineVersion = aws.StringValue(dbCluster.EngineVersion)
+			rdsCluster.EngineMode = aws.StringValue(dbCluster.EngineMode)
+			rdsCluster.MultiAZ = aws.BoolValue(dbCluster.MultiAZ)
+			rdsCluster.State = aws.StringValue(dbCluster.Status)
+
+			if !strings.HasPrefix(rdsCluster.Engine, auroraEnginePrefix) {
+				rdsCluster.StorageGiB = aws.Int64Value(dbCluster.AllocatedStorage)
+			}
+
+			if dbCluster.ClusterCreateTime != nil {
+				rdsCluster.CreatedAt = *dbCluster.ClusterCreateTime
+			}
+
+			for _, member := range dbCluster.DBClusterMembers {
+				rdsCluster.MemberIDs = append(rdsCluster.MemberIDs, aws.StringValue(member.DBInstanceIdentifier))
+			}
+
+			rdsCluster.Tags = getRDSTags(dbCluster.TagList)
+			rdsCluster.CloudResource = setLaunchedBy(rdsCluster.CloudResource, "")
+
+			rdsClusters = append(rdsClusters, *rdsCluster)
+		}
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RDS clusters %w", err)
+	}
+
+	log.Printf("Found %d RDS clusters in account %s region %s", len(rdsClusters), account, region)
+	return rdsClusters, nil
+}
+
+// getElastiCacheClusters reports each replication group as one cluster made of its member cache clusters, and each
+// cache cluster outside a replication group on its own
+func getElastiCacheClusters(elastiCacheService *elasticache.ElastiCache, account string, region string) ([]ElastiCacheCluster, error) {
+	var elastiCacheClusters []ElastiCacheCluster
+	cacheClustersByReplicationGroupId := map[string][]*elasticache.CacheCluster{}
+
+	err := elastiCacheService.DescribeCacheClustersPages(&elasticache.DescribeCacheClustersInput{}, func(page *elasticache.DescribeCacheClustersOutput, lastPage bool) bool {
+		for _, cacheCluster := range page.CacheClusters {
+			if replicationGroupId := aws.StringValue(cacheCluster.ReplicationGroupId); replicationGroupId != "" {
+				cacheClustersByReplicationGroupId[replicationGroupId] = append(cacheClustersByReplicationGroupId[replicationGroupId], cacheCluster)
+				continue
+			}
+
+			elastiCacheCluster := NewElastiCacheCluster()
+			elastiCacheCluster.ID = aws.StringValue(cacheCluster.CacheClusterId)
+			elastiCacheCluster.ARN = aws.StringValue(cacheCluster.ARN)
+			elastiCacheCluster.InstanceClass = aws.StringValue(cacheCluster.CacheNodeType)
+			elastiCacheCluster.State = aws.StringValue(cacheCluster.CacheClusterStatus)
+			elastiCacheCluster.NodeCount = int(aws.Int64Value(cacheCluster.NumCacheNodes))
+			elastiCacheCluster.MemberIDs = []string{elastiCacheCluster.ID}
+			setElastiCacheEngine(elastiCacheCluster, cacheCluster)
+
+			elastiCacheClusters = append(elastiCacheClusters, *elastiCacheCluster)
+		}
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ElastiCache cache clusters %w", err)
+	}
+
+	err = elastiCacheService.DescribeReplicationGroupsPages(&elasticache.DescribeReplicationGroupsInput{}, func(page *elasticache.DescribeReplicationGroupsOutput, lastPage bool) bool {
+		for _, replicationGroup := range page.ReplicationGroups {
+			elastiCacheCluster := NewElastiCacheCluster()
+			elastiCacheCluster.ID = aws.StringValue(replicationGroup.ReplicationGroupId)
+			elastiCacheCluster.ARN = aws.StringValue(replicationGroup.ARN)
+			elastiCacheCluster.InstanceClass = aws.StringValue(replicationGroup.CacheNodeType)
+			elastiCacheCluster.State = aws.StringValue(replicationGroup.Status)
+			elastiCacheCluster.MultiAZ = aws.StringValue(replicationGroup.MultiAZ) == elasticache.MultiAZStatusEnabled
+			elastiCacheCluster.MemberIDs = aws.StringValueSlice(replicationGroup.MemberClusters)
+
+			// Replication groups have no engine or creation time of their own, they come from the member clusters
+			for _, cacheCluster := range cacheClustersByReplicationGroupId[elastiCacheCluster.ID] {
+				elastiCacheCluster.NodeCount += int(aws.Int64Value(cacheCluster.NumCacheNodes))
+				setElastiCacheEngine(elastiCacheCluster, cacheCluster)
+			}
+
+			elastiCacheClusters = append(elastiCacheClusters, *elastiCacheCluster)
+		}
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ElastiCache replication groups %w", err)
+	}
+
+	for idx := range elastiCacheClusters {
+		elastiCacheCluster := &elastiCacheClusters[idx]
+		elastiCacheCluster.Name = elastiCacheCluster.ID
+		elastiCacheCluster.Provider = ProviderAWS
+		elastiCacheCluster.Account = account
+		elastiCacheCluster.Region = region
+
+		if elastiCacheCluster.ARN != "" {
+			tags, err := elastiCacheService.ListTagsForResource(&elasticache.ListTagsForResourceInput{ResourceName: aws.String(elastiCacheCluster.ARN)})
+			if err != nil {
+				log.Printf("Unable to get tags for ElastiCache cluster %s in %s: %s", elastiCacheCluster.ID, region, err)
+			} else {
+				elastiCacheCluster.Tags = getElastiCacheTags(tags.TagList)
+			}
+		}
+
+		elastiCacheCluster.CloudResource = setLaunchedBy(elastiCacheCluster.CloudResource, "")
+	}
+
+	log.Printf("Found %d ElastiCache clusters in account %s region %s", len(elastiCacheClusters), account, region)
+	return elastiCacheClusters, nil
+}
+
+// setElastiCacheEngine takes the engine from a cache cluster, keeping the earliest creation time seen
+func setElastiCacheEngine(elastiCacheCluster *ElastiCacheCluster, cacheCluster *elasticache.CacheCluster) {
+	elastiCacheCluster.Engine = aws.StringValue(cacheCluster.Engine)
+	elastiCacheCluster.EngineVersion = aws.StringValue(cacheCluster.EngineVersion)
+
+	if cacheCluster.CacheClusterCreateTime != nil && (elastiCacheCluster.CreatedAt.IsZero() || cacheCluster.CacheClusterCreateTime.Before(elastiCacheCluster.CreatedAt)) {
+		elastiCacheCluster.CreatedAt = *cacheCluster.CacheClusterCreateTime
+	}
+}
+
+// getOpenSearchDomains carries on past a batch that fails to describe, returning the domains of the other batches along
+// with the error
+func getOpenSearchDomains(openSearchService *elasticsearchservice.ElasticsearchService, account string, region string) ([]OpenSearchDomain, error) {
+	var openSearchDomains []OpenSearchDomain
+	var describeErr error
+
+	domainNames, err := openSearchService.ListDomainNames(&elasticsearchservice.ListDomainNamesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OpenSearch domains %w", err)
+	}
+
+	var names []*string
+	for _, domainInfo := range domainNames.DomainNames {
+		names = append(names, domainInfo.DomainName)
+	}
+
+	for start := 0; start < len(names); start += openSearchDescribeBatchSize {
+		end := start + openSearchDescribeBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+
+		output, err := openSearchService.DescribeElasticsearchDomains(&elasticsearchservice.DescribeElasticsearchDomainsInput{DomainNames: names[start:end]})
+		if err != nil {
+			log.Printf("Unable to describe OpenSearch domains in %s: %s", region, err)
+			describeErr = fmt.Errorf("failed to describe OpenSearch domains %w", err)
+			continue
+		}
+
+		for _, domainStatus := range output.DomainStatusList {
+			openSearchDomain := newOpenSearchDomain(domainStatus)
+			openSearchDomain.Account = account
+			openSearchDomain.Region = region
+
+			tags, err := openSearchService.ListTags(&elasticsearchservice.ListTagsInput{ARN: domainStatus.ARN})
+			if err != nil {
+				log.Printf("Unable to get tags for OpenSearch domain %s in %s: %s", openSearchDomain.ID, region, err)
+			} else {
+				openSearchDomain.Tags = getOpenSearchTags(tags.TagList)
+			}
+
+			openSearchDomain.CloudResource = setLaunchedBy(openSearchDomain.CloudResource, "")
+			openSearchDomains = append(openSearchDomains, *openSearchDomain)
+		}
+	}
+
+	log.Printf("Found %d OpenSearch domains in account %s region %s", len(openSearchDomains), account, region)
+	return openSearchDomains, describeErr
+}
+
+func newOpenSearchDomain(domainStatus *elasticsearchservice.ElasticsearchDomainStatus) *OpenSearchDomain {
+	openSearchDomain := NewOpenSearchDomain()
+	openSearchDomain.ID = aws.StringValue(domainStatus.DomainName)
+	openSearchDomain.Name = openSearchDomain.ID
+	openSearchDomain.Provider = ProviderAWS
+	openSearchDomain.ARN = aws.StringValue(domainStatus.ARN)
+
+	version := aws.StringValue(domainStatus.ElasticsearchVersion)
+	if strings.HasPrefix(version, openSearchVersionPrefix) {
+		openSearchDomain.Engine = "opensearch"
+		openSearchDomain.EngineVersion = strings.TrimPrefix(version, openSearchVersionPrefix)
+	} else {
+		openSearchDomain.Engine = "elasticsearch"
+		openSearchDomain.EngineVersion = version
+	}
+
+	if clusterConfig := domainStatus.ElasticsearchClusterConfig; clusterConfig != nil {
+		openSearchDomain.InstanceClass = aws.StringValue(clusterConfig.InstanceType)
+		openSearchDomain.InstanceCount = aws.Int64Value(clusterConfig.InstanceCount)
+		openSearchDomain.MultiAZ = aws.BoolValue(clusterConfig.ZoneAwarenessEnabled)
+	}
+
+	// The EBS volume size is per data instance
+	if ebsOptions := domainStatus.EBSOptions; ebsOptions != nil && aws.BoolValue(ebsOptions.EBSEnabled) {
+		openSearchDomain.StorageGiB = aws.Int64Value(ebsOptions.VolumeSize) * openSearchDomain.InstanceCount
+	}
+
+	switch {
+	case aws.BoolValue(domainStatus.Deleted):
+		openSearchDomain.State = "deleting"
+	case aws.BoolValue(domainStatus.Processing):
+		openSearchDomain.State = "processing"
+	default:
+		openSearchDomain.State = "active"
+	}
+
+	return openSearchDomain
+}
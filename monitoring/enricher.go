@@ -0,0 +1,44 @@
+package monitoring
+
+import "fmt"
+
+// Enricher adds information to resources that have already been collected, before any claims are processed
+type Enricher interface {
+	Name() string
+	Enrich(scope *ScanScope, ctx *RegionalCloudContext) error
+}
+
+var enricherRegistry []Enricher
+
+func RegisterEnricher(enricher Enricher) {
+	enricherRegistry = append(enricherRegistry, enricher)
+}
+
+func RegisteredEnrichers() []Enricher {
+	registered := make([]Enricher, len(enricherRegistry))
+	copy(registered, enricherRegistry)
+	return registered
+}
+
+func init() {
+	RegisterEnricher(&LaunchedByEnricher{})
+	RegisterEnricher(&DetachedVolumeEnricher{})
+}
+
+func enrichRegion(scope *ScanScope, ctx *RegionalCloudContext, enrichers []Enricher) []ScanError {
+	var scanErrors []ScanError
+
+	for _, enricher := range enrichers {
+		if err := enricher.Enrich(scope, ctx); err != nil {
+			scanErrors = append(scanErrors, ScanError{
+				Account:   scope.Account,
+				Region:    scope.Region,
+				Kind:      ScanErrorEnrich,
+				Operation: fmt.Sprintf("add %s", enricher.Name()),
+				Message:   err.Error(),
+			})
+		}
+	}
+
+	return scanErrors
+}
@@ -0,0 +1,217 @@
+package monitoring
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const expiryWarningWindowEnv = "EXPIRY_WARNING_WINDOW"
+const defaultExpiryWarningWindow = 72 * time.Hour
+
+const ExpiresOnTag = "expires-on"
+const TTLTag = "ttl"
+
+const (
+	ExpiryValid        = "valid"
+	ExpiryExpiringSoon = "expiring soon"
+	ExpiryExpired      = "expired"
+	ExpiryMissingTTL   = "missing TTL"
+)
+
+type Expiry struct {
+	Status    string
+	ExpiresAt time.Time
+	Source    string
+}
+
+// ExpiredResource is recorded before claims are processed, so expired resources are found wherever they end up in the report
+type ExpiredResource struct {
+	Reference ResourceReference
+	Resource  CloudResource
+}
+
+type ExpiryPolicy struct {
+	WarningWindow time.Duration
+}
+
+func getExpiryPolicy() *ExpiryPolicy {
+	warningWindow := defaultExpiryWarningWindow
+
+	if value := os.Getenv(expiryWarningWindowEnv); value != "" {
+		parsed, err := parseTTL(value)
+		if err != nil {
+			log.Printf("Invalid %s %q, using %s", expiryWarningWindowEnv, value, defaultExpiryWarningWindow)
+		} else {
+			warningWindow = parsed
+		}
+	}
+
+	return &ExpiryPolicy{WarningWindow: warningWindow}
+}
+
+// Evaluate prefers an explicit expires-on date over a ttl counted from when the resource was created
+func (policy *ExpiryPolicy) Evaluate(resource CloudResource, now time.Time) Expiry {
+	var expiresAt time.Time
+	var source string
+
+	if value, ok := resource.Tags[ExpiresOnTag]; ok {
+		parsed, err := parseExpiresOn(value)
+		if err != nil {
+			log.Printf("Ignoring %s tag %q on %s: %s", ExpiresOnTag, value, resource.ID, err)
+		} else {
+			expiresAt = parsed
+			source = fmt.Sprintf("tag %s", ExpiresOnTag)
+		}
+	}
+
+	if expiresAt.IsZero() && !resource.CreatedAt.IsZero() {
+		if value, ok := resource.Tags[TTLTag]; ok {
+			ttl, err := parseTTL(value)
+			if err != nil {
+				log.Printf("Ignoring %s tag %q on %s: %s", TTLTag, value, resource.ID, err)
+			} else {
+				expiresAt = resource.CreatedAt.Add(ttl)
+				source = fmt.Sprintf("tag %s", TTLTag)
+			}
+		}
+	}
+
+	if expiresAt.IsZero() {
+		return Expiry{Status: ExpiryMissingTTL}
+	}
+
+	expiry := Expiry{Status: ExpiryValid, ExpiresAt: expiresAt, Source: source}
+
+	if !now.Before(expiresAt) {
+		expiry.Status = ExpiryExpired
+	} else if expiresAt.Sub(now) <= policy.WarningWindow {
+		expiry.Status = ExpiryExpiringSoon
+	}
+
+	return expiry
+}
+
+func parseExpiresOn(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if expiresAt, err := time.Parse(time.RFC3339, value); err == nil {
+		return expiresAt, nil
+	}
+
+	return time.Parse("2006-01-02", value)
+}
+
+// parseTTL accepts Go durations such as 72h as well as whole days such as 7d
+func parseTTL(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", value)
+		}
+
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return ttl, nil
+}
+
+type ExpiryEnricher struct {
+	Policy *ExpiryPolicy
+}
+
+func NewExpiryEnricher(policy *ExpiryPolicy) *ExpiryEnricher {
+	return &ExpiryEnricher{Policy: policy}
+}
+
+func (enricher *ExpiryEnricher) Name() string {
+	return "expiry policy"
+}
+
+func (enricher *ExpiryEnricher) Enrich(scope *ScanScope, ctx *RegionalCloudContext) error {
+	now := time.Now()
+
+	evaluate := func(nodeType NodeType, id string, resource CloudResource) Expiry {
+		expiry := enricher.Policy.Evaluate(resource, now)
+		ctx.ExpiryCounts[expiry.Status]++
+
+		if expiry.Status == ExpiryExpired {
+			resource.Expiry = expiry
+			ctx.ExpiredResources = append(ctx.ExpiredResources, ExpiredResource{
+				Reference: ResourceReference{Account: ctx.Account, Region: ctx.Region, Type: nodeType, ID: id},
+				Resource:  resource,
+			})
+		}
+
+		return expiry
+	}
+
+	for id, ebsVolume := range ctx.EBSVolumes {
+		ebsVolume.Expiry = evaluate(NodeEBSVolume, ebsVolume.ID, ebsVolume.CloudResource)
+		ctx.EBSVolumes[id] = ebsVolume
+	}
+
+	for id, ec2Instance := range ctx.EC2Instances {
+		ec2Instance.Expiry = evaluate(NodeEC2Instance, ec2Instance.ID, ec2Instance.CloudResource)
+		ctx.EC2Instances[id] = ec2Instance
+	}
+
+	for name, eksCluster := range ctx.EKSClusters {
+		eksCluster.Expiry = evaluate(NodeEKSCluster, eksCluster.Name, eksCluster.CloudResource)
+		ctx.EKSClusters[name] = eksCluster
+	}
+
+	for id, cloudformationStack := range ctx.CloudFormationStacks {
+		cloudformationStack.Expiry = evaluate(NodeCloudformationStack, cloudformationStack.ID, cloudformationStack.CloudResource)
+		ctx.CloudFormationStacks[id] = cloudformationStack
+	}
+
+	ctx.updateCloudResources(func(resource ReportableResource) CloudResource {
+		cloudResource := resource.Resource()
+
+		if nodeType, ok := GetResourceNodeType(resource.Kind()); ok {
+			cloudResource.Expiry = evaluate(nodeType, cloudResource.ID, cloudResource)
+		}
+
+		return cloudResource
+	})
+
+	return nil
+}
+
+func (ctx *GlobalCloudContext) GetExpiredResources() []ExpiredResource {
+	var expiredResources []ExpiredResource
+
+	for _, regionalCtx := range ctx.RegionalCloudContexts {
+		expiredResources = append(expiredResources, regionalCtx.ExpiredResources...)
+	}
+
+	sort.SliceStable(expiredResources, func(i, j int) bool {
+		return expiredResources[i].Resource.Expiry.ExpiresAt.Before(expiredResources[j].Resource.Expiry.ExpiresAt)
+	})
+
+	return expiredResources
+}
+
+func (ctx *GlobalCloudContext) GetExpiryCounts() map[string]int {
+	counts := map[string]int{}
+
+	for _, regionalCtx := range ctx.RegionalCloudContexts {
+		for status, count := range regionalCtx.ExpiryCounts {
+			counts[status] += count
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,815 @@
+package monitoring
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const gcpProjectIdsEnv = "GCP_PROJECT_IDS"
+const gcpCredentialsPathEnv = "GOOGLE_APPLICATION_CREDENTIALS"
+const gcpApiEndpointEnv = "GCP_API_ENDPOINT"
+
+const (
+	defaultGCPComputeEndpoint           = "https://compute.googleapis.com"
+	defaultGCPContainerEndpoint         = "https://container.googleapis.com"
+	defaultGCPDeploymentManagerEndpoint = "https://deploymentmanager.googleapis.com"
+)
+
+const gcpReadOnlyScope = "https://www.googleapis.com/auth/cloud-platform.read-only"
+const gcpJWTBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+// Deployment Manager deployments are global, they are reported here unless their resources place them in a region
+const GCPGlobalRegion = "global"
+
+// GKE adds these to the Compute Engine instances backing its node pools
+const gkeClusterNameLabel = "goog-k8s-cluster-name"
+const gkeClusterNameMetadataKey = "cluster-name"
+const gkeClusterLocationLabel = "goog-k8s-cluster-location"
+const gkeClusterLocationMetadataKey = "cluster-location"
+
+// GCPClient lists resources from the Compute Engine, GKE and Deployment Manager REST APIs. Setting GCP_API_ENDPOINT
+// sends all three to a single local server, and the token URI comes from the service account key.
+type GCPClient struct {
+	ComputeEndpoint           string
+	ContainerEndpoint         string
+	DeploymentManagerEndpoint string
+	HTTPClient                *http.Client
+	credentials               *gcpServiceAccountKey
+	privateKey                *rsa.PrivateKey
+	token                     string
+	tokenExpiry               time.Time
+}
+
+// GCPInventory holds everything listed in one project, collectors pick out the resources of their region
+type GCPInventory struct {
+	Instances   []GCPInstance
+	Disks       []GCPPersistentDisk
+	GKEClusters []GKECluster
+	Deployments []DeploymentManagerDeployment
+}
+
+type gcpServiceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+type gcpTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type gcpInstance struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Zone              string            `json:"zone"`
+	MachineType       string            `json:"machineType"`
+	Status            string            `json:"status"`
+	SelfLink          string            `json:"selfLink"`
+	CreationTimestamp string            `json:"creationTimestamp"`
+	Labels            map[string]string `json:"labels"`
+	Disks             []struct {
+		Source string `json:"source"`
+	} `json:"disks"`
+	NetworkInterfaces []struct {
+		Subnetwork string `json:"subnetwork"`
+	} `json:"networkInterfaces"`
+	Metadata struct {
+		Items []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"items"`
+	} `json:"metadata"`
+}
+
+type gcpDisk struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Zone              string            `json:"zone"`
+	Region            string            `json:"region"`
+	SizeGb            string            `json:"sizeGb"`
+	Type              string            `json:"type"`
+	Status            string            `json:"status"`
+	SelfLink          string            `json:"selfLink"`
+	CreationTimestamp string            `json:"creationTimestamp"`
+	Labels            map[string]string `json:"labels"`
+	Users             []string          `json:"users"`
+}
+
+type gcpAggregatedInstances struct {
+	Items map[string]struct {
+		Instances []gcpInstance `json:"instances"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+type gcpAggregatedDisks struct {
+	Items map[string]struct {
+		Disks []gcpDisk `json:"disks"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+type gkeClusterList struct {
+	Clusters []struct {
+		Name                 string            `json:"name"`
+		Location             string            `json:"location"`
+		Status               string            `json:"status"`
+		CurrentMasterVersion string            `json:"currentMasterVersion"`
+		CurrentNodeCount     int               `json:"currentNodeCount"`
+		Network              string            `json:"network"`
+		SelfLink             string            `json:"selfLink"`
+		CreateTime           string            `json:"createTime"`
+		ResourceLabels       map[string]string `json:"resourceLabels"`
+	} `json:"clusters"`
+}
+
+type gcpDeploymentList struct {
+	Deployments []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		InsertTime string `json:"insertTime"`
+		Labels     []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"labels"`
+	} `json:"deployments"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+type gcpDeploymentResourceList struct {
+	Resources []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"resources"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+func NewGCPClient() (*GCPClient, error) {
+	client := &GCPClient{
+		ComputeEndpoint:           defaultGCPComputeEndpoint,
+		ContainerEndpoint:         defaultGCPContainerEndpoint,
+		DeploymentManagerEndpoint: defaultGCPDeploymentManagerEndpoint,
+		HTTPClient:                &http.Client{Timeout: time.Minute},
+	}
+
+	if endpoint := strings.TrimSuffix(os.Getenv(gcpApiEndpointEnv), "/"); endpoint != "" {
+		client.ComputeEndpoint = endpoint
+		client.ContainerEndpoint = endpoint
+		client.DeploymentManagerEndpoint = endpoint
+	}
+
+	credentialsPath := os.Getenv(gcpCredentialsPathEnv)
+	if credentialsPath == "" {
+		return nil, fmt.Errorf("unable to scan GCP, %s environment variable not found", gcpCredentialsPathEnv)
+	}
+
+	credentialsJson, err := ioutil.ReadFile(credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read GCP service account key %s: %s", credentialsPath, err)
+	}
+
+	client.credentials = &gcpServiceAccountKey{}
+	if err := json.Unmarshal(credentialsJson, client.credentials); err != nil {
+		return nil, fmt.Errorf("unable to read GCP service account key %s: %s", credentialsPath, err)
+	}
+
+	client.privateKey, err = parseGCPPrivateKey(client.credentials.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read GCP service account key %s: %s", credentialsPath, err)
+	}
+
+	return client, nil
+}
+
+func parseGCPPrivateKey(privateKeyPem string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKeyPem))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM private key found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+func getGCPProjectIds() []string {
+	var projectIds []string
+
+	for _, projectId := range split(os.Getenv(gcpProjectIdsEnv)) {
+		if projectId = strings.TrimSpace(projectId); projectId != "" {
+			projectIds = append(projectIds, projectId)
+		}
+	}
+
+	return projectIds
+}
+
+func GCPConfigured() bool {
+	return len(getGCPProjectIds()) > 0
+}
+
+// getAssertion signs the JWT a service account exchanges for an access token
+func (client *GCPClient) getAssertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   client.credentials.ClientEmail,
+		"scope": gcpReadOnlyScope,
+		"aud":   client.credentials.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := fmt.Sprintf("%s.%s", base64.RawURLEncoding.EncodeToString(header), base64.RawURLEncoding.EncodeToString(claims))
+	digest := sha256.Sum256([]byte(unsigned))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, client.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s.%s", unsigned, base64.RawURLEncoding.EncodeToString(signature)), nil
+}
+
+func (client *GCPClient) getToken() (string, error) {
+	if client.token != "" && time.Now().Before(client.tokenExpiry) {
+		return client.token, nil
+	}
+
+	assertion, err := client.getAssertion(time.Now())
+	if err != nil {
+		return "", fmt.Errorf("unable to sign GCP token request: %s", err)
+	}
+
+	response, err := client.HTTPClient.PostForm(client.credentials.TokenURI, url.Values{
+		"grant_type": {gcpJWTBearerGrantType},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to get GCP access token: %s", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		return "", fmt.Errorf("unable to get GCP access token: %s %s", response.Status, strings.TrimSpace(string(body)))
+	}
+
+	var tokenResponse gcpTokenResponse
+	if err := json.NewDecoder(response.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("unable to read GCP access token: %s", err)
+	}
+
+	// Renew a minute early so the token does not expire part way through a listing
+	client.token = tokenResponse.AccessToken
+	client.tokenExpiry = time.Now().Add(time.Duration(tokenResponse.ExpiresIn)*time.Second - time.Minute)
+
+	return client.token, nil
+}
+
+func (client *GCPClient) get(endpoint string, path string, pageToken string, out interface{}) error {
+	token, err := client.getToken()
+	if err != nil {
+		return err
+	}
+
+	requestUrl := fmt.Sprintf("%s%s", endpoint, path)
+	if pageToken != "" {
+		requestUrl = fmt.Sprintf("%s?pageToken=%s", requestUrl, url.QueryEscape(pageToken))
+	}
+
+	if err := getJSON(client.HTTPClient, requestUrl, token, out); err != nil {
+		return fmt.Errorf("unable to get %s: %s", path, err)
+	}
+
+	return nil
+}
+
+func newGCPCloudResource(id string, name string, region string, labels map[string]string, createdAt string, projectId string) CloudResource {
+	cloudResource := CloudResource{
+		ID:       id,
+		Name:     name,
+		Provider: ProviderGCP,
+		Region:   region,
+		Tags:     labels,
+		Account:  projectId,
+	}
+
+	if cloudResource.Tags == nil {
+		cloudResource.Tags = map[string]string{}
+	}
+
+	if createdAt != "" {
+		if created, err := time.Parse(time.RFC3339, createdAt); err == nil {
+			cloudResource.CreatedAt = created
+		}
+	}
+
+	return setLaunchedBy(cloudResource, "")
+}
+
+// getGCPResourceName returns the last part of a resource URL, e.g. the machine type of an instance
+func getGCPResourceName(resourceUrl string) string {
+	return resourceUrl[strings.LastIndex(resourceUrl, "/")+1:]
+}
+
+// getGCPResourcePath strips the API host and version from a resource URL, since the same resource is referenced through
+// both compute.googleapis.com and www.googleapis.com
+func getGCPResourcePath(resourceUrl string) string {
+	if idx := strings.Index(resourceUrl, "projects/"); idx != -1 {
+		return resourceUrl[idx:]
+	}
+
+	return resourceUrl
+}
+
+// getGCPRegion returns the region of a zone, region or location, which may be given as a URL
+func getGCPRegion(location string) string {
+	location = getGCPResourceName(location)
+	parts := strings.Split(location, "-")
+
+	// Zones add a single letter to their region, e.g. us-central1-a
+	if len(parts) == 3 && len(parts[2]) == 1 {
+		return strings.Join(parts[:2], "-")
+	}
+
+	return location
+}
+
+// getGKEClusterId qualifies the cluster name with its zone or region, since zonal clusters in different zones of a
+// region can share a name
+func getGKEClusterId(location string, name string) string {
+	return fmt.Sprintf("%s/%s", location, name)
+}
+
+// getGKEClusterIdFromUrl reads the location and name from a cluster URL, e.g. .../zones/us-central1-a/clusters/name
+func getGKEClusterIdFromUrl(clusterUrl string) string {
+	parts := strings.Split(clusterUrl, "/")
+
+	for idx := 0; idx < len(parts)-3; idx++ {
+		if (parts[idx] == "zones" || parts[idx] == "locations") && parts[idx+2] == "clusters" {
+			return getGKEClusterId(parts[idx+1], parts[idx+3])
+		}
+	}
+
+	return getGCPResourceName(clusterUrl)
+}
+
+// getGCPResourceLocation finds the zone or region in a resource URL, e.g. .../zones/us-central1-a/instances/name
+func getGCPResourceLocation(resourceUrl string) string {
+	parts := strings.Split(resourceUrl, "/")
+
+	for idx := 0; idx < len(parts)-1; idx++ {
+		switch parts[idx] {
+		case "zones", "regions", "locations":
+			return getGCPRegion(parts[idx+1])
+		}
+	}
+
+	return ""
+}
+
+func (client *GCPClient) getInstances(projectId string) ([]GCPInstance, error) {
+	var instances []GCPInstance
+	pageToken := ""
+
+	for ok := true; ok; ok = pageToken != "" {
+		var page gcpAggregatedInstances
+		if err := client.get(client.ComputeEndpoint, fmt.Sprintf("/compute/v1/projects/%s/aggregated/instances", projectId), pageToken, &page); err != nil {
+			return nil, fmt.Errorf("failed to get Compute Engine instances %w", err)
+		}
+
+		for _, scopedList := range page.Items {
+			for _, instanceResponse := range scopedList.Instances {
+				instance := NewGCPInstance()
+				instance.CloudResource = newGCPCloudResource(instanceResponse.ID, instanceResponse.Name, getGCPRegion(instanceResponse.Zone), instanceResponse.Labels, instanceResponse.CreationTimestamp, projectId)
+				instance.Zone = getGCPResourceName(instanceResponse.Zone)
+				instance.InstanceType = getGCPResourceName(instanceResponse.MachineType)
+				instance.State = instanceResponse.Status
+				instance.SelfLink = instanceResponse.SelfLink
+
+				// Disks are referenced by URL until the inventory resolves them to disk IDs
+				for _, disk := range instanceResponse.Disks {
+					if disk.Source != "" {
+						instance.VolumeIDs = append(instance.VolumeIDs, disk.Source)
+					}
+				}
+
+				if len(instanceResponse.NetworkInterfaces) > 0 {
+					instance.SubnetID = getGCPResourceName(instanceResponse.NetworkInterfaces[0].Subnetwork)
+				}
+
+				instance.GKEClusterName = instanceResponse.Labels[gkeClusterNameLabel]
+				instance.GKEClusterLocation = instanceResponse.Labels[gkeClusterLocationLabel]
+
+				for _, item := range instanceResponse.Metadata.Items {
+					if item.Key == gkeClusterNameMetadataKey && instance.GKEClusterName == "" {
+						instance.GKEClusterName = item.Value
+					}
+
+					if item.Key == gkeClusterLocationMetadataKey && instance.GKEClusterLocation == "" {
+						instance.GKEClusterLocation = item.Value
+					}
+				}
+
+				instances = append(instances, *instance)
+			}
+		}
+
+		pageToken = page.NextPageToken
+	}
+
+	log.Printf("Found %d Compute Engine instances in project %s", len(instances), projectId)
+	return instances, nil
+}
+
+func (client *GCPClient) getDisks(projectId string) ([]GCPPersistentDisk, error) {
+	var disks []GCPPersistentDisk
+	pageToken := ""
+
+	for ok := true; ok; ok = pageToken != "" {
+		var page gcpAggregatedDisks
+		if err := client.get(client.ComputeEndpoint, fmt.Sprintf("/compute/v1/projects/%s/aggregated/disks", projectId), pageToken, &page); err != nil {
+			return nil, fmt.Errorf("failed to get persistent disks %w", err)
+		}
+
+		for _, scopedList := range page.Items {
+			for _, diskResponse := range scopedList.Disks {
+				// Regional disks are replicated across zones and have no zone of their own
+				location := diskResponse.Zone
+				if location == "" {
+					location = diskResponse.Region
+				}
+
+				disk := NewGCPPersistentDisk()
+				disk.CloudResource = newGCPCloudResource(diskResponse.ID, diskResponse.Name, getGCPRegion(location), diskResponse.Labels, diskResponse.CreationTimestamp, projectId)
+				disk.Zone = getGCPResourceName(diskResponse.Zone)
+				disk.VolumeType = getGCPResourceName(diskResponse.Type)
+				disk.State = diskResponse.Status
+				disk.SelfLink = diskResponse.SelfLink
+				disk.Users = diskResponse.Users
+				disk.SizeGiB, _ = strconv.ParseInt(diskResponse.SizeGb, 10, 64)
+
+				disks = append(disks, *disk)
+			}
+		}
+
+		pageToken = page.NextPageToken
+	}
+
+	log.Printf("Found %d persistent disks in project %s", len(disks), projectId)
+	return disks, nil
+}
+
+func (client *GCPClient) getGKEClusters(projectId string) ([]GKECluster, error) {
+	var response gkeClusterList
+	if err := client.get(client.ContainerEndpoint, fmt.Sprintf("/v1/projects/%s/locations/-/clusters", projectId), "", &response); err != nil {
+		return nil, fmt.Errorf("failed to get GKE clusters %w", err)
+	}
+
+	var gkeClusters []GKECluster
+
+	for _, clusterResponse := range response.Clusters {
+		gkeCluster := NewGKECluster()
+		gkeCluster.CloudResource = newGCPCloudResource(getGKEClusterId(clusterResponse.Location, clusterResponse.Name), clusterResponse.Name, getGCPRegion(clusterResponse.Location), clusterResponse.ResourceLabels, clusterResponse.CreateTime, projectId)
+		gkeCluster.Location = clusterResponse.Location
+		gkeCluster.State = clusterResponse.Status
+		gkeCluster.Version = clusterResponse.CurrentMasterVersion
+		gkeCluster.NodeCount = clusterResponse.CurrentNodeCount
+		gkeCluster.NetworkID = clusterResponse.Network
+		gkeCluster.SelfLink = clusterResponse.SelfLink
+
+		gkeClusters = append(gkeClusters, *gkeCluster)
+	}
+
+	log.Printf("Found %d GKE clusters in project %s", len(gkeClusters), projectId)
+	return gkeClusters, nil
+}
+
+func (client *GCPClient) getDeployments(projectId string) ([]DeploymentManagerDeployment, error) {
+	var deployments []DeploymentManagerDeployment
+	pageToken := ""
+
+	for ok := true; ok; ok = pageToken != "" {
+		var page gcpDeploymentList
+		if err := client.get(client.DeploymentManagerEndpoint, fmt.Sprintf("/deploymentmanager/v2/projects/%s/global/deployments", projectId), pageToken, &page); err != nil {
+			return nil, fmt.Errorf("failed to get Deployment Manager deployments %w", err)
+		}
+
+		for _, deploymentResponse := range page.Deployments {
+			labels := map[string]string{}
+			for _, label := range deploymentResponse.Labels {
+				labels[label.Key] = label.Value
+			}
+
+			deployment := NewDeploymentManagerDeployment()
+			deployment.CloudResource = newGCPCloudResource(deploymentResponse.ID, deploymentResponse.Name, GCPGlobalRegion, labels, deploymentResponse.InsertTime, projectId)
+
+			resources, err := client.getDeploymentResources(projectId, deployment.Name)
+			if err != nil {
+				log.Println(err)
+			} else {
+				deployment.Resources = resources
+			}
+
+			// Deployments are claimed in the region their resources were created in
+			for _, resource := range deployment.Resources {
+				if location := getGCPResourceLocation(resource.PhysicalID); location != "" {
+					deployment.Region = location
+					break
+				}
+			}
+
+			deployments = append(deployments, *deployment)
+		}
+
+		pageToken = page.NextPageToken
+	}
+
+	log.Printf("Found %d Deployment Manager deployments in project %s", len(deployments), projectId)
+	return deployments, nil
+}
+
+func (client *GCPClient) getDeploymentResources(projectId string, deploymentName string) ([]StackResource, error) {
+	var resources []StackResource
+	pageToken := ""
+
+	for ok := true; ok; ok = pageToken != "" {
+		var page gcpDeploymentResourceList
+		if err := client.get(client.DeploymentManagerEndpoint, fmt.Sprintf("/deploymentmanager/v2/projects/%s/global/deployments/%s/resources", projectId, deploymentName), pageToken, &page); err != nil {
+			return nil, fmt.Errorf("unable to list resources of Deployment Manager deployment %s: %s", deploymentName, err)
+		}
+
+		for _, resource := range page.Resources {
+			resources = append(resources, StackResource{LogicalID: resource.Name, PhysicalID: resource.URL, Type: resource.Type})
+		}
+
+		pageToken = page.NextPageToken
+	}
+
+	return resources, nil
+}
+
+// getInventory lists every supported resource type in a project. Types that cannot be listed are reported as scan
+// errors and the rest of the project is still scanned.
+func (client *GCPClient) getInventory(projectId string, globalCtx *GlobalCloudContext) *GCPInventory {
+	inventory := &GCPInventory{}
+
+	addScanError := func(operation string, nodeType NodeType, err error) {
+		globalCtx.AddScanError(ScanError{
+			Account:   projectId,
+			Kind:      ScanErrorCollect,
+			NodeTypes: []NodeType{nodeType},
+			Operation: operation,
+			Message:   err.Error(),
+		})
+	}
+
+	var err error
+
+	if inventory.Instances, err = client.getInstances(projectId); err != nil {
+		addScanError("get Compute Engine instances", NodeGCPInstance, err)
+	}
+
+	if inventory.Disks, err = client.getDisks(projectId); err != nil {
+		addScanError("get persistent disks", NodeGCPPersistentDisk, err)
+	}
+
+	if inventory.GKEClusters, err = client.getGKEClusters(projectId); err != nil {
+		addScanError("get GKE clusters", NodeGKECluster, err)
+	}
+
+	if inventory.Deployments, err = client.getDeployments(projectId); err != nil {
+		addScanError("get Deployment Manager deployments", NodeDeploymentManagerDeployment, err)
+	}
+
+	inventory.resolveResourceIDs()
+	return inventory
+}
+
+// resolveResourceIDs replaces the URLs instances and deployments use to refer to other resources with the IDs of those
+// resources
+func (inventory *GCPInventory) resolveResourceIDs() {
+	idsByPath := map[string]string{}
+
+	for _, instance := range inventory.Instances {
+		idsByPath[getGCPResourcePath(instance.SelfLink)] = instance.ID
+	}
+
+	for _, disk := range inventory.Disks {
+		idsByPath[getGCPResourcePath(disk.SelfLink)] = disk.ID
+	}
+
+	for idx := range inventory.Instances {
+		volumeIds := inventory.Instances[idx].VolumeIDs
+
+		for volumeIdx, diskLink := range volumeIds {
+			if id, ok := idsByPath[getGCPResourcePath(diskLink)]; ok {
+				volumeIds[volumeIdx] = id
+			}
+		}
+	}
+
+	for idx := range inventory.Deployments {
+		resources := inventory.Deployments[idx].Resources
+
+		for resourceIdx, resource := range resources {
+			// GKE clusters are identified by their location and name, which are the end of their URL
+			if resource.Type == deploymentGKEResourceType {
+				resources[resourceIdx].PhysicalID = getGKEClusterIdFromUrl(resource.PhysicalID)
+			} else if id, ok := idsByPath[getGCPResourcePath(resource.PhysicalID)]; ok {
+				resources[resourceIdx].PhysicalID = id
+			}
+		}
+	}
+}
+
+func (inventory *GCPInventory) Regions() []string {
+	regions := map[string]bool{}
+
+	for _, instance := range inventory.Instances {
+		regions[instance.Region] = true
+	}
+
+	for _, disk := range inventory.Disks {
+		regions[disk.Region] = true
+	}
+
+	for _, gkeCluster := range inventory.GKEClusters {
+		regions[gkeCluster.Region] = true
+	}
+
+	for _, deployment := range inventory.Deployments {
+		regions[deployment.Region] = true
+	}
+
+	var sorted []string
+	for region := range regions {
+		if region != "" {
+			sorted = append(sorted, region)
+		}
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// getGCPScopes lists every configured project up front, since the APIs list a whole project at once, and returns a
+// scope for every region something was found in
+func getGCPScopes(globalCtx *GlobalCloudContext) []*ScanScope {
+	projectIds := getGCPProjectIds()
+
+	client, err := NewGCPClient()
+	if err != nil {
+		for _, projectId := range projectIds {
+			globalCtx.AddScanError(ScanError{
+				Account:   projectId,
+				Kind:      ScanErrorCollect,
+				Operation: "authenticate with GCP",
+				Message:   err.Error(),
+			})
+		}
+		return nil
+	}
+
+	var scopes []*ScanScope
+
+	for _, projectId := range projectIds {
+		log.Printf("Listing GCP project %s", projectId)
+		inventory := client.getInventory(projectId, globalCtx)
+
+		regions := inventory.Regions()
+		globalCtx.AccountRegions[projectId] = regions
+		log.Printf("Scanning %d regions in GCP project %s", len(regions), projectId)
+
+		for _, region := range regions {
+			scopes = append(scopes, &ScanScope{
+				Provider: ProviderGCP,
+				GCP:      inventory,
+				Account:  projectId,
+				Region:   region,
+			})
+		}
+	}
+
+	return scopes
+}
+
+type GCPInstanceCollector struct{}
+
+func (collector *GCPInstanceCollector) Name() string {
+	return "Compute Engine instances"
+}
+
+func (collector *GCPInstanceCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	for _, instance := range scope.GCP.Instances {
+		if instance.Region == scope.Region {
+			ctx.AddResource(instance)
+		}
+	}
+
+	return nil
+}
+
+type GCPPersistentDiskCollector struct{}
+
+func (collector *GCPPersistentDiskCollector) Name() string {
+	return "persistent disks"
+}
+
+func (collector *GCPPersistentDiskCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	for _, disk := range scope.GCP.Disks {
+		if disk.Region == scope.Region {
+			ctx.AddResource(disk)
+		}
+	}
+
+	return nil
+}
+
+type GKEClusterCollector struct{}
+
+func (collector *GKEClusterCollector) Name() string {
+	return "GKE clusters"
+}
+
+func (collector *GKEClusterCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	for _, gkeCluster := range scope.GCP.GKEClusters {
+		if gkeCluster.Region == scope.Region {
+			ctx.AddResource(gkeCluster)
+		}
+	}
+
+	return nil
+}
+
+type DeploymentManagerDeploymentCollector struct{}
+
+func (collector *DeploymentManagerDeploymentCollector) Name() string {
+	return "Deployment Manager deployments"
+}
+
+func (collector *DeploymentManagerDeploymentCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	for _, deployment := range scope.GCP.Deployments {
+		if deployment.Region == scope.Region {
+			ctx.AddResource(deployment)
+		}
+	}
+
+	return nil
+}
+
+var gcpCollectorRegistry []Collector
+
+func RegisterGCPCollector(collector Collector) {
+	gcpCollectorRegistry = append(gcpCollectorRegistry, collector)
+}
+
+func RegisteredGCPCollectors() []Collector {
+	registered := make([]Collector, len(gcpCollectorRegistry))
+	copy(registered, gcpCollectorRegistry)
+	return registered
+}
+
+func init() {
+	RegisterGCPCollector(&GCPInstanceCollector{})
+	RegisterGCPCollector(&GCPPersistentDiskCollector{})
+	RegisterGCPCollector(&GKEClusterCollector{})
+	RegisterGCPCollector(&DeploymentManagerDeploymentCollector{})
+}
@@ -0,0 +1,440 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	ResourceTypeGCPInstance                 = "Compute Engine instances"
+	ResourceTypeGCPPersistentDisk           = "GCP persistent disks"
+	ResourceTypeGKECluster                  = "GKE clusters"
+	ResourceTypeDeploymentManagerDeployment = "Deployment Manager deployments"
+)
+
+const (
+	NodeGCPInstance                 NodeType = "gcp-instance"
+	NodeGCPPersistentDisk           NodeType = "gcp-persistent-disk"
+	NodeGKECluster                  NodeType = "gke-cluster"
+	NodeDeploymentManagerDeployment NodeType = "deployment-manager-deployment"
+)
+
+const EdgeMemberOfGKE EdgeType = "member-of-gke"
+
+// GCP label keys are lower case, so Couchbase Cloud labels its GKE clusters and instances with the lower case cloud ID tag
+const GCPCloudIdLabel = "cloudid"
+
+const (
+	deploymentInstanceResourceType = "compute.v1.instance"
+	deploymentDiskResourceType     = "compute.v1.disk"
+	deploymentGKEResourceType      = "container.v1.cluster"
+)
+
+// GCPInstance names the GKE cluster of its node pool, if any. GKEClusterLocation is empty for nodes created before GKE
+// started recording it, they are matched on the cluster name alone.
+type GCPInstance struct {
+	Compute
+	Zone               string
+	SelfLink           string
+	GKEClusterName     string
+	GKEClusterLocation string
+	Disks              map[string]GCPPersistentDisk
+}
+
+type GCPPersistentDisk struct {
+	Volume
+	Zone     string
+	SelfLink string
+	Users    []string
+}
+
+// GKECluster is identified by its location and name, as zonal clusters in different zones can share a name
+type GKECluster struct {
+	KubernetesCluster
+	Location  string
+	SelfLink  string
+	Instances map[string]GCPInstance
+}
+
+type DeploymentManagerDeployment struct {
+	Stack
+	Instances   map[string]GCPInstance
+	Disks       map[string]GCPPersistentDisk
+	GKEClusters map[string]GKECluster
+}
+
+func NewGCPInstance() *GCPInstance {
+	return &GCPInstance{
+		Disks: make(map[string]GCPPersistentDisk),
+	}
+}
+
+func NewGCPPersistentDisk() *GCPPersistentDisk {
+	return &GCPPersistentDisk{}
+}
+
+func NewGKECluster() *GKECluster {
+	return &GKECluster{
+		Instances: make(map[string]GCPInstance),
+	}
+}
+
+func NewDeploymentManagerDeployment() *DeploymentManagerDeployment {
+	return &DeploymentManagerDeployment{
+		Instances:   make(map[string]GCPInstance),
+		Disks:       make(map[string]GCPPersistentDisk),
+		GKEClusters: make(map[string]GKECluster),
+	}
+}
+
+func (instance GCPInstance) Resource() CloudResource {
+	return instance.CloudResource
+}
+
+func (instance GCPInstance) WithCloudResource(resource CloudResource) ReportableResource {
+	instance.CloudResource = resource
+	return instance
+}
+
+func (instance GCPInstance) Kind() string {
+	return ResourceTypeGCPInstance
+}
+
+func (instance GCPInstance) ReportFields() []ReportField {
+	return []ReportField{
+		{Label: "Machine type", Value: instance.InstanceType},
+		{Label: "Status", Value: instance.State},
+		{Label: "Zone", Value: instance.Zone},
+		{Label: "Disks", Value: strconv.Itoa(len(instance.Disks))},
+	}
+}
+
+func (instance GCPInstance) ClaimedResources() []ReportableResource {
+	var resources []ReportableResource
+
+	for _, disk := range instance.Disks {
+		resources = append(resources, disk)
+	}
+
+	return resources
+}
+
+func (disk GCPPersistentDisk) Resource() CloudResource {
+	return disk.CloudResource
+}
+
+func (disk GCPPersistentDisk) WithCloudResource(resource CloudResource) ReportableResource {
+	disk.CloudResource = resource
+	return disk
+}
+
+func (disk GCPPersistentDisk) Kind() string {
+	return ResourceTypeGCPPersistentDisk
+}
+
+func (disk GCPPersistentDisk) ReportFields() []ReportField {
+	attached := "no"
+	if len(disk.Users) > 0 {
+		attached = "yes"
+	}
+
+	return []ReportField{
+		{Label: "Size", Value: fmt.Sprintf("%d GiB", disk.SizeGiB)},
+		{Label: "Type", Value: disk.VolumeType},
+		{Label: "Status", Value: disk.State},
+		{Label: "Attached", Value: attached},
+	}
+}
+
+func (gkeCluster GKECluster) Resource() CloudResource {
+	return gkeCluster.CloudResource
+}
+
+func (gkeCluster GKECluster) WithCloudResource(resource CloudResource) ReportableResource {
+	gkeCluster.CloudResource = resource
+	return gkeCluster
+}
+
+func (gkeCluster GKECluster) Kind() string {
+	return ResourceTypeGKECluster
+}
+
+func (gkeCluster GKECluster) ReportFields() []ReportField {
+	return []ReportField{
+		{Label: "Location", Value: gkeCluster.Location},
+		{Label: "Status", Value: gkeCluster.State},
+		{Label: "Version", Value: gkeCluster.Version},
+		{Label: "Nodes", Value: strconv.Itoa(gkeCluster.NodeCount)},
+		{Label: "Instances", Value: strconv.Itoa(len(gkeCluster.Instances))},
+	}
+}
+
+func (gkeCluster GKECluster) ClaimedResources() []ReportableResource {
+	var resources []ReportableResource
+
+	for _, instance := range gkeCluster.Instances {
+		resources = append(resources, instance)
+	}
+
+	return resources
+}
+
+func (deployment DeploymentManagerDeployment) Resource() CloudResource {
+	return deployment.CloudResource
+}
+
+func (deployment DeploymentManagerDeployment) WithCloudResource(resource CloudResource) ReportableResource {
+	deployment.CloudResource = resource
+	return deployment
+}
+
+func (deployment DeploymentManagerDeployment) Kind() string {
+	return ResourceTypeDeploymentManagerDeployment
+}
+
+func (deployment DeploymentManagerDeployment) ReportFields() []ReportField {
+	return []ReportField{
+		{Label: "Resources", Value: strconv.Itoa(len(deployment.Resources))},
+	}
+}
+
+func (deployment DeploymentManagerDeployment) ClaimedResources() []ReportableResource {
+	var resources []ReportableResource
+
+	for _, gkeCluster := range deployment.GKEClusters {
+		resources = append(resources, gkeCluster)
+	}
+
+	for _, instance := range deployment.Instances {
+		resources = append(resources, instance)
+	}
+
+	for _, disk := range deployment.Disks {
+		resources = append(resources, disk)
+	}
+
+	return resources
+}
+
+func (instance *GCPInstance) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
+	switch resource.(type) {
+	case GCPPersistentDisk:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		disk := resource.(GCPPersistentDisk)
+		instance.Disks[disk.ID] = disk
+		return claimed()
+	}
+
+	return cannotClaim(resource)
+}
+
+func (gkeCluster *GKECluster) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
+	switch resource.(type) {
+	case GCPInstance:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		instance := resource.(GCPInstance)
+		gkeCluster.Instances[instance.ID] = instance
+		return claimed()
+	}
+
+	return cannotClaim(resource)
+}
+
+func (deployment *DeploymentManagerDeployment) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
+	switch resource.(type) {
+	case GKECluster:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		gkeCluster := resource.(GKECluster)
+		deployment.GKEClusters[gkeCluster.ID] = gkeCluster
+		return claimed()
+	case GCPInstance:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		instance := resource.(GCPInstance)
+		deployment.Instances[instance.ID] = instance
+		return claimed()
+	case GCPPersistentDisk:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		disk := resource.(GCPPersistentDisk)
+		deployment.Disks[disk.ID] = disk
+		return claimed()
+	}
+
+	return cannotClaim(resource)
+}
+
+func init() {
+	RegisterStackResourceType(deploymentInstanceResourceType, ResourceTypeGCPInstance)
+	RegisterStackResourceType(deploymentDiskResourceType, ResourceTypeGCPPersistentDisk)
+	RegisterStackResourceType(deploymentGKEResourceType, ResourceTypeGKECluster)
+
+	RegisterResourceNodeType(ResourceTypeGCPInstance, NodeGCPInstance)
+	RegisterResourceNodeType(ResourceTypeGCPPersistentDisk, NodeGCPPersistentDisk)
+	RegisterResourceNodeType(ResourceTypeGKECluster, NodeGKECluster)
+	RegisterResourceNodeType(ResourceTypeDeploymentManagerDeployment, NodeDeploymentManagerDeployment)
+}
+
+func (ctx *RegionalCloudContext) GetGCPInstances() []GCPInstance {
+	var instances []GCPInstance
+
+	for _, resource := range ctx.Resources[ResourceTypeGCPInstance] {
+		instances = append(instances, resource.(GCPInstance))
+	}
+
+	return instances
+}
+
+func (ctx *RegionalCloudContext) GetGKEClusters() []GKECluster {
+	var gkeClusters []GKECluster
+
+	for _, resource := range ctx.Resources[ResourceTypeGKECluster] {
+		gkeClusters = append(gkeClusters, resource.(GKECluster))
+	}
+
+	return gkeClusters
+}
+
+func (ctx *RegionalCloudContext) GetDeploymentManagerDeployments() []DeploymentManagerDeployment {
+	var deployments []DeploymentManagerDeployment
+
+	for _, resource := range ctx.Resources[ResourceTypeDeploymentManagerDeployment] {
+		deployments = append(deployments, resource.(DeploymentManagerDeployment))
+	}
+
+	return deployments
+}
+
+func (instance GCPInstance) isGKEClusterNode(gkeCluster GKECluster) bool {
+	if instance.GKEClusterName != gkeCluster.Name {
+		return false
+	}
+
+	return instance.GKEClusterLocation == "" || instance.GKEClusterLocation == gkeCluster.Location
+}
+
+// getGCPInstancesByGKEClusterId groups the instances backing GKE node pools by the ID of their cluster
+func (ctx *RegionalCloudContext) getGCPInstancesByGKEClusterId() map[string][]GCPInstance {
+	instances := map[string][]GCPInstance{}
+	gkeClusters := ctx.GetGKEClusters()
+
+	for _, instance := range ctx.GetGCPInstances() {
+		for _, gkeCluster := range gkeClusters {
+			if instance.isGKEClusterNode(gkeCluster) {
+				instances[gkeCluster.ID] = append(instances[gkeCluster.ID], instance)
+			}
+		}
+	}
+
+	return instances
+}
+
+func getGKEClusterClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+	instancesByGKEClusterId := ctx.getGCPInstancesByGKEClusterId()
+
+	for _, gkeCluster := range ctx.GetGKEClusters() {
+		claimer := gkeCluster
+
+		for _, instance := range instancesByGKEClusterId[gkeCluster.ID] {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  &claimer,
+				Resource: instance,
+				Reason:   fmt.Sprintf("labelled with %s", gkeClusterNameLabel),
+			})
+		}
+	}
+
+	return candidates
+}
+
+func getCouchbaseCloudGCPClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+
+	for _, gkeCluster := range ctx.GetGKEClusters() {
+		if couchbaseCloud, ok := ctx.CouchbaseClouds[gkeCluster.Tags[GCPCloudIdLabel]]; ok {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  couchbaseCloud,
+				Resource: gkeCluster,
+				Reason:   fmt.Sprintf("labelled with %s", GCPCloudIdLabel),
+			})
+		}
+	}
+
+	for _, instance := range ctx.GetGCPInstances() {
+		if couchbaseCloud, ok := ctx.CouchbaseClouds[instance.Tags[GCPCloudIdLabel]]; ok {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  couchbaseCloud,
+				Resource: instance,
+				Reason:   fmt.Sprintf("labelled with %s", GCPCloudIdLabel),
+			})
+		}
+	}
+
+	return candidates
+}
+
+// addGCPResources adds every candidate ownership of the GCP resources in the region to the graph
+func addGCPResources(graph *OwnershipGraph, ctx *RegionalCloudContext) {
+	instancesByGKEClusterId := ctx.getGCPInstancesByGKEClusterId()
+	deploymentNodeTypes := map[string]NodeType{
+		deploymentInstanceResourceType: NodeGCPInstance,
+		deploymentDiskResourceType:     NodeGCPPersistentDisk,
+		deploymentGKEResourceType:      NodeGKECluster,
+	}
+
+	for _, resource := range ctx.Resources[ResourceTypeGCPPersistentDisk] {
+		graph.AddNode(NodeGCPPersistentDisk, resource.Resource())
+	}
+
+	for _, instance := range ctx.GetGCPInstances() {
+		instanceNodeId := graph.AddNode(NodeGCPInstance, instance.CloudResource)
+
+		if couchbaseCloud, ok := ctx.CouchbaseClouds[instance.Tags[GCPCloudIdLabel]]; ok {
+			graph.AddEdge(instanceNodeId, graph.AddNode(NodeCouchbaseCloud, couchbaseCloud.CloudResource), EdgeBelongsToCloud)
+		}
+
+		for _, volumeId := range instance.VolumeIDs {
+			if disk, ok := ctx.Resources[ResourceTypeGCPPersistentDisk][volumeId]; ok {
+				graph.AddEdge(GetNodeId(NodeGCPPersistentDisk, disk.Resource()), instanceNodeId, EdgeAttachedTo)
+			}
+		}
+	}
+
+	for _, gkeCluster := range ctx.GetGKEClusters() {
+		gkeNodeId := graph.AddNode(NodeGKECluster, gkeCluster.CloudResource)
+
+		for _, instance := range instancesByGKEClusterId[gkeCluster.ID] {
+			graph.AddEdge(GetNodeId(NodeGCPInstance, instance.CloudResource), gkeNodeId, EdgeMemberOfGKE)
+		}
+
+		if couchbaseCloud, ok := ctx.CouchbaseClouds[gkeCluster.Tags[GCPCloudIdLabel]]; ok {
+			graph.AddEdge(gkeNodeId, graph.AddNode(NodeCouchbaseCloud, couchbaseCloud.CloudResource), EdgeBelongsToCloud)
+		}
+	}
+
+	kindsByResourceType := RegisteredStackResourceTypes()
+
+	for _, deployment := range ctx.GetDeploymentManagerDeployments() {
+		deploymentNodeId := graph.AddNode(NodeDeploymentManagerDeployment, deployment.CloudResource)
+
+		for _, stackResource := range deployment.Resources {
+			nodeType, ok := deploymentNodeTypes[stackResource.Type]
+			if !ok {
+				continue
+			}
+
+			if resource, ok := ctx.Resources[kindsByResourceType[stackResource.Type]][stackResource.PhysicalID]; ok {
+				graph.AddEdge(GetNodeId(nodeType, resource.Resource()), deploymentNodeId, EdgeCreatedByStack)
+			}
+		}
+	}
+}
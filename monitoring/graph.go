@@ -0,0 +1,303 @@
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type NodeType string
+type EdgeType string
+
+const (
+	NodeCouchbaseCloud        NodeType = "couchbase-cloud"
+	NodeCouchbaseCloudCluster NodeType = "couchbase-cloud-cluster"
+	NodeCloudformationStack   NodeType = "cloudformation-stack"
+	NodeEKSCluster            NodeType = "eks-cluster"
+	NodeEC2Instance           NodeType = "ec2-instance"
+	NodeEBSVolume             NodeType = "ebs-volume"
+)
+
+const (
+	EdgeAttachedTo               EdgeType = "attached-to"
+	EdgeCreatedByStack           EdgeType = "created-by-stack"
+	EdgeMemberOfEKS              EdgeType = "member-of-eks"
+	EdgeMemberOfCouchbaseCluster EdgeType = "member-of-couchbase-cluster"
+	EdgeBelongsToCloud           EdgeType = "belongs-to-cloud"
+)
+
+// resourceNodeTypes maps the kinds of provider neutral resources to the node type they are reported as
+var resourceNodeTypes = map[string]NodeType{}
+
+// RegisterResourceNodeType lets expired resources of kind be referenced, and acted on, as nodeType
+func RegisterResourceNodeType(kind string, nodeType NodeType) {
+	resourceNodeTypes[kind] = nodeType
+}
+
+func GetResourceNodeType(kind string) (NodeType, bool) {
+	nodeType, ok := resourceNodeTypes[kind]
+	return nodeType, ok
+}
+
+type GraphNode struct {
+	ID       string   `json:"id"`
+	Type     NodeType `json:"type"`
+	Name     string   `json:"name,omitempty"`
+	Provider string   `json:"provider,omitempty"`
+	Account  string   `json:"account,omitempty"`
+	Region   string   `json:"region,omitempty"`
+}
+
+// GraphEdge always points from the owned resource to its owner
+type GraphEdge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Type EdgeType `json:"type"`
+}
+
+// OwnershipGraph keeps every candidate ownership relationship, unlike claims where the first claimer wins
+type OwnershipGraph struct {
+	Nodes map[string]GraphNode
+	Edges []GraphEdge
+	edges map[GraphEdge]bool
+	mutex sync.Mutex
+}
+
+func NewOwnershipGraph() *OwnershipGraph {
+	return &OwnershipGraph{
+		Nodes: make(map[string]GraphNode),
+		edges: make(map[GraphEdge]bool),
+	}
+}
+
+// GetNodeId scopes the ID of regional resources to their account and region, since names such as EKS cluster names
+// and RDS identifiers are only unique within a region. Couchbase Cloud resources have neither and keep their own ID.
+func GetNodeId(nodeType NodeType, resource CloudResource) string {
+	if resource.Account == "" && resource.Region == "" {
+		return fmt.Sprintf("%s:%s", nodeType, resource.ID)
+	}
+
+	return fmt.Sprintf("%s:%s:%s:%s", nodeType, resource.Account, resource.Region, resource.ID)
+}
+
+// getEKSClusterNodeResource identifies EKS clusters by name, as the regional context does
+func getEKSClusterNodeResource(eksCluster EKSCluster) CloudResource {
+	resource := eksCluster.CloudResource
+	resource.ID = eksCluster.Name
+	return resource
+}
+
+func (graph *OwnershipGraph) AddNode(nodeType NodeType, resource CloudResource) string {
+	nodeId := GetNodeId(nodeType, resource)
+
+	graph.Nodes[nodeId] = GraphNode{
+		ID:       nodeId,
+		Type:     nodeType,
+		Name:     resource.Name,
+		Provider: resource.Provider,
+		Account:  resource.Account,
+		Region:   resource.Region,
+	}
+
+	return nodeId
+}
+
+func (graph *OwnershipGraph) AddEdge(from string, to string, edgeType EdgeType) {
+	edge := GraphEdge{From: from, To: to, Type: edgeType}
+
+	if graph.edges[edge] {
+		return
+	}
+
+	graph.edges[edge] = true
+	graph.Edges = append(graph.Edges, edge)
+}
+
+func (graph *OwnershipGraph) Merge(other *OwnershipGraph) {
+	graph.mutex.Lock()
+	defer graph.mutex.Unlock()
+
+	for nodeId, node := range other.Nodes {
+		graph.Nodes[nodeId] = node
+	}
+
+	for _, edge := range other.Edges {
+		graph.AddEdge(edge.From, edge.To, edge.Type)
+	}
+}
+
+// ForAccount returns the resources of a single account along with every owner reachable from them
+func (graph *OwnershipGraph) ForAccount(account string) *OwnershipGraph {
+	subgraph := NewOwnershipGraph()
+	parents := map[string][]GraphEdge{}
+	var pending []string
+
+	for _, edge := range graph.Edges {
+		parents[edge.From] = append(parents[edge.From], edge)
+	}
+
+	for nodeId, node := range graph.Nodes {
+		if node.Account == account {
+			pending = append(pending, nodeId)
+		}
+	}
+
+	for len(pending) > 0 {
+		nodeId := pending[0]
+		pending = pending[1:]
+
+		if _, ok := subgraph.Nodes[nodeId]; ok {
+			continue
+		}
+
+		subgraph.Nodes[nodeId] = graph.Nodes[nodeId]
+
+		for _, edge := range parents[nodeId] {
+			subgraph.AddEdge(edge.From, edge.To, edge.Type)
+			pending = append(pending, edge.To)
+		}
+	}
+
+	return subgraph
+}
+
+func (graph *OwnershipGraph) SortedNodes() []GraphNode {
+	var nodes []GraphNode
+
+	for _, node := range graph.Nodes {
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	return nodes
+}
+
+func (graph *OwnershipGraph) SortedEdges() []GraphEdge {
+	edges := make([]GraphEdge, len(graph.Edges))
+	copy(edges, graph.Edges)
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+
+		return edges[i].Type < edges[j].Type
+	})
+
+	return edges
+}
+
+// BuildOwnershipGraph must run before claims are processed, since claiming removes resources from the regional context
+func BuildOwnershipGraph(ctx *RegionalCloudContext) *OwnershipGraph {
+	graph := NewOwnershipGraph()
+
+	for _, ebsVolume := range ctx.EBSVolumes {
+		graph.AddNode(NodeEBSVolume, ebsVolume.CloudResource)
+	}
+
+	for _, ec2Instance := range ctx.EC2Instances {
+		ec2NodeId := graph.AddNode(NodeEC2Instance, ec2Instance.CloudResource)
+
+		for _, volumeId := range ec2Instance.VolumeIDs {
+			if ebsVolume, ok := ctx.EBSVolumes[volumeId]; ok {
+				graph.AddEdge(GetNodeId(NodeEBSVolume, ebsVolume.CloudResource), ec2NodeId, EdgeAttachedTo)
+			}
+		}
+	}
+
+	for _, eksCluster := range ctx.EKSClusters {
+		graph.AddNode(NodeEKSCluster, getEKSClusterNodeResource(eksCluster))
+	}
+
+	for _, cloudformationStack := range ctx.CloudFormationStacks {
+		stackNodeId := graph.AddNode(NodeCloudformationStack, cloudformationStack.CloudResource)
+
+		for _, stackResource := range cloudformationStack.Resources {
+			if stackResource.Type == cloudformationEc2StackResourceId {
+				if ec2Instance, ok := ctx.EC2Instances[stackResource.PhysicalID]; ok {
+					graph.AddEdge(GetNodeId(NodeEC2Instance, ec2Instance.CloudResource), stackNodeId, EdgeCreatedByStack)
+				}
+			}
+		}
+
+		if cloudId, ok := cloudformationStack.Parameters[CloudformationCloudIdParameter]; ok {
+			if couchbaseCloud, ok := ctx.CouchbaseClouds[cloudId]; ok {
+				graph.AddEdge(stackNodeId, graph.AddNode(NodeCouchbaseCloud, couchbaseCloud.CloudResource), EdgeBelongsToCloud)
+			}
+		}
+	}
+
+	ec2InstancesByClusterId := ctx.GetEC2InstancesByClusterId()
+
+	for _, couchbaseCloudCluster := range ctx.CouchbaseCloudClusters {
+		ec2Instances, ok := ec2InstancesByClusterId[couchbaseCloudCluster.ID]
+		if !ok {
+			continue
+		}
+
+		clusterNodeId := graph.AddNode(NodeCouchbaseCloudCluster, couchbaseCloudCluster.CloudResource)
+
+		for _, ec2Instance := range ec2Instances {
+			graph.AddEdge(GetNodeId(NodeEC2Instance, ec2Instance.CloudResource), clusterNodeId, EdgeMemberOfCouchbaseCluster)
+
+			if eksClusterName, ok := ec2Instance.Tags[ec2EksClusterNameTag]; ok {
+				if eksCluster, ok := ctx.EKSClusters[eksClusterName]; ok {
+					graph.AddEdge(clusterNodeId, GetNodeId(NodeEKSCluster, getEKSClusterNodeResource(eksCluster)), EdgeMemberOfEKS)
+				}
+			}
+		}
+	}
+
+	ec2InstancesBySubnetId := ctx.GetEC2InstancesBySubnetId()
+
+	for _, eksCluster := range ctx.EKSClusters {
+		eksNodeId := GetNodeId(NodeEKSCluster, getEKSClusterNodeResource(eksCluster))
+
+		for _, eksSubnetId := range eksCluster.SubnetIDs {
+			for _, ec2Instance := range ec2InstancesBySubnetId[eksSubnetId] {
+				graph.AddEdge(GetNodeId(NodeEC2Instance, ec2Instance.CloudResource), eksNodeId, EdgeMemberOfEKS)
+			}
+		}
+
+		if cloudId, ok := eksCluster.Tags[EKSClusterCloudIdTag]; ok {
+			if couchbaseCloud, ok := ctx.CouchbaseClouds[cloudId]; ok {
+				graph.AddEdge(eksNodeId, graph.AddNode(NodeCouchbaseCloud, couchbaseCloud.CloudResource), EdgeBelongsToCloud)
+			}
+		}
+	}
+
+	addAzureResources(graph, ctx)
+	addGCPResources(graph, ctx)
+	addDataStoreResources(graph, ctx)
+	addNetworkResources(graph, ctx)
+
+	return graph
+}
+
+// addCloudformationStackEdges links the Cloudformation stacks in the region to the resources of the kinds kept in
+// RegionalCloudContext.Resources that they list, nodeTypes maps the stack resource types to graph node types
+func addCloudformationStackEdges(graph *OwnershipGraph, ctx *RegionalCloudContext, nodeTypes map[string]NodeType) {
+	kindsByResourceType := RegisteredStackResourceTypes()
+
+	for _, cloudformationStack := range ctx.CloudFormationStacks {
+		stackNodeId := GetNodeId(NodeCloudformationStack, cloudformationStack.CloudResource)
+
+		for _, stackResource := range cloudformationStack.Resources {
+			nodeType, ok := nodeTypes[stackResource.Type]
+			if !ok {
+				continue
+			}
+
+			if resource, ok := ctx.Resources[kindsByResourceType[stackResource.Type]][stackResource.PhysicalID]; ok {
+				graph.AddEdge(GetNodeId(nodeType, resource.Resource()), stackNodeId, EdgeCreatedByStack)
+			}
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const historyDBPathEnv = "HISTORY_DB_PATH"
+const historyPersistentRunsEnv = "HISTORY_PERSISTENT_RUNS"
+
+const defaultHistoryPersistentRuns = 5
+
+// Snapshots of older runs are pruned so the store does not grow forever
+const historyRetainedRuns = 90
+
+// Run keys are RFC3339 timestamps in UTC so bolt's byte ordering is chronological
+const historyRunKeyLayout = time.RFC3339
+
+var historySnapshotsBucket = []byte("snapshots")
+var historyInventoriesBucket = []byte("inventories")
+
+type HistoryInventory struct {
+	RunAt     time.Time   `json:"runAt"`
+	Resources []GraphNode `json:"resources"`
+}
+
+type PersistentResource struct {
+	Resource GraphNode
+	Runs     int
+}
+
+// HistoryDiff compares this run with the previous one. It is empty on the first run.
+type HistoryDiff struct {
+	PreviousRunAt       time.Time
+	PersistentRuns      int
+	New                 []GraphNode
+	Removed             []GraphNode
+	PersistentOffenders []PersistentResource
+}
+
+type HistoryStore struct {
+	db             *bolt.DB
+	persistentRuns int
+}
+
+func GetHistoryDBPath() string {
+	return os.Getenv(historyDBPathEnv)
+}
+
+func getHistoryPersistentRuns() int {
+	value := os.Getenv(historyPersistentRunsEnv)
+	if value == "" {
+		return defaultHistoryPersistentRuns
+	}
+
+	runs, err := strconv.Atoi(value)
+	if err != nil || runs < 2 {
+		log.Printf("Invalid %s %q, using %d", historyPersistentRunsEnv, value, defaultHistoryPersistentRuns)
+		return defaultHistoryPersistentRuns
+	}
+
+	return runs
+}
+
+func OpenHistoryStore(path string) (*HistoryStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 10 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open history store %s: %s", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{historySnapshotsBucket, historyInventoriesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to prepare history store %s: %s", path, err)
+	}
+
+	return &HistoryStore{db: db, persistentRuns: getHistoryPersistentRuns()}, nil
+}
+
+func (store *HistoryStore) Close() error {
+	return store.db.Close()
+}
+
+// getInventories returns up to limit previous inventories, newest first
+func (store *HistoryStore) getInventories(limit int) ([]HistoryInventory, error) {
+	var inventories []HistoryInventory
+
+	err := store.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(historyInventoriesBucket).Cursor()
+
+		for key, value := cursor.Last(); key != nil && len(inventories) < limit; key, value = cursor.Prev() {
+			var inventory HistoryInventory
+			if err := json.Unmarshal(value, &inventory); err != nil {
+				return fmt.Errorf("unable to read run %s: %s", key, err)
+			}
+
+			inventories = append(inventories, inventory)
+		}
+
+		return nil
+	})
+
+	return inventories, err
+}
+
+// Record saves a snapshot of the run and returns how it differs from the runs before it
+func (store *HistoryStore) Record(ctx *GlobalCloudContext, runAt time.Time) (*HistoryDiff, error) {
+	// Graph node IDs already include the account and region, so the same resource ID in two scopes stays distinct
+	inventory := HistoryInventory{RunAt: runAt.UTC(), Resources: ctx.OwnershipGraph.SortedNodes()}
+
+	previous, err := store.getInventories(historyRetainedRuns)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory = carryForwardUnscanned(inventory, previous, ctx.ScanErrors)
+
+	diff := getHistoryDiff(inventory, previous, store.persistentRuns)
+
+	snapshotJson, err := json.Marshal(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode snapshot: %s", err)
+	}
+
+	inventoryJson, err := json.Marshal(inventory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode inventory: %s", err)
+	}
+
+	key := []byte(inventory.RunAt.Format(historyRunKeyLayout))
+
+	err = store.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(historySnapshotsBucket).Put(key, snapshotJson); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(historyInventoriesBucket).Put(key, inventoryJson); err != nil {
+			return err
+		}
+
+		return pruneHistory(tx)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to save run %s: %s", key, err)
+	}
+
+	log.Printf("Saved run %s to history, %d new and %d removed resources since the last run, %d persistent offenders", key, len(diff.New), len(diff.Removed), len(diff.PersistentOffenders))
+	return diff, nil
+}
+
+// carryForwardUnscanned keeps the previous entries that failed to be collected this run, so their resources are not
+// reported as removed and their persistent offender streaks carry on. Enrichment and region discovery failures are
+// ignored, since the resources were still collected.
+func carryForwardUnscanned(current HistoryInventory, previous []HistoryInventory, scanErrors []ScanError) HistoryInventory {
+	if len(previous) == 0 || len(scanErrors) == 0 {
+		return current
+	}
+
+	// Keyed by account and region, with an empty region for a whole account. A nil set means every type is missing.
+	uncollected := map[string]map[NodeType]bool{}
+	for _, scanError := range scanErrors {
+		if scanError.Kind != ScanErrorCollect {
+			continue
+		}
+
+		key := scanError.Account + "/" + scanError.Region
+		nodeTypes, seen := uncollected[key]
+
+		if len(scanError.NodeTypes) == 0 || (seen && nodeTypes == nil) {
+			uncollected[key] = nil
+			continue
+		}
+
+		if !seen {
+			nodeTypes = map[NodeType]bool{}
+			uncollected[key] = nodeTypes
+		}
+
+		for _, nodeType := range scanError.NodeTypes {
+			nodeTypes[nodeType] = true
+		}
+	}
+
+	isUncollected := func(resource GraphNode) bool {
+		for _, key := range []string{resource.Account + "/", resource.Account + "/" + resource.Region} {
+			if nodeTypes, ok := uncollected[key]; ok && (nodeTypes == nil || nodeTypes[resource.Type]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	currentResources := map[string]bool{}
+	for _, resource := range current.Resources {
+		currentResources[resource.ID] = true
+	}
+
+	carried := 0
+	for _, resource := range previous[0].Resources {
+		if !currentResources[resource.ID] && isUncollected(resource) {
+			current.Resources = append(current.Resources, resource)
+			carried++
+		}
+	}
+
+	if carried > 0 {
+		log.Printf("Carried %d resources forward from the last run that failed to be collected", carried)
+		sort.Slice(current.Resources, func(i, j int) bool {
+			return current.Resources[i].ID < current.Resources[j].ID
+		})
+	}
+
+	return current
+}
+
+func pruneHistory(tx *bolt.Tx) error {
+	for _, name := range [][]byte{historySnapshotsBucket, historyInventoriesBucket} {
+		bucket := tx.Bucket(name)
+		excess := bucket.Stats().KeyN - historyRetainedRuns
+
+		// Deleting through the cursor while iterating skips keys, so the oldest keys are collected first
+		var keys [][]byte
+		cursor := bucket.Cursor()
+		for key, _ := cursor.First(); key != nil && len(keys) < excess; key, _ = cursor.Next() {
+			keys = append(keys, append([]byte(nil), key...))
+		}
+
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getHistoryDiff expects previous inventories newest first
+func getHistoryDiff(current HistoryInventory, previous []HistoryInventory, persistentRuns int) *HistoryDiff {
+	diff := &HistoryDiff{PersistentRuns: persistentRuns}
+
+	if len(previous) == 0 {
+		return diff
+	}
+
+	diff.PreviousRunAt = previous[0].RunAt
+
+	previousResources := map[string]GraphNode{}
+	for _, resource := range previous[0].Resources {
+		previousResources[resource.ID] = resource
+	}
+
+	currentResources := map[string]bool{}
+	for _, resource := range current.Resources {
+		currentResources[resource.ID] = true
+
+		if _, ok := previousResources[resource.ID]; !ok {
+			diff.New = append(diff.New, resource)
+		}
+	}
+
+	for _, resource := range previous[0].Resources {
+		if !currentResources[resource.ID] {
+			diff.Removed = append(diff.Removed, resource)
+		}
+	}
+
+	// Counts how many runs in a row, ending with this one, each resource has been reported in
+	runs := map[string]int{}
+	for _, resource := range current.Resources {
+		runs[resource.ID] = 1
+	}
+
+	for _, inventory := range previous {
+		seen := map[string]bool{}
+		for _, resource := range inventory.Resources {
+			seen[resource.ID] = true
+		}
+
+		extended := false
+		for id, count := range runs {
+			if count > 0 && seen[id] {
+				runs[id]++
+				extended = true
+			} else if count > 0 {
+				// A negative count marks a streak that has already been broken
+				runs[id] = -count
+			}
+		}
+
+		if !extended {
+			break
+		}
+	}
+
+	for _, resource := range current.Resources {
+		count := runs[resource.ID]
+		if count < 0 {
+			count = -count
+		}
+
+		if count >= persistentRuns {
+			diff.PersistentOffenders = append(diff.PersistentOffenders, PersistentResource{Resource: resource, Runs: count})
+		}
+	}
+
+	sort.SliceStable(diff.PersistentOffenders, func(i, j int) bool {
+		return diff.PersistentOffenders[i].Runs > diff.PersistentOffenders[j].Runs
+	})
+
+	return diff
+}
@@ -0,0 +1,289 @@
+package monitoring
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+const testOtherRegion = "us-east-1"
+
+func newTestHistoryNode(nodeType NodeType, account string, region string, id string) GraphNode {
+	resource := CloudResource{ID: id, Account: account, Region: region}
+	return GraphNode{ID: GetNodeId(nodeType, resource), Type: nodeType, Account: account, Region: region}
+}
+
+func newTestHistoryInventory(runAt time.Time, resources ...GraphNode) HistoryInventory {
+	return HistoryInventory{RunAt: runAt, Resources: resources}
+}
+
+func getHistoryNodeIds(resources []GraphNode) []string {
+	ids := []string{}
+	for _, resource := range resources {
+		ids = append(ids, resource.ID)
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+func TestCarryForwardUnscanned(t *testing.T) {
+	runAt := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	instance := newTestHistoryNode(NodeEC2Instance, testAccount, testRegion, "i-1")
+	volume := newTestHistoryNode(NodeEBSVolume, testAccount, testRegion, "vol-1")
+	otherRegionInstance := newTestHistoryNode(NodeEC2Instance, testAccount, testOtherRegion, "i-2")
+	otherAccountInstance := newTestHistoryNode(NodeEC2Instance, "210987654321", testRegion, "i-3")
+	couchbaseCloud := GraphNode{ID: GetNodeId(NodeCouchbaseCloud, CloudResource{ID: "cloud-1"}), Type: NodeCouchbaseCloud}
+
+	previousRun := newTestHistoryInventory(runAt.Add(-24*time.Hour), instance, volume, otherRegionInstance, otherAccountInstance, couchbaseCloud)
+
+	tests := []struct {
+		name       string
+		current    []GraphNode
+		previous   []HistoryInventory
+		scanErrors []ScanError
+		want       []GraphNode
+	}{
+		{
+			name:       "first run",
+			current:    []GraphNode{instance},
+			scanErrors: []ScanError{{Account: testAccount, Kind: ScanErrorCollect}},
+			want:       []GraphNode{instance},
+		},
+		{
+			name:     "no scan errors",
+			current:  []GraphNode{instance},
+			previous: []HistoryInventory{previousRun},
+			want:     []GraphNode{instance},
+		},
+		{
+			name:     "enrichment failures are ignored",
+			current:  []GraphNode{otherAccountInstance},
+			previous: []HistoryInventory{previousRun},
+			scanErrors: []ScanError{
+				{Account: testAccount, Region: testRegion, Kind: ScanErrorEnrich, Operation: "add CloudTrail launched by"},
+			},
+			want: []GraphNode{otherAccountInstance},
+		},
+		{
+			name:     "region discovery fallback is ignored",
+			current:  []GraphNode{otherAccountInstance},
+			previous: []HistoryInventory{previousRun},
+			scanErrors: []ScanError{
+				{Account: testAccount, Kind: ScanErrorDiscovery, Operation: "discover enabled regions, scanning default regions instead"},
+			},
+			want: []GraphNode{otherAccountInstance},
+		},
+		{
+			name:     "failed collector only carries its types in its region",
+			current:  []GraphNode{otherAccountInstance},
+			previous: []HistoryInventory{previousRun},
+			scanErrors: []ScanError{
+				{Account: testAccount, Region: testRegion, Kind: ScanErrorCollect, NodeTypes: []NodeType{NodeEC2Instance}},
+			},
+			want: []GraphNode{instance, otherAccountInstance},
+		},
+		{
+			name:     "failed collector without types carries the whole region",
+			current:  []GraphNode{otherAccountInstance},
+			previous: []HistoryInventory{previousRun},
+			scanErrors: []ScanError{
+				{Account: testAccount, Region: testRegion, Kind: ScanErrorCollect},
+			},
+			want: []GraphNode{instance, volume, otherAccountInstance},
+		},
+		{
+			name:     "failed collector without types overrides typed failures",
+			current:  []GraphNode{otherAccountInstance},
+			previous: []HistoryInventory{previousRun},
+			scanErrors: []ScanError{
+				{Account: testAccount, Region: testRegion, Kind: ScanErrorCollect, NodeTypes: []NodeType{NodeEC2Instance}},
+				{Account: testAccount, Region: testRegion, Kind: ScanErrorCollect},
+				{Account: testAccount, Region: testRegion, Kind: ScanErrorCollect, NodeTypes: []NodeType{NodeEKSCluster}},
+			},
+			want: []GraphNode{instance, volume, otherAccountInstance},
+		},
+		{
+			name:     "account that failed to be scanned carries every region",
+			current:  []GraphNode{otherAccountInstance},
+			previous: []HistoryInventory{previousRun},
+			scanErrors: []ScanError{
+				{Account: testAccount, Kind: ScanErrorCollect, Operation: "assume AWS role"},
+			},
+			want: []GraphNode{instance, volume, otherRegionInstance, otherAccountInstance},
+		},
+		{
+			name:     "account listing failure only carries its type",
+			current:  []GraphNode{volume, otherAccountInstance},
+			previous: []HistoryInventory{previousRun},
+			scanErrors: []ScanError{
+				{Account: testAccount, Kind: ScanErrorCollect, NodeTypes: []NodeType{NodeEC2Instance}},
+			},
+			want: []GraphNode{instance, volume, otherRegionInstance, otherAccountInstance},
+		},
+		{
+			name:     "resources found this run are not duplicated",
+			current:  []GraphNode{instance, otherAccountInstance},
+			previous: []HistoryInventory{previousRun},
+			scanErrors: []ScanError{
+				{Account: testAccount, Region: testRegion, Kind: ScanErrorCollect},
+			},
+			want: []GraphNode{instance, volume, otherAccountInstance},
+		},
+		{
+			name:     "only the last run is carried forward",
+			current:  []GraphNode{otherAccountInstance},
+			previous: []HistoryInventory{newTestHistoryInventory(runAt.Add(-24 * time.Hour)), previousRun},
+			scanErrors: []ScanError{
+				{Account: testAccount, Kind: ScanErrorCollect},
+			},
+			want: []GraphNode{otherAccountInstance},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			current := newTestHistoryInventory(runAt, append([]GraphNode(nil), test.current...)...)
+
+			got := carryForwardUnscanned(current, test.previous, test.scanErrors)
+
+			if !got.RunAt.Equal(runAt) {
+				t.Errorf("expected the run time to be kept, got %s", got.RunAt)
+			}
+
+			if gotIds, wantIds := getHistoryNodeIds(got.Resources), getHistoryNodeIds(test.want); !reflect.DeepEqual(gotIds, wantIds) {
+				t.Errorf("resources = %v, want %v", gotIds, wantIds)
+			}
+		})
+	}
+}
+
+func TestGetHistoryDiff(t *testing.T) {
+	runAt := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	a := newTestHistoryNode(NodeEC2Instance, testAccount, testRegion, "i-a")
+	b := newTestHistoryNode(NodeEC2Instance, testAccount, testRegion, "i-b")
+	c := newTestHistoryNode(NodeEBSVolume, testAccount, testRegion, "vol-c")
+	d := newTestHistoryNode(NodeEBSVolume, testAccount, testOtherRegion, "vol-d")
+
+	// getPrevious returns inventories newest first, one run a day before runAt
+	getPrevious := func(runs ...[]GraphNode) []HistoryInventory {
+		var inventories []HistoryInventory
+		for idx, resources := range runs {
+			inventories = append(inventories, newTestHistoryInventory(runAt.Add(-time.Duration(idx+1)*24*time.Hour), resources...))
+		}
+
+		return inventories
+	}
+
+	tests := []struct {
+		name           string
+		current        []GraphNode
+		previous       []HistoryInventory
+		persistentRuns int
+		wantNew        []GraphNode
+		wantRemoved    []GraphNode
+		wantOffenders  map[string]int
+	}{
+		{
+			name:           "first run",
+			current:        []GraphNode{a, b},
+			persistentRuns: 2,
+		},
+		{
+			name:           "new and removed resources",
+			current:        []GraphNode{a, c},
+			previous:       getPrevious([]GraphNode{a, b}),
+			persistentRuns: 3,
+			wantNew:        []GraphNode{c},
+			wantRemoved:    []GraphNode{b},
+		},
+		{
+			name:           "streak reaching the persistent runs",
+			current:        []GraphNode{a, b},
+			previous:       getPrevious([]GraphNode{a, b}, []GraphNode{a}),
+			persistentRuns: 3,
+			wantOffenders:  map[string]int{a.ID: 3},
+		},
+		{
+			name:           "streak covering every run",
+			current:        []GraphNode{a},
+			previous:       getPrevious([]GraphNode{a}, []GraphNode{a}, []GraphNode{a}),
+			persistentRuns: 2,
+			wantOffenders:  map[string]int{a.ID: 4},
+		},
+		{
+			name:           "gap breaks the streak",
+			current:        []GraphNode{a, b},
+			previous:       getPrevious([]GraphNode{a, b}, []GraphNode{a}, []GraphNode{a, b}, []GraphNode{a, b}),
+			persistentRuns: 3,
+			wantOffenders:  map[string]int{a.ID: 5},
+		},
+		{
+			name:           "streak ending before this run does not count",
+			current:        []GraphNode{a},
+			previous:       getPrevious([]GraphNode{b}, []GraphNode{a, b}, []GraphNode{a, b}),
+			persistentRuns: 2,
+			wantNew:        []GraphNode{a},
+			wantRemoved:    []GraphNode{b},
+			wantOffenders:  map[string]int{},
+		},
+		{
+			name:           "streaks of every length",
+			current:        []GraphNode{a, b, c, d},
+			previous:       getPrevious([]GraphNode{a, b, c}, []GraphNode{a, b}, []GraphNode{b}),
+			persistentRuns: 2,
+			wantNew:        []GraphNode{d},
+			wantOffenders:  map[string]int{a.ID: 3, b.ID: 4, c.ID: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff := getHistoryDiff(newTestHistoryInventory(runAt, test.current...), test.previous, test.persistentRuns)
+
+			if diff.PersistentRuns != test.persistentRuns {
+				t.Errorf("persistent runs = %d, want %d", diff.PersistentRuns, test.persistentRuns)
+			}
+
+			if len(test.previous) == 0 {
+				if !diff.PreviousRunAt.IsZero() || len(diff.New) != 0 || len(diff.Removed) != 0 || len(diff.PersistentOffenders) != 0 {
+					t.Errorf("expected an empty diff on the first run, got %+v", diff)
+				}
+				return
+			}
+
+			if !diff.PreviousRunAt.Equal(test.previous[0].RunAt) {
+				t.Errorf("previous run = %s, want %s", diff.PreviousRunAt, test.previous[0].RunAt)
+			}
+
+			if got, want := getHistoryNodeIds(diff.New), getHistoryNodeIds(test.wantNew); !reflect.DeepEqual(got, want) {
+				t.Errorf("new = %v, want %v", got, want)
+			}
+
+			if got, want := getHistoryNodeIds(diff.Removed), getHistoryNodeIds(test.wantRemoved); !reflect.DeepEqual(got, want) {
+				t.Errorf("removed = %v, want %v", got, want)
+			}
+
+			offenders := map[string]int{}
+			for idx, offender := range diff.PersistentOffenders {
+				offenders[offender.Resource.ID] = offender.Runs
+
+				if idx > 0 && diff.PersistentOffenders[idx-1].Runs < offender.Runs {
+					t.Errorf("expected persistent offenders with the longest streaks first, got %+v", diff.PersistentOffenders)
+				}
+			}
+
+			wantOffenders := test.wantOffenders
+			if wantOffenders == nil {
+				wantOffenders = map[string]int{}
+			}
+
+			if !reflect.DeepEqual(offenders, wantOffenders) {
+				t.Errorf("persistent offenders = %v, want %v", offenders, wantOffenders)
+			}
+		})
+	}
+}
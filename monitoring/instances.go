@@ -0,0 +1,55 @@
+package monitoring
+
+import (
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// StateTransitionReason looks like "User initiated (2021-06-08 10:12:05 GMT)"
+var stateTransitionTimeRegexp = regexp.MustCompile(`\((\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) GMT\)`)
+
+const stateTransitionTimeLayout = "2006-01-02 15:04:05"
+
+func (ec2Instance EC2Instance) IsStopped() bool {
+	return ec2Instance.State == ec2.InstanceStateNameStopped || ec2Instance.State == ec2.InstanceStateNameStopping
+}
+
+// getStateTransitionTime returns a zero time when the reason does not include one
+func getStateTransitionTime(stateTransitionReason string) time.Time {
+	match := stateTransitionTimeRegexp.FindStringSubmatch(stateTransitionReason)
+	if match == nil {
+		return time.Time{}
+	}
+
+	transitionTime, err := time.Parse(stateTransitionTimeLayout, match[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	return transitionTime
+}
+
+// GetStoppedEC2Instances returns stopped instances that are not claimed by anything else, longest stopped first
+func (ctx *GlobalCloudContext) GetStoppedEC2Instances() []EC2Instance {
+	var ec2Instances []EC2Instance
+
+	for _, regionalCtx := range ctx.RegionalCloudContexts {
+		for _, ec2Instance := range regionalCtx.EC2Instances {
+			if ec2Instance.IsStopped() {
+				ec2Instances = append(ec2Instances, ec2Instance)
+			}
+		}
+	}
+
+	sort.SliceStable(ec2Instances, func(i, j int) bool {
+		if ec2Instances[i].StoppedAt.IsZero() != ec2Instances[j].StoppedAt.IsZero() {
+			return ec2Instances[i].StoppedAt.IsZero()
+		}
+
+		return ec2Instances[i].StoppedAt.Before(ec2Instances[j].StoppedAt)
+	})
+
+	return ec2Instances
+}
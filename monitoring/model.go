@@ -0,0 +1,80 @@
+package monitoring
+
+// The provider neutral resource model. Provider types such as EC2Instance, AzureVirtualMachine and GCPInstance embed
+// one of these and only add what is specific to their cloud, so claims and views can treat them alike.
+
+// Compute is a virtual machine. VolumeIDs are the IDs of the volumes attached to it.
+type Compute struct {
+	CloudResource
+	InstanceType string
+	State        string
+	SubnetID     string
+	VolumeIDs    []string
+}
+
+type Volume struct {
+	CloudResource
+	VolumeType string
+	SizeGiB    int64
+	State      string
+}
+
+type KubernetesCluster struct {
+	CloudResource
+	Version   string
+	State     string
+	NetworkID string
+	NodeCount int
+}
+
+// StackResource is an entry in the resource list of a stack. Type is the provider's own resource type name, such as
+// AWS::EC2::Instance, and PhysicalID is the ID of the resource it created.
+type StackResource struct {
+	LogicalID  string
+	PhysicalID string
+	Type       string
+	Status     string
+}
+
+// Stack is a set of resources created together from a template, such as a Cloudformation stack
+type Stack struct {
+	CloudResource
+	Resources []StackResource
+}
+
+type Network struct {
+	CloudResource
+	CIDR string
+}
+
+// DataStore is a managed database or search service. StorageGiB is zero when the service does not allocate storage up
+// front, such as Aurora or ElastiCache.
+type DataStore struct {
+	CloudResource
+	Engine        string
+	EngineVersion string
+	InstanceClass string
+	StorageGiB    int64
+	MultiAZ       bool
+	State         string
+}
+
+func (compute Compute) GetCompute() Compute {
+	return compute
+}
+
+func (stack Stack) GetStack() Stack {
+	return stack
+}
+
+// ComputeClaimer is implemented by the provider instance types, which claim the volumes attached to them
+type ComputeClaimer interface {
+	CloudResourceClaimer
+	GetCompute() Compute
+}
+
+// StackClaimer is implemented by the provider stack types, which claim the resources they list
+type StackClaimer interface {
+	CloudResourceClaimer
+	GetStack() Stack
+}
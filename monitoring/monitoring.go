@@ -28,119 +28,29 @@ const awsSessionName = "cloud-monitoring-tool"
 const ec2EksClusterNameTag = "cluster"
 const cloudformationEc2StackResourceId = "AWS::EC2::Instance"
 
-var regions = []string{
-	"us-east-1", "us-east-2", "us-west-2", "eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1", "eu-north-1",
-	"ca-central-1", "us-west-1", "ap-south-1", "ap-northeast-2", "ap-southeast-1", "ap-southeast-2", "ap-northeast-1",
-}
-
-func processEC2Claims(ctx *RegionalCloudContext) {
-	ebsCountBefore := len(ctx.EBSVolumes)
-
-	for _, ec2Instance := range ctx.EC2Instances {
-		for _, blockDevice := range ec2Instance.InstanceBlockDeviceMappings {
-			ec2VolumeId := *blockDevice.Ebs.VolumeId
-
-			if ebsVolume, ok := ctx.EBSVolumes[ec2VolumeId]; ok {
-				ec2Instance.Claim(ctx, ebsVolume)
-			}
-		}
-	}
-
-	ebsCountAfter := len(ctx.EBSVolumes)
-	log.Printf("Processed EC2 claims (%d EBS volumes)", ebsCountBefore-ebsCountAfter)
-}
-
-func processCouchbaseCloudClusterClaims(ctx *RegionalCloudContext) {
-	ec2InstancesByClusterId := ctx.GetEC2InstancesByClusterId()
-	ec2CountBefore := len(ctx.EC2Instances)
-
-	for _, couchbaseCloudCluster := range ctx.CouchbaseCloudClusters {
-		clusterId := couchbaseCloudCluster.ID
-
-		if ec2Instances, ok := ec2InstancesByClusterId[clusterId]; ok {
-			for _, ec2Instance := range ec2Instances {
-				if eksClusterName, ok := ec2Instance.Tags[ec2EksClusterNameTag]; ok {
-					couchbaseCloudCluster.EKSClusterName = eksClusterName
-				}
-
-				couchbaseCloudCluster.Claim(ctx, ec2Instance)
-			}
-		}
-	}
-
-	ec2CountAfter := len(ctx.EC2Instances)
-	log.Printf("Processed Couchbase Cloud Cluster claims (%d EC2 instances)", ec2CountBefore-ec2CountAfter)
-}
-
-func processEKSClusterClaims(ctx *RegionalCloudContext) {
-	couchbaseCloudClustersByEKSName := ctx.GetCouchbaseCloudClustersByEKSName()
-	ec2InstancesBySubnetId := ctx.GetEC2InstancesBySubnetId()
-	cbcCountBefore := len(ctx.CouchbaseCloudClusters)
-	ec2CountBefore := len(ctx.EC2Instances)
-
-	for _, eksCluster := range ctx.EKSClusters {
-		eksName := eksCluster.Name
-
-		if couchbaseClusters, ok := couchbaseCloudClustersByEKSName[eksName]; ok {
-			for _, couchbaseCluster := range couchbaseClusters {
-				eksCluster.Claim(ctx, couchbaseCluster)
+func collectRegion(scope *ScanScope, collectors []Collector) (*RegionalCloudContext, []ScanError) {
+	ctx := NewRegionalCloudContext(scope.Account, scope.Region)
+	var scanErrors []ScanError
+
+	for _, collector := range collectors {
+		if err := collector.Collect(scope, ctx); err != nil {
+			scanError := ScanError{
+				Account:   scope.Account,
+				Region:    scope.Region,
+				Kind:      ScanErrorCollect,
+				Operation: fmt.Sprintf("get %s", collector.Name()),
+				Message:   err.Error(),
 			}
-		}
-
-		// Linking EKS clusters to EC2 instances directly and reliably is not possible without K8S permissions.
-		// Assume all EC2 instances within subnets associated with EKS cluster belong to it
-		for _, eksSubnetId := range eksCluster.Subnets {
-			if ec2Instances, ok := ec2InstancesBySubnetId[*eksSubnetId]; ok {
-				for _, ec2Instance := range ec2Instances {
-					eksCluster.Claim(ctx, ec2Instance)
-				}
-			}
-		}
-
-	}
-
-	cbcCountAfter := len(ctx.CouchbaseCloudClusters)
-	ec2CountAfter := len(ctx.EC2Instances)
-	log.Printf("Processed EKS Cluster claims (%d EC2 instances, %d CBC clusters)", ec2CountBefore-ec2CountAfter, cbcCountBefore-cbcCountAfter)
-}
-
-func processCloudformationStackClaims(ctx *RegionalCloudContext) {
-	ec2CountBefore := len(ctx.EC2Instances)
 
-	for _, cloudformationStack := range ctx.CloudFormationStacks {
-		for _, stackResource := range cloudformationStack.StackResourceList {
-			switch *stackResource.ResourceType {
-			case cloudformationEc2StackResourceId:
-				if ec2Instance, ok := ctx.EC2Instances[*stackResource.PhysicalResourceId]; ok {
-					cloudformationStack.Claim(ctx, ec2Instance)
-				}
+			if nodeTypeCollector, ok := collector.(NodeTypeCollector); ok {
+				scanError.NodeTypes = nodeTypeCollector.NodeTypes()
 			}
-		}
-	}
-
-	ec2CountAfter := len(ctx.EC2Instances)
-	log.Printf("Processed Cloudformation Stack claims (%d EC2 instances)", ec2CountBefore-ec2CountAfter)
-}
-
-func processCouchbaseCloudClaims(ctx *RegionalCloudContext) {
-	eksClustersByCloudId := ctx.GetEKSClustersByCloudId()
-	cloudformationStacksByCloudId := ctx.GetCloudformationStacksByCloudId()
-	eksCountBefore := len(ctx.EKSClusters)
-	cfStackCountBefore := len(ctx.CloudFormationStacks)
 
-	for _, couchbaseCloud := range ctx.CouchbaseClouds {
-		if eksCluster, ok := eksClustersByCloudId[couchbaseCloud.ID]; ok {
-			couchbaseCloud.Claim(ctx, eksCluster)
-		}
-
-		if cloudformationStack, ok := cloudformationStacksByCloudId[couchbaseCloud.ID]; ok {
-			couchbaseCloud.Claim(ctx, cloudformationStack)
+			scanErrors = append(scanErrors, scanError)
 		}
 	}
 
-	eksCountAfter := len(ctx.EKSClusters)
-	cfStackCountAfter := len(ctx.CloudFormationStacks)
-	log.Printf("Processed Couchbase Cloud claims (%d EKS clusters, %d CF stacks)", eksCountBefore-eksCountAfter, cfStackCountBefore-cfStackCountAfter)
+	return ctx, scanErrors
 }
 
 func assumeRole(role string, sess *session.Session, roleSessionName string) (*sts.Credentials, error) {
@@ -295,6 +205,7 @@ func getEBSVolumes(ec2Svc *ec2.EC2, account string, region string) (map[string]E
 			id := *volume.VolumeId
 			ebsVolume := NewEBSVolume()
 			ebsVolume.ID = id
+			ebsVolume.Provider = ProviderAWS
 			ebsVolume.Account = account
 			ebsVolume.Region = region
 
@@ -310,19 +221,9 @@ func getEBSVolumes(ec2Svc *ec2.EC2, account string, region string) (map[string]E
 				ebsVolume.State = *volume.State
 			}
 
-			ebsVolume.Type = volume.VolumeType
-
-			volumeTags := map[string]string{}
-
-			for _, tag := range volume.Tags {
-				if tag == nil || tag.Key == nil {
-					continue
-				}
-
-				volumeTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
-			}
-
-			ebsVolume.Tags = volumeTags
+			ebsVolume.VolumeType = aws.StringValue(volume.VolumeType)
+			ebsVolume.SnapshotID = aws.StringValue(volume.SnapshotId)
+			ebsVolume.Tags = getEC2Tags(volume.Tags)
 
 			if name, ok := ebsVolume.Tags["Name"]; ok {
 				ebsVolume.Name = name
@@ -338,7 +239,7 @@ func getEBSVolumes(ec2Svc *ec2.EC2, account string, region string) (map[string]E
 		return nil, fmt.Errorf("failed to get EBS volumes %w", err)
 	}
 
-	log.Printf("Found %d EBS volumes", len(ebsVolumes))
+	log.Printf("Found %d EBS volumes in account %s region %s", len(ebsVolumes), account, region)
 	return ebsVolumes, nil
 }
 
@@ -352,12 +253,25 @@ func getEC2Instances(ec2Service *ec2.EC2, account string, region string) (map[st
 	err := ec2Service.DescribeInstancesPages(input, func(output *ec2.DescribeInstancesOutput, lastPage bool) bool {
 		for _, reservation := range output.Reservations {
 			for _, instanceDescription := range reservation.Instances {
+				state := ""
+				if instanceDescription.State != nil {
+					state = aws.StringValue(instanceDescription.State.Name)
+				}
+
+				// Terminated instances stay visible for about an hour but are no longer billed
+				if state == ec2.InstanceStateNameTerminated {
+					continue
+				}
+
 				id := *instanceDescription.InstanceId
 				ec2Instance := NewEC2Instance()
+				ec2Instance.State = state
+				ec2Instance.StateTransitionReason = aws.StringValue(instanceDescription.StateTransitionReason)
 				ec2Instance.ID = id
+				ec2Instance.Provider = ProviderAWS
 				ec2Instance.Account = account
 				ec2Instance.Region = region
-				ec2Instance.InstanceBlockDeviceMappings = instanceDescription.BlockDeviceMappings
+				ec2Instance.VolumeIDs = getEC2VolumeIDs(instanceDescription.BlockDeviceMappings)
 
 				if instanceDescription.SubnetId != nil {
 					ec2Instance.SubnetID = *instanceDescription.SubnetId
@@ -375,17 +289,11 @@ func getEC2Instances(ec2Service *ec2.EC2, account string, region string) (map[st
 					ec2Instance.CreatedAt = *instanceDescription.LaunchTime
 				}
 
-				ec2Tags := map[string]string{}
-
-				for _, tag := range instanceDescription.Tags {
-					if tag == nil || tag.Key == nil {
-						continue
-					}
-
-					ec2Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+				if ec2Instance.IsStopped() {
+					ec2Instance.StoppedAt = getStateTransitionTime(ec2Instance.StateTransitionReason)
 				}
 
-				ec2Instance.Tags = ec2Tags
+				ec2Instance.Tags = getEC2Tags(instanceDescription.Tags)
 
 				if name, ok := ec2Instance.Tags["Name"]; ok {
 					ec2Instance.Name = name
@@ -401,7 +309,7 @@ func getEC2Instances(ec2Service *ec2.EC2, account string, region string) (map[st
 		return nil, fmt.Errorf("failed to get instances in the VPC %w", err)
 	}
 
-	log.Printf("Found %d EC2 instances", len(ec2Instances))
+	log.Printf("Found %d EC2 instances in account %s region %s", len(ec2Instances), account, region)
 	return ec2Instances, nil
 }
 
@@ -438,15 +346,18 @@ func getEKSClusters(sess *session.Session, awsCredentials *sts.Credentials, acco
 
 		now := time.Now()
 		eksCluster := NewEKSCluster()
+		eksCluster.Provider = ProviderAWS
 		eksCluster.Account = account
 		eksCluster.Region = region
+		eksCluster.Version = aws.StringValue(clusterDescription.Cluster.Version)
+		eksCluster.State = aws.StringValue(clusterDescription.Cluster.Status)
 
 		if clusterDescription.Cluster.Name != nil {
 			eksCluster.Name = *clusterDescription.Cluster.Name
 		}
 
 		if clusterDescription.Cluster.ResourcesVpcConfig.VpcId != nil {
-			eksCluster.VpcId = *clusterDescription.Cluster.ResourcesVpcConfig.VpcId
+			eksCluster.NetworkID = *clusterDescription.Cluster.ResourcesVpcConfig.VpcId
 		}
 
 		if clusterDescription.Cluster.CreatedAt != nil {
@@ -454,7 +365,7 @@ func getEKSClusters(sess *session.Session, awsCredentials *sts.Credentials, acco
 			eksCluster.CreatedAt = *clusterDescription.Cluster.CreatedAt
 		}
 
-		eksCluster.Subnets = clusterDescription.Cluster.ResourcesVpcConfig.SubnetIds
+		eksCluster.SubnetIDs = aws.StringValueSlice(clusterDescription.Cluster.ResourcesVpcConfig.SubnetIds)
 
 		eksTags := map[string]string{}
 
@@ -465,7 +376,7 @@ func getEKSClusters(sess *session.Session, awsCredentials *sts.Credentials, acco
 		eksCluster.Tags = eksTags
 		eksClustersMap[eksCluster.Name] = *eksCluster
 
-		log.Printf("Found EKS cluster: %s in %s", eksCluster.Name, eksCluster.VpcId)
+		log.Printf("Found EKS cluster: %s in %s", eksCluster.Name, eksCluster.NetworkID)
 	}
 
 	return eksClustersMap, nil
@@ -491,6 +402,7 @@ func getCloudformationStacks(sess *session.Session, awsCredentials *sts.Credenti
 
 	for _, stackDescription := range result.Stacks {
 		cloudformationStack := NewCloudFormationStack()
+		cloudformationStack.Provider = ProviderAWS
 		cloudformationStack.Account = account
 
 		if stackDescription.StackId != nil {
@@ -509,23 +421,29 @@ func getCloudformationStacks(sess *session.Session, awsCredentials *sts.Credenti
 		cloudformationStack.Region = region
 		cloudformationStack.Parameters = getCloudformationStackParameters(stackDescription)
 
+		stackTags := map[string]string{}
+		for _, tag := range stackDescription.Tags {
+			stackTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+		cloudformationStack.Tags = stackTags
+
 		stackResourceList, err := getCloudformationStackResourceList(cloudformationService, cloudformationStack.Name)
 
 		if err != nil {
 			log.Println(err)
 		} else {
-			cloudformationStack.StackResourceList = stackResourceList
+			cloudformationStack.Resources = stackResourceList
 		}
 
 		cloudformationStacksMap[cloudformationStack.ID] = *cloudformationStack
 	}
 
-	log.Printf("Found %d Cloudformation stacks", len(cloudformationStacksMap))
+	log.Printf("Found %d Cloudformation stacks in account %s region %s", len(cloudformationStacksMap), account, region)
 	return cloudformationStacksMap, nil
 }
 
-func getCloudformationStackResourceList(cloudformationService *cloudformation.CloudFormation, cloudformationStackName string) ([]*cloudformation.StackResourceSummary, error) {
-	var stackResourceSummaries []*cloudformation.StackResourceSummary
+func getCloudformationStackResourceList(cloudformationService *cloudformation.CloudFormation, cloudformationStackName string) ([]StackResource, error) {
+	var stackResources []StackResource
 
 	listStacksInput := &cloudformation.ListStackResourcesInput{
 		StackName: &cloudformationStackName,
@@ -533,7 +451,7 @@ func getCloudformationStackResourceList(cloudformationService *cloudformation.Cl
 
 	err := cloudformationService.ListStackResourcesPages(listStacksInput, func(listStacksOutput *cloudformation.ListStackResourcesOutput, lastPage bool) bool {
 		for _, stackResourceSummary := range listStacksOutput.StackResourceSummaries {
-			stackResourceSummaries = append(stackResourceSummaries, stackResourceSummary)
+			stackResources = append(stackResources, newCloudformationStackResource(stackResourceSummary))
 		}
 		return !lastPage
 	})
@@ -542,7 +460,7 @@ func getCloudformationStackResourceList(cloudformationService *cloudformation.Cl
 		return nil, fmt.Errorf("unable to list stack resource summaries for Cloudformation stack %s: %s", cloudformationStackName, err)
 	}
 
-	return stackResourceSummaries, nil
+	return stackResources, nil
 }
 
 func getCloudformationStackParameters(stackDescription *cloudformation.Stack) map[string]string {
@@ -558,6 +476,16 @@ func getCloudformationStackParameters(stackDescription *cloudformation.Stack) ma
 	return parameters
 }
 
+func getAWSConfig(awsCredentials *sts.Credentials, region string) *aws.Config {
+	return &aws.Config{
+		Credentials: credentials.NewStaticCredentials(
+			aws.StringValue(awsCredentials.AccessKeyId),
+			aws.StringValue(awsCredentials.SecretAccessKey),
+			aws.StringValue(awsCredentials.SessionToken),
+		),
+		Region: aws.String(region)}
+}
+
 func getEC2Service(sess *session.Session, awsCredentials *sts.Credentials, region string) *ec2.EC2 {
 	ec2Svc := ec2.New(sess, &aws.Config{
 		Credentials: credentials.NewStaticCredentials(
@@ -666,7 +594,54 @@ func getCouchbaseCloudData(accessKeys []string, secretKeys []string) (map[string
 	return clouds, clusters, nil
 }
 
+func getAWSRoleArns() []string {
+	var roleArns []string
+
+	for _, roleArn := range split(os.Getenv(awsRoleArns)) {
+		if roleArn = strings.TrimSpace(roleArn); roleArn != "" {
+			roleArns = append(roleArns, roleArn)
+		}
+	}
+
+	return roleArns
+}
+
+func AWSConfigured() bool {
+	return len(getAWSRoleArns()) > 0
+}
+
+// Analyse scans every configured provider into a single context so Couchbase Clouds on any provider can be claimed
+func Analyse() (*GlobalCloudContext, error) {
+	var providers []string
+
+	if AWSConfigured() {
+		providers = append(providers, ProviderAWS)
+	}
+
+	if AzureConfigured() {
+		providers = append(providers, ProviderAzure)
+	}
+
+	if GCPConfigured() {
+		providers = append(providers, ProviderGCP)
+	}
+
+	return analyseProviders(providers)
+}
+
 func AnalyseAWS() (*GlobalCloudContext, error) {
+	return analyseProviders([]string{ProviderAWS})
+}
+
+func AnalyseAzure() (*GlobalCloudContext, error) {
+	return analyseProviders([]string{ProviderAzure})
+}
+
+func AnalyseGCP() (*GlobalCloudContext, error) {
+	return analyseProviders([]string{ProviderGCP})
+}
+
+func analyseProviders(providers []string) (*GlobalCloudContext, error) {
 	cbcAccessKeys := split(os.Getenv(cbcApiAccessKeysEnv))
 	cbcSecretKeys := split(os.Getenv(cbcApiSecretKeysEnv))
 
@@ -676,7 +651,7 @@ func AnalyseAWS() (*GlobalCloudContext, error) {
 		return nil, err
 	}
 
-	couchbaseCloudsCtx, couchbaseCloudClustersCtx, err := deepCopyCouchbaseCloudData(couchbaseClouds, couchbaseCloudClusters)
+	priceCatalog, err := getPriceCatalog()
 
 	if err != nil {
 		return nil, err
@@ -686,71 +661,111 @@ func AnalyseAWS() (*GlobalCloudContext, error) {
 	globalCtx.CouchbaseClouds = couchbaseClouds
 	globalCtx.CouchbaseCloudClusters = couchbaseCloudClusters
 
+	var scopes []*ScanScope
+
+	for _, provider := range providers {
+		switch provider {
+		case ProviderAWS:
+			scopes = append(scopes, getAWSScopes(globalCtx)...)
+		case ProviderAzure:
+			scopes = append(scopes, getAzureScopes(globalCtx)...)
+		case ProviderGCP:
+			scopes = append(scopes, getGCPScopes(globalCtx)...)
+		}
+	}
+
+	scanner := &regionScanner{
+		collectors: map[string][]Collector{
+			ProviderAWS:   RegisteredCollectors(),
+			ProviderAzure: RegisteredAzureCollectors(),
+			ProviderGCP:   RegisteredGCPCollectors(),
+		},
+		enrichers: map[string][]Enricher{
+			ProviderAWS:   append(RegisteredEnrichers(), NewUtilisationEnricher(), NewCostEnricher(priceCatalog), NewExpiryEnricher(getExpiryPolicy())),
+			ProviderAzure: {NewCostEnricher(priceCatalog), NewExpiryEnricher(getExpiryPolicy())},
+			ProviderGCP:   {NewCostEnricher(priceCatalog), NewExpiryEnricher(getExpiryPolicy())},
+		},
+		couchbaseClouds:        couchbaseClouds,
+		couchbaseCloudClusters: couchbaseCloudClusters,
+		globalCtx:              globalCtx,
+	}
+
+	scanner.run(scopes, getScanWorkers())
+
+	return globalCtx, nil
+}
+
+// getAWSScopes returns a scope for every enabled region of every role's account. Accounts that cannot be reached are
+// reported as scan errors so the other providers are still scanned.
+func getAWSScopes(globalCtx *GlobalCloudContext) []*ScanScope {
+	awsRoleArns := getAWSRoleArns()
+
+	addAccountScanErrors := func(operation string, err error) {
+		for _, awsRoleArn := range awsRoleArns {
+			globalCtx.AddScanError(ScanError{
+				Account:   getStringInBetween(awsRoleArn, "arn:aws:iam::", ":"),
+				Kind:      ScanErrorCollect,
+				Operation: operation,
+				Message:   err.Error(),
+			})
+		}
+	}
+
 	awsSession, err := session.NewSession()
 	if err != nil {
-		return nil, fmt.Errorf("unable to create AWS session: %s", err)
+		addAccountScanErrors("create AWS session", err)
+		return nil
 	}
 
 	callerId, err := getCallerId(awsSession)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get AWS Caller ID: %s", err)
+		addAccountScanErrors("get AWS Caller ID", err)
+		return nil
 	}
 
-	awsRoleArns := split(os.Getenv(awsRoleArns))
+	regionFilter := getRegionFilter()
+	var scopes []*ScanScope
 
 	for _, awsRoleArn := range awsRoleArns {
 		log.Printf("Assuming role %s", awsRoleArn)
+		account := getStringInBetween(awsRoleArn, "arn:aws:iam::", ":")
 		awsCredentials, err := assumeRole(awsRoleArn, awsSession, fmt.Sprintf("%s-%v", awsSessionName, callerId))
 
 		if err != nil {
-			return nil, fmt.Errorf("unable to assume AWS role: %s", err)
+			globalCtx.AddScanError(ScanError{
+				Account:   account,
+				Kind:      ScanErrorCollect,
+				Operation: fmt.Sprintf("assume AWS role %s", awsRoleArn),
+				Message:   err.Error(),
+			})
+			continue
 		}
 
-		account := getStringInBetween(awsRoleArn, "arn:aws:iam::", ":")
-
-		for _, region := range regions {
-			log.Printf("Analysing AWS %s", region)
-
-			ec2Service := getEC2Service(awsSession, awsCredentials, region)
-
-			ebsVolumes, err := getEBSVolumes(ec2Service, account, region)
-			if err != nil {
-				return nil, fmt.Errorf("unable to get EBS Volumes in account: %s, region: %s. %s", account, region, err)
-			}
-
-			ec2Instances, err := getEC2Instances(ec2Service, account, region)
-			if err != nil {
-				return nil, fmt.Errorf("unable to get EC2 Instances in account: %s, region: %s. %s", account, region, err)
-			}
-
-			eksClusters, err := getEKSClusters(awsSession, awsCredentials, account, region)
-			if err != nil {
-				return nil, fmt.Errorf("unable to get EKS clusters in account: %s, region: %s. %s", account, region, err)
-			}
-
-			cloudformationStacks, err := getCloudformationStacks(awsSession, awsCredentials, account, region)
-			if err != nil {
-				return nil, fmt.Errorf("unable to get Cloudformation stacks in account: %s, region: %s. %s", account, region, err)
-			}
-
-			ctx := NewRegionalCloudContext(account, region)
-
-			ctx.EBSVolumes = ebsVolumes
-			ctx.EC2Instances = ec2Instances
-			ctx.CouchbaseCloudClusters = couchbaseCloudClustersCtx
-			ctx.EKSClusters = eksClusters
-			ctx.CloudFormationStacks = cloudformationStacks
-			ctx.CouchbaseClouds = couchbaseCloudsCtx
+		regions, err := getEnabledRegions(awsSession, awsCredentials)
+		if err != nil {
+			globalCtx.AddScanError(ScanError{
+				Account:   account,
+				Kind:      ScanErrorDiscovery,
+				Operation: "discover enabled regions, scanning default regions instead",
+				Message:   err.Error(),
+			})
+			regions = fallbackRegions
+		}
 
-			processEC2Claims(ctx)
-			processCouchbaseCloudClusterClaims(ctx)
-			processEKSClusterClaims(ctx)
-			processCloudformationStackClaims(ctx)
-			processCouchbaseCloudClaims(ctx)
+		regions = regionFilter.Apply(regions)
+		globalCtx.AccountRegions[account] = regions
+		log.Printf("Scanning %d regions in account %s", len(regions), account)
 
-			globalCtx.Add(*ctx)
+		for _, region := range regions {
+			scopes = append(scopes, &ScanScope{
+				Provider:    ProviderAWS,
+				Session:     awsSession,
+				Credentials: awsCredentials,
+				Account:     account,
+				Region:      region,
+			})
 		}
 	}
 
-	return globalCtx, nil
+	return scopes
 }
@@ -0,0 +1,412 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ResourceTypeVPC          = "VPCs"
+	ResourceTypeLoadBalancer = "Load balancers"
+	ResourceTypeNATGateway   = "NAT gateways"
+	ResourceTypeElasticIP    = "Elastic IPs"
+)
+
+const (
+	NodeVPC          NodeType = "vpc"
+	NodeLoadBalancer NodeType = "load-balancer"
+	NodeNATGateway   NodeType = "nat-gateway"
+	NodeElasticIP    NodeType = "elastic-ip"
+)
+
+const (
+	EdgeCreatedByEKS EdgeType = "created-by-eks"
+	EdgeInVPC        EdgeType = "in-vpc"
+)
+
+// Classic load balancers are reported alongside the ELBv2 application, network and gateway types
+const LoadBalancerTypeClassic = "classic"
+
+// The AWS load balancer controller and the in-tree service controller tag the load balancers of Kubernetes services
+// with the name of their cluster
+const eksLoadBalancerClusterTagPrefix = "kubernetes.io/cluster/"
+
+const (
+	cloudformationVPCStackResourceId                 = "AWS::EC2::VPC"
+	cloudformationNATGatewayStackResourceId          = "AWS::EC2::NatGateway"
+	cloudformationLoadBalancerStackResourceId        = "AWS::ElasticLoadBalancingV2::LoadBalancer"
+	cloudformationClassicLoadBalancerStackResourceId = "AWS::ElasticLoadBalancing::LoadBalancer"
+)
+
+// VPC includes the default VPC every region has, so that NAT gateways created in it are claimed too. VPCs are only
+// reported when they claim a NAT gateway or are claimed by a stack.
+type VPC struct {
+	Network
+	State       string
+	IsDefault   bool
+	NATGateways map[string]NATGateway
+}
+
+// LoadBalancer is identified by its ARN, or by its name for classic load balancers, as in Cloudformation stacks.
+// TargetHealthUnknown is set when the targets could not be read, the target counts are then left at zero.
+type LoadBalancer struct {
+	CloudResource
+	Type                string
+	Scheme              string
+	State               string
+	VPCID               string
+	DNSName             string
+	TargetCount         int
+	HealthyTargetCount  int
+	TargetHealthUnknown bool
+}
+
+type NATGateway struct {
+	CloudResource
+	State     string
+	VPCID     string
+	SubnetID  string
+	PublicIPs []string
+}
+
+// ElasticIP is identified by its allocation ID. The API does not return an allocation time.
+type ElasticIP struct {
+	CloudResource
+	PublicIP           string
+	AssociationID      string
+	InstanceID         string
+	NetworkInterfaceID string
+}
+
+func NewVPC() *VPC {
+	return &VPC{
+		NATGateways: make(map[string]NATGateway),
+	}
+}
+
+func NewLoadBalancer() *LoadBalancer {
+	return &LoadBalancer{}
+}
+
+func NewNATGateway() *NATGateway {
+	return &NATGateway{}
+}
+
+func NewElasticIP() *ElasticIP {
+	return &ElasticIP{}
+}
+
+func (vpc VPC) Resource() CloudResource {
+	return vpc.CloudResource
+}
+
+func (vpc VPC) WithCloudResource(resource CloudResource) ReportableResource {
+	vpc.CloudResource = resource
+	return vpc
+}
+
+func (vpc VPC) Kind() string {
+	return ResourceTypeVPC
+}
+
+func (vpc VPC) ReportFields() []ReportField {
+	isDefault := "no"
+	if vpc.IsDefault {
+		isDefault = "yes"
+	}
+
+	return []ReportField{
+		{Label: "CIDR", Value: vpc.CIDR},
+		{Label: "Default", Value: isDefault},
+		{Label: "State", Value: vpc.State},
+		{Label: "NAT gateways", Value: strconv.Itoa(len(vpc.NATGateways))},
+	}
+}
+
+func (vpc VPC) ClaimedResources() []ReportableResource {
+	var resources []ReportableResource
+
+	for _, natGateway := range vpc.NATGateways {
+		resources = append(resources, natGateway)
+	}
+
+	return resources
+}
+
+func (loadBalancer LoadBalancer) Resource() CloudResource {
+	return loadBalancer.CloudResource
+}
+
+func (loadBalancer LoadBalancer) WithCloudResource(resource CloudResource) ReportableResource {
+	loadBalancer.CloudResource = resource
+	return loadBalancer
+}
+
+func (loadBalancer LoadBalancer) Kind() string {
+	return ResourceTypeLoadBalancer
+}
+
+func (loadBalancer LoadBalancer) ReportFields() []ReportField {
+	healthyTargets := "unknown"
+	if !loadBalancer.TargetHealthUnknown {
+		healthyTargets = fmt.Sprintf("%d of %d", loadBalancer.HealthyTargetCount, loadBalancer.TargetCount)
+	}
+
+	return []ReportField{
+		{Label: "Type", Value: loadBalancer.Type},
+		{Label: "Scheme", Value: loadBalancer.Scheme},
+		{Label: "State", Value: loadBalancer.State},
+		{Label: "Healthy targets", Value: healthyTargets},
+	}
+}
+
+// OrphanReason is empty when the target health could not be read, rather than guessing the load balancer has no targets
+func (loadBalancer LoadBalancer) OrphanReason() string {
+	if loadBalancer.TargetHealthUnknown {
+		return ""
+	}
+
+	if loadBalancer.TargetCount == 0 {
+		return "no targets"
+	}
+
+	if loadBalancer.HealthyTargetCount == 0 {
+		return "no healthy targets"
+	}
+
+	return ""
+}
+
+// GetEKSClusterNames returns the clusters named in the Kubernetes cluster tags of the load balancer
+func (loadBalancer LoadBalancer) GetEKSClusterNames() []string {
+	var names []string
+
+	for key := range loadBalancer.Tags {
+		if strings.HasPrefix(key, eksLoadBalancerClusterTagPrefix) {
+			names = append(names, strings.TrimPrefix(key, eksLoadBalancerClusterTagPrefix))
+		}
+	}
+
+	return names
+}
+
+func (natGateway NATGateway) Resource() CloudResource {
+	return natGateway.CloudResource
+}
+
+func (natGateway NATGateway) WithCloudResource(resource CloudResource) ReportableResource {
+	natGateway.CloudResource = resource
+	return natGateway
+}
+
+func (natGateway NATGateway) Kind() string {
+	return ResourceTypeNATGateway
+}
+
+func (natGateway NATGateway) ReportFields() []ReportField {
+	return []ReportField{
+		{Label: "State", Value: natGateway.State},
+		{Label: "VPC", Value: natGateway.VPCID},
+		{Label: "Public IPs", Value: strings.Join(natGateway.PublicIPs, ", ")},
+	}
+}
+
+func (elasticIP ElasticIP) Resource() CloudResource {
+	return elasticIP.CloudResource
+}
+
+func (elasticIP ElasticIP) WithCloudResource(resource CloudResource) ReportableResource {
+	elasticIP.CloudResource = resource
+	return elasticIP
+}
+
+func (elasticIP ElasticIP) Kind() string {
+	return ResourceTypeElasticIP
+}
+
+func (elasticIP ElasticIP) ReportFields() []ReportField {
+	associatedWith := elasticIP.InstanceID
+	if associatedWith == "" {
+		associatedWith = elasticIP.NetworkInterfaceID
+	}
+
+	return []ReportField{
+		{Label: "Public IP", Value: elasticIP.PublicIP},
+		{Label: "Associated with", Value: associatedWith},
+	}
+}
+
+func (elasticIP ElasticIP) OrphanReason() string {
+	if elasticIP.AssociationID == "" {
+		return "not associated"
+	}
+
+	return ""
+}
+
+// ClaimedNothing leaves VPCs out of the report unless they hold a NAT gateway or belong to a stack, as every region has
+// a default VPC and VPCs cost nothing of their own
+func (vpc VPC) ClaimedNothing() bool {
+	return len(vpc.NATGateways) == 0
+}
+
+func (vpc *VPC) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
+	switch resource.(type) {
+	case NATGateway:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		natGateway := resource.(NATGateway)
+		vpc.NATGateways[natGateway.ID] = natGateway
+		return claimed()
+	}
+
+	return cannotClaim(resource)
+}
+
+func init() {
+	RegisterStackResourceType(cloudformationVPCStackResourceId, ResourceTypeVPC)
+	RegisterStackResourceType(cloudformationNATGatewayStackResourceId, ResourceTypeNATGateway)
+	RegisterStackResourceType(cloudformationLoadBalancerStackResourceId, ResourceTypeLoadBalancer)
+	RegisterStackResourceType(cloudformationClassicLoadBalancerStackResourceId, ResourceTypeLoadBalancer)
+
+	RegisterResourceNodeType(ResourceTypeVPC, NodeVPC)
+	RegisterResourceNodeType(ResourceTypeLoadBalancer, NodeLoadBalancer)
+	RegisterResourceNodeType(ResourceTypeNATGateway, NodeNATGateway)
+	RegisterResourceNodeType(ResourceTypeElasticIP, NodeElasticIP)
+
+	// VPCs are free, they are priced so their total covers the NAT gateways they claim
+	RegisterResourcePricer(ResourceTypeVPC, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return NewHourlyCost(0)
+	})
+	RegisterResourcePricer(ResourceTypeLoadBalancer, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return catalog.GetLoadBalancerCost(resource.(LoadBalancer))
+	})
+	RegisterResourcePricer(ResourceTypeNATGateway, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return catalog.GetNATGatewayCost(resource.(NATGateway))
+	})
+	RegisterResourcePricer(ResourceTypeElasticIP, func(catalog *PriceCatalog, resource ReportableResource) Cost {
+		return catalog.GetElasticIPCost(resource.(ElasticIP))
+	})
+}
+
+func (ctx *RegionalCloudContext) GetVPCs() []VPC {
+	var vpcs []VPC
+
+	for _, resource := range ctx.Resources[ResourceTypeVPC] {
+		vpcs = append(vpcs, resource.(VPC))
+	}
+
+	return vpcs
+}
+
+func (ctx *RegionalCloudContext) GetLoadBalancers() []LoadBalancer {
+	var loadBalancers []LoadBalancer
+
+	for _, resource := range ctx.Resources[ResourceTypeLoadBalancer] {
+		loadBalancers = append(loadBalancers, resource.(LoadBalancer))
+	}
+
+	return loadBalancers
+}
+
+func (ctx *RegionalCloudContext) GetNATGateways() []NATGateway {
+	var natGateways []NATGateway
+
+	for _, resource := range ctx.Resources[ResourceTypeNATGateway] {
+		natGateways = append(natGateways, resource.(NATGateway))
+	}
+
+	return natGateways
+}
+
+func (ctx *RegionalCloudContext) getNATGatewaysByVPCId() map[string][]NATGateway {
+	natGateways := map[string][]NATGateway{}
+
+	for _, natGateway := range ctx.GetNATGateways() {
+		natGateways[natGateway.VPCID] = append(natGateways[natGateway.VPCID], natGateway)
+	}
+
+	return natGateways
+}
+
+func getEKSClusterLoadBalancerClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+
+	for _, loadBalancer := range ctx.GetLoadBalancers() {
+		for _, eksClusterName := range loadBalancer.GetEKSClusterNames() {
+			eksCluster, ok := ctx.EKSClusters[eksClusterName]
+			if !ok {
+				continue
+			}
+
+			claimer := eksCluster
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  &claimer,
+				Resource: loadBalancer,
+				Reason:   fmt.Sprintf("tagged with %s%s", eksLoadBalancerClusterTagPrefix, eksClusterName),
+			})
+		}
+	}
+
+	return candidates
+}
+
+func getVPCClaimCandidates(ctx *RegionalCloudContext) []ClaimCandidate {
+	var candidates []ClaimCandidate
+	natGatewaysByVPCId := ctx.getNATGatewaysByVPCId()
+
+	for _, vpc := range ctx.GetVPCs() {
+		claimer := vpc
+
+		for _, natGateway := range natGatewaysByVPCId[vpc.ID] {
+			candidates = append(candidates, ClaimCandidate{
+				Claimer:  &claimer,
+				Resource: natGateway,
+				Reason:   fmt.Sprintf("in VPC %s", vpc.ID),
+			})
+		}
+	}
+
+	return candidates
+}
+
+// addNetworkResources adds the networking resources in the region to the graph, along with the EKS clusters, VPCs and
+// Cloudformation stacks that own them
+func addNetworkResources(graph *OwnershipGraph, ctx *RegionalCloudContext) {
+	natGatewaysByVPCId := ctx.getNATGatewaysByVPCId()
+
+	for _, resource := range ctx.Resources[ResourceTypeElasticIP] {
+		graph.AddNode(NodeElasticIP, resource.Resource())
+	}
+
+	for _, natGateway := range ctx.GetNATGateways() {
+		graph.AddNode(NodeNATGateway, natGateway.CloudResource)
+	}
+
+	for _, vpc := range ctx.GetVPCs() {
+		vpcNodeId := graph.AddNode(NodeVPC, vpc.CloudResource)
+
+		for _, natGateway := range natGatewaysByVPCId[vpc.ID] {
+			graph.AddEdge(GetNodeId(NodeNATGateway, natGateway.CloudResource), vpcNodeId, EdgeInVPC)
+		}
+	}
+
+	for _, loadBalancer := range ctx.GetLoadBalancers() {
+		loadBalancerNodeId := graph.AddNode(NodeLoadBalancer, loadBalancer.CloudResource)
+
+		for _, eksClusterName := range loadBalancer.GetEKSClusterNames() {
+			if eksCluster, ok := ctx.EKSClusters[eksClusterName]; ok {
+				graph.AddEdge(loadBalancerNodeId, GetNodeId(NodeEKSCluster, getEKSClusterNodeResource(eksCluster)), EdgeCreatedByEKS)
+			}
+		}
+	}
+
+	addCloudformationStackEdges(graph, ctx, map[string]NodeType{
+		cloudformationVPCStackResourceId:                 NodeVPC,
+		cloudformationNATGatewayStackResourceId:          NodeNATGateway,
+		cloudformationLoadBalancerStackResourceId:        NodeLoadBalancer,
+		cloudformationClassicLoadBalancerStackResourceId: NodeLoadBalancer,
+	})
+}
@@ -0,0 +1,444 @@
+package monitoring
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// DescribeTags accepts at most this many load balancers per call, for both ELB APIs
+const loadBalancerDescribeTagsBatchSize = 20
+
+const classicLoadBalancerInstanceInService = "InService"
+
+type VPCCollector struct{}
+
+func (collector *VPCCollector) Name() string {
+	return "VPCs"
+}
+
+func (collector *VPCCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeVPC}
+}
+
+func (collector *VPCCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	ec2Service := getEC2Service(scope.Session, scope.Credentials, scope.Region)
+
+	vpcs, err := getVPCs(ec2Service, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for _, vpc := range vpcs {
+		ctx.AddResource(vpc)
+	}
+
+	return nil
+}
+
+type LoadBalancerCollector struct{}
+
+func (collector *LoadBalancerCollector) Name() string {
+	return "load balancers"
+}
+
+func (collector *LoadBalancerCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeLoadBalancer}
+}
+
+func (collector *LoadBalancerCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	config := getAWSConfig(scope.Credentials, scope.Region)
+
+	// Either API failing still leaves the load balancers the other one listed in the report, as does failing to read tags
+	loadBalancers, err := getLoadBalancers(elbv2.New(scope.Session, config), scope.Account, scope.Region)
+	for _, loadBalancer := range loadBalancers {
+		ctx.AddResource(loadBalancer)
+	}
+
+	classicLoadBalancers, classicErr := getClassicLoadBalancers(elb.New(scope.Session, config), scope.Account, scope.Region)
+	for _, loadBalancer := range classicLoadBalancers {
+		ctx.AddResource(loadBalancer)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return classicErr
+}
+
+type NATGatewayCollector struct{}
+
+func (collector *NATGatewayCollector) Name() string {
+	return "NAT gateways"
+}
+
+func (collector *NATGatewayCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeNATGateway}
+}
+
+func (collector *NATGatewayCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	ec2Service := getEC2Service(scope.Session, scope.Credentials, scope.Region)
+
+	natGateways, err := getNATGateways(ec2Service, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for _, natGateway := range natGateways {
+		ctx.AddResource(natGateway)
+	}
+
+	return nil
+}
+
+type ElasticIPCollector struct{}
+
+func (collector *ElasticIPCollector) Name() string {
+	return "Elastic IPs"
+}
+
+func (collector *ElasticIPCollector) NodeTypes() []NodeType {
+	return []NodeType{NodeElasticIP}
+}
+
+func (collector *ElasticIPCollector) Collect(scope *ScanScope, ctx *RegionalCloudContext) error {
+	ec2Service := getEC2Service(scope.Session, scope.Credentials, scope.Region)
+
+	elasticIPs, err := getElasticIPs(ec2Service, scope.Account, scope.Region)
+	if err != nil {
+		return err
+	}
+
+	for _, elasticIP := range elasticIPs {
+		ctx.AddResource(elasticIP)
+	}
+
+	return nil
+}
+
+func getVPCs(ec2Service *ec2.EC2, account string, region string) ([]VPC, error) {
+	var vpcs []VPC
+
+	input := &ec2.DescribeVpcsInput{
+		MaxResults: aws.Int64(100),
+	}
+
+	err := ec2Service.DescribeVpcsPages(input, func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+		for _, vpcDescription := range page.Vpcs {
+			vpc := NewVPC()
+			vpc.ID = aws.StringValue(vpcDescription.VpcId)
+			vpc.Provider = ProviderAWS
+			vpc.Account = account
+			vpc.Region = region
+			vpc.CIDR = aws.StringValue(vpcDescription.CidrBlock)
+			vpc.State = aws.StringValue(vpcDescription.State)
+			vpc.IsDefault = aws.BoolValue(vpcDescription.IsDefault)
+			vpc.Tags = getEC2Tags(vpcDescription.Tags)
+			vpc.Name = vpc.Tags["Name"]
+			vpc.CloudResource = setLaunchedBy(vpc.CloudResource, "")
+
+			vpcs = append(vpcs, *vpc)
+		}
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VPCs %w", err)
+	}
+
+	log.Printf("Found %d VPCs in account %s region %s", len(vpcs), account, region)
+	return vpcs, nil
+}
+
+// getLoadBalancers returns every load balancer along with an error if the tags of some could not be read, since they
+// are needed to claim them for EKS clusters
+func getLoadBalancers(elbv2Service *elbv2.ELBV2, account string, region string) ([]LoadBalancer, error) {
+	var loadBalancers []LoadBalancer
+	var tagsErr error
+
+	err := elbv2Service.DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{}, func(page *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+		for _, loadBalancerDescription := range page.LoadBalancers {
+			loadBalancer := NewLoadBalancer()
+			loadBalancer.ID = aws.StringValue(loadBalancerDescription.LoadBalancerArn)
+			loadBalancer.Name = aws.StringValue(loadBalancerDescription.LoadBalancerName)
+			loadBalancer.Provider = ProviderAWS
+			loadBalancer.Account = account
+			loadBalancer.Region = region
+			loadBalancer.Type = aws.StringValue(loadBalancerDescription.Type)
+			loadBalancer.Scheme = aws.StringValue(loadBalancerDescription.Scheme)
+			loadBalancer.VPCID = aws.StringValue(loadBalancerDescription.VpcId)
+			loadBalancer.DNSName = aws.StringValue(loadBalancerDescription.DNSName)
+
+			if loadBalancerDescription.State != nil {
+				loadBalancer.State = aws.StringValue(loadBalancerDescription.State.Code)
+			}
+
+			if loadBalancerDescription.CreatedTime != nil {
+				loadBalancer.CreatedAt = *loadBalancerDescription.CreatedTime
+			}
+
+			loadBalancers = append(loadBalancers, *loadBalancer)
+		}
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get load balancers %w", err)
+	}
+
+	for start := 0; start < len(loadBalancers); start += loadBalancerDescribeTagsBatchSize {
+		end := start + loadBalancerDescribeTagsBatchSize
+		if end > len(loadBalancers) {
+			end = len(loadBalancers)
+		}
+
+		var arns []*string
+		for _, loadBalancer := range loadBalancers[start:end] {
+			arns = append(arns, aws.String(loadBalancer.ID))
+		}
+
+		output, err := elbv2Service.DescribeTags(&elbv2.DescribeTagsInput{ResourceArns: arns})
+		if err != nil {
+			log.Printf("Unable to get load balancer tags in %s: %s", region, err)
+			tagsErr = fmt.Errorf("failed to get load balancer tags %w", err)
+			continue
+		}
+
+		tagsByArn := map[string]map[string]string{}
+		for _, tagDescription := range output.TagDescriptions {
+			tagsByArn[aws.StringValue(tagDescription.ResourceArn)] = getELBv2Tags(tagDescription.Tags)
+		}
+
+		for idx := start; idx < end; idx++ {
+			loadBalancers[idx].Tags = tagsByArn[loadBalancers[idx].ID]
+		}
+	}
+
+	for idx := range loadBalancers {
+		loadBalancer := &loadBalancers[idx]
+
+		if err := setLoadBalancerTargetHealth(elbv2Service, loadBalancer); err != nil {
+			log.Printf("Unable to get target health for load balancer %s in %s: %s", loadBalancer.Name, region, err)
+			loadBalancer.TargetCount = 0
+			loadBalancer.HealthyTargetCount = 0
+			loadBalancer.TargetHealthUnknown = true
+		}
+
+		loadBalancer.CloudResource = setLaunchedBy(loadBalancer.CloudResource, "")
+	}
+
+	log.Printf("Found %d load balancers in account %s region %s", len(loadBalancers), account, region)
+	return loadBalancers, tagsErr
+}
+
+// setLoadBalancerTargetHealth counts the targets registered in every target group the load balancer forwards to
+func setLoadBalancerTargetHealth(elbv2Service *elbv2.ELBV2, loadBalancer *LoadBalancer) error {
+	var targetGroupArns []*string
+
+	input := &elbv2.DescribeTargetGroupsInput{
+		LoadBalancerArn: aws.String(loadBalancer.ID),
+	}
+
+	err := elbv2Service.DescribeTargetGroupsPages(input, func(page *elbv2.DescribeTargetGroupsOutput, lastPage bool) bool {
+		for _, targetGroup := range page.TargetGroups {
+			targetGroupArns = append(targetGroupArns, targetGroup.TargetGroupArn)
+		}
+		return !lastPage
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, targetGroupArn := range targetGroupArns {
+		output, err := elbv2Service.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{TargetGroupArn: targetGroupArn})
+		if err != nil {
+			return err
+		}
+
+		for _, targetHealth := range output.TargetHealthDescriptions {
+			loadBalancer.TargetCount++
+
+			if targetHealth.TargetHealth != nil && aws.StringValue(targetHealth.TargetHealth.State) == elbv2.TargetHealthStateEnumHealthy {
+				loadBalancer.HealthyTargetCount++
+			}
+		}
+	}
+
+	return nil
+}
+
+// getClassicLoadBalancers returns every classic load balancer along with an error if the tags of some could not be read
+func getClassicLoadBalancers(elbService *elb.ELB, account string, region string) ([]LoadBalancer, error) {
+	var loadBalancers []LoadBalancer
+	var tagsErr error
+
+	err := elbService.DescribeLoadBalancersPages(&elb.DescribeLoadBalancersInput{}, func(page *elb.DescribeLoadBalancersOutput, lastPage bool) bool {
+		for _, loadBalancerDescription := range page.LoadBalancerDescriptions {
+			loadBalancer := NewLoadBalancer()
+			loadBalancer.ID = aws.StringValue(loadBalancerDescription.LoadBalancerName)
+			loadBalancer.Name = loadBalancer.ID
+			loadBalancer.Provider = ProviderAWS
+			loadBalancer.Account = account
+			loadBalancer.Region = region
+			loadBalancer.Type = LoadBalancerTypeClassic
+			loadBalancer.Scheme = aws.StringValue(loadBalancerDescription.Scheme)
+			loadBalancer.VPCID = aws.StringValue(loadBalancerDescription.VPCId)
+			loadBalancer.DNSName = aws.StringValue(loadBalancerDescription.DNSName)
+
+			if loadBalancerDescription.CreatedTime != nil {
+				loadBalancer.CreatedAt = *loadBalancerDescription.CreatedTime
+			}
+
+			loadBalancers = append(loadBalancers, *loadBalancer)
+		}
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classic load balancers %w", err)
+	}
+
+	for start := 0; start < len(loadBalancers); start += loadBalancerDescribeTagsBatchSize {
+		end := start + loadBalancerDescribeTagsBatchSize
+		if end > len(loadBalancers) {
+			end = len(loadBalancers)
+		}
+
+		var names []*string
+		for _, loadBalancer := range loadBalancers[start:end] {
+			names = append(names, aws.String(loadBalancer.ID))
+		}
+
+		output, err := elbService.DescribeTags(&elb.DescribeTagsInput{LoadBalancerNames: names})
+		if err != nil {
+			log.Printf("Unable to get classic load balancer tags in %s: %s", region, err)
+			tagsErr = fmt.Errorf("failed to get classic load balancer tags %w", err)
+			continue
+		}
+
+		tagsByName := map[string]map[string]string{}
+		for _, tagDescription := range output.TagDescriptions {
+			tagsByName[aws.StringValue(tagDescription.LoadBalancerName)] = getELBTags(tagDescription.Tags)
+		}
+
+		for idx := start; idx < end; idx++ {
+			loadBalancers[idx].Tags = tagsByName[loadBalancers[idx].ID]
+		}
+	}
+
+	for idx := range loadBalancers {
+		loadBalancer := &loadBalancers[idx]
+
+		output, err := elbService.DescribeInstanceHealth(&elb.DescribeInstanceHealthInput{LoadBalancerName: aws.String(loadBalancer.ID)})
+		if err != nil {
+			log.Printf("Unable to get instance health for classic load balancer %s in %s: %s", loadBalancer.Name, region, err)
+			loadBalancer.TargetHealthUnknown = true
+		} else {
+			for _, instanceState := range output.InstanceStates {
+				loadBalancer.TargetCount++
+
+				if aws.StringValue(instanceState.State) == classicLoadBalancerInstanceInService {
+					loadBalancer.HealthyTargetCount++
+				}
+			}
+		}
+
+		loadBalancer.CloudResource = setLaunchedBy(loadBalancer.CloudResource, "")
+	}
+
+	log.Printf("Found %d classic load balancers in account %s region %s", len(loadBalancers), account, region)
+	return loadBalancers, tagsErr
+}
+
+func getNATGateways(ec2Service *ec2.EC2, account string, region string) ([]NATGateway, error) {
+	var natGateways []NATGateway
+
+	input := &ec2.DescribeNatGatewaysInput{
+		MaxResults: aws.Int64(100),
+	}
+
+	err := ec2Service.DescribeNatGatewaysPages(input, func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+		for _, natGatewayDescription := range page.NatGateways {
+			state := aws.StringValue(natGatewayDescription.State)
+
+			// Deleted NAT gateways stay visible for about an hour but are no longer billed
+			if state == ec2.NatGatewayStateDeleted {
+				continue
+			}
+
+			natGateway := NewNATGateway()
+			natGateway.ID = aws.StringValue(natGatewayDescription.NatGatewayId)
+			natGateway.Provider = ProviderAWS
+			natGateway.Account = account
+			natGateway.Region = region
+			natGateway.State = state
+			natGateway.VPCID = aws.StringValue(natGatewayDescription.VpcId)
+			natGateway.SubnetID = aws.StringValue(natGatewayDescription.SubnetId)
+
+			for _, address := range natGatewayDescription.NatGatewayAddresses {
+				if address.PublicIp != nil {
+					natGateway.PublicIPs = append(natGateway.PublicIPs, *address.PublicIp)
+				}
+			}
+
+			if natGatewayDescription.CreateTime != nil {
+				natGateway.CreatedAt = *natGatewayDescription.CreateTime
+			}
+
+			natGateway.Tags = getEC2Tags(natGatewayDescription.Tags)
+			natGateway.Name = natGateway.Tags["Name"]
+			natGateway.CloudResource = setLaunchedBy(natGateway.CloudResource, "")
+
+			natGateways = append(natGateways, *natGateway)
+		}
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NAT gateways %w", err)
+	}
+
+	log.Printf("Found %d NAT gateways in account %s region %s", len(natGateways), account, region)
+	return natGateways, nil
+}
+
+func getElasticIPs(ec2Service *ec2.EC2, account string, region string) ([]ElasticIP, error) {
+	var elasticIPs []ElasticIP
+
+	output, err := ec2Service.DescribeAddresses(&ec2.DescribeAddressesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Elastic IPs %w", err)
+	}
+
+	for _, address := range output.Addresses {
+		elasticIP := NewElasticIP()
+		elasticIP.ID = aws.StringValue(address.AllocationId)
+		elasticIP.PublicIP = aws.StringValue(address.PublicIp)
+		elasticIP.Provider = ProviderAWS
+		elasticIP.Account = account
+		elasticIP.Region = region
+		elasticIP.AssociationID = aws.StringValue(address.AssociationId)
+		elasticIP.InstanceID = aws.StringValue(address.InstanceId)
+		elasticIP.NetworkInterfaceID = aws.StringValue(address.NetworkInterfaceId)
+
+		// EC2-Classic addresses have no allocation ID
+		if elasticIP.ID == "" {
+			elasticIP.ID = elasticIP.PublicIP
+		}
+
+		elasticIP.Tags = getEC2Tags(address.Tags)
+		elasticIP.Name = elasticIP.Tags["Name"]
+		elasticIP.CloudResource = setLaunchedBy(elasticIP.CloudResource, "")
+
+		elasticIPs = append(elasticIPs, *elasticIP)
+	}
+
+	log.Printf("Found %d Elastic IPs in account %s region %s", len(elasticIPs), account, region)
+	return elasticIPs, nil
+}
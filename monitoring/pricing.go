@@ -0,0 +1,619 @@
+package monitoring
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const priceCatalogPathEnv = "PRICE_CATALOG_PATH"
+
+const azureServiceCodeVirtualMachines = "Virtual Machines"
+
+// Prices for regions missing from the catalog are taken from this region instead
+const priceCatalogReferenceRegion = "us-east-1"
+
+// Services of other providers name their regions differently, so they have their own reference region
+var priceCatalogServiceReferenceRegions = map[string]string{
+	azureServiceCodeVirtualMachines: "eastus",
+}
+
+const hoursPerMonth = 730
+
+const (
+	priceUnitHours                 = "Hrs"
+	priceUnitGBMonth               = "GB-Mo"
+	awsServiceCodeEC2              = "AmazonEC2"
+	awsServiceCodeEKS              = "AmazonEKS"
+	awsServiceCodeRDS              = "AmazonRDS"
+	awsServiceCodeElastiCache      = "AmazonElastiCache"
+	awsServiceCodeOpenSearch       = "AmazonES"
+	awsServiceCodeELB              = "AWSELB"
+	productFamilyCompute           = "Compute"
+	productFamilyEC2               = "Compute Instance"
+	productFamilyStorage           = "Storage"
+	productFamilyRDSInstance       = "Database Instance"
+	productFamilyRDSStorage        = "Database Storage"
+	productFamilyElastiCache       = "Cache Instance"
+	productFamilyOpenSearch        = "Elastic Search Instance"
+	productFamilyOpenSearchStorage = "Elastic Search Volume"
+	productFamilyNATGateway        = "NAT Gateway"
+	productFamilyElasticIP         = "IP Address"
+)
+
+// Each load balancer type is its own product family in the offer files
+var loadBalancerPriceProductFamilies = map[string]string{
+	"application":           "Load Balancer-Application",
+	"network":               "Load Balancer-Network",
+	"gateway":               "Load Balancer-Gateway",
+	LoadBalancerTypeClassic: "Load Balancer",
+}
+
+// The offer files name RDS engines and storage types differently to the RDS API
+var rdsPriceEngines = map[string]string{
+	"mysql":             "MySQL",
+	"mariadb":           "MariaDB",
+	"postgres":          "PostgreSQL",
+	"aurora":            "Aurora MySQL",
+	"aurora-mysql":      "Aurora MySQL",
+	"aurora-postgresql": "Aurora PostgreSQL",
+}
+
+var rdsPriceVolumeTypes = map[string]string{
+	"gp2":      "General Purpose",
+	"io1":      "Provisioned IOPS",
+	"standard": "Magnetic",
+}
+
+//go:embed pricing/catalog.json
+var bundledPriceCatalog []byte
+
+type Cost struct {
+	Hourly float64
+	Priced bool
+}
+
+func NewHourlyCost(hourly float64) Cost {
+	return Cost{Hourly: hourly, Priced: true}
+}
+
+func (cost Cost) Monthly() float64 {
+	return cost.Hourly * hoursPerMonth
+}
+
+func (cost Cost) Add(other Cost) Cost {
+	return Cost{
+		Hourly: cost.Hourly + other.Hourly,
+		Priced: cost.Priced || other.Priced,
+	}
+}
+
+func (cost Cost) String() string {
+	if !cost.Priced {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("$%.2f/hr, $%.2f/month", cost.Hourly, cost.Monthly())
+}
+
+type PriceCatalogEntry struct {
+	SKU           string
+	ServiceCode   string
+	ProductFamily string
+	RegionCode    string
+	Attributes    map[string]string
+	Unit          string
+	PricePerUnit  float64
+}
+
+type PriceCatalog struct {
+	PublicationDate string
+	entries         map[string][]PriceCatalogEntry
+}
+
+// priceListOffer is the subset of the AWS Price List API offer file format the catalog is read from
+type priceListOffer struct {
+	PublicationDate string `json:"publicationDate"`
+	Products        map[string]struct {
+		SKU           string            `json:"sku"`
+		ProductFamily string            `json:"productFamily"`
+		Attributes    map[string]string `json:"attributes"`
+	} `json:"products"`
+	Terms struct {
+		OnDemand map[string]map[string]struct {
+			PriceDimensions map[string]struct {
+				Unit         string            `json:"unit"`
+				PricePerUnit map[string]string `json:"pricePerUnit"`
+			} `json:"priceDimensions"`
+		} `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+// LoadPriceCatalog reads the catalog at path, or the catalog bundled with the tool when path is empty
+func LoadPriceCatalog(path string) (*PriceCatalog, error) {
+	catalogJson := bundledPriceCatalog
+
+	if path != "" {
+		fileJson, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read price catalog %s: %s", path, err)
+		}
+		catalogJson = fileJson
+	}
+
+	catalog, err := ParsePriceCatalog(catalogJson)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Loaded price catalog published %s", catalog.PublicationDate)
+	return catalog, nil
+}
+
+func getPriceCatalog() (*PriceCatalog, error) {
+	return LoadPriceCatalog(os.Getenv(priceCatalogPathEnv))
+}
+
+func ParsePriceCatalog(catalogJson []byte) (*PriceCatalog, error) {
+	var offer priceListOffer
+
+	if err := json.Unmarshal(catalogJson, &offer); err != nil {
+		return nil, fmt.Errorf("unable to parse price catalog: %s", err)
+	}
+
+	catalog := &PriceCatalog{
+		PublicationDate: offer.PublicationDate,
+		entries:         make(map[string][]PriceCatalogEntry),
+	}
+
+	for sku, product := range offer.Products {
+		for _, term := range offer.Terms.OnDemand[sku] {
+			for _, dimension := range term.PriceDimensions {
+				price, err := strconv.ParseFloat(dimension.PricePerUnit["USD"], 64)
+				if err != nil {
+					continue
+				}
+
+				entry := PriceCatalogEntry{
+					SKU:           sku,
+					ServiceCode:   product.Attributes["servicecode"],
+					ProductFamily: product.ProductFamily,
+					RegionCode:    product.Attributes["regionCode"],
+					Attributes:    product.Attributes,
+					Unit:          dimension.Unit,
+					PricePerUnit:  price,
+				}
+
+				key := getPriceCatalogKey(entry.ServiceCode, entry.ProductFamily, entry.RegionCode)
+				catalog.entries[key] = append(catalog.entries[key], entry)
+			}
+		}
+	}
+
+	return catalog, nil
+}
+
+func getPriceCatalogKey(serviceCode string, productFamily string, region string) string {
+	return strings.Join([]string{serviceCode, productFamily, region}, "|")
+}
+
+// Find returns the first on-demand price matching every given attribute, falling back to the reference region
+func (catalog *PriceCatalog) Find(serviceCode string, productFamily string, region string, attributes map[string]string) (PriceCatalogEntry, bool) {
+	referenceRegion := priceCatalogReferenceRegion
+	if serviceReferenceRegion, ok := priceCatalogServiceReferenceRegions[serviceCode]; ok {
+		referenceRegion = serviceReferenceRegion
+	}
+
+	for _, lookupRegion := range []string{region, referenceRegion} {
+		for _, entry := range catalog.entries[getPriceCatalogKey(serviceCode, productFamily, lookupRegion)] {
+			if matchesAttributes(entry.Attributes, attributes) {
+				return entry, true
+			}
+		}
+	}
+
+	return PriceCatalogEntry{}, false
+}
+
+func matchesAttributes(entryAttributes map[string]string, attributes map[string]string) bool {
+	for key, value := range attributes {
+		if !strings.EqualFold(entryAttributes[key], value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetEC2InstanceCost only covers compute, which stopped instances are not billed for
+func (catalog *PriceCatalog) GetEC2InstanceCost(ec2Instance EC2Instance) Cost {
+	if ec2Instance.IsStopped() {
+		return NewHourlyCost(0)
+	}
+
+	operatingSystem := "Linux"
+	if strings.EqualFold(ec2Instance.Platform, "windows") {
+		operatingSystem = "Windows"
+	}
+
+	// Offer files hold several SKUs per instance type, these pick the plain on-demand one without licensed software
+	// or a capacity reservation
+	entry, ok := catalog.Find(awsServiceCodeEC2, productFamilyEC2, ec2Instance.Region, map[string]string{
+		"instanceType":    ec2Instance.InstanceType,
+		"operatingSystem": operatingSystem,
+		"tenancy":         "Shared",
+		"preInstalledSw":  "NA",
+		"capacitystatus":  "Used",
+		"licenseModel":    "No License required",
+	})
+
+	if !ok || entry.Unit != priceUnitHours {
+		return Cost{}
+	}
+
+	return NewHourlyCost(entry.PricePerUnit)
+}
+
+func (catalog *PriceCatalog) GetEBSVolumeCost(ebsVolume EBSVolume) Cost {
+	if ebsVolume.VolumeType == "" {
+		return Cost{}
+	}
+
+	entry, ok := catalog.Find(awsServiceCodeEC2, productFamilyStorage, ebsVolume.Region, map[string]string{
+		"volumeApiName": ebsVolume.VolumeType,
+	})
+
+	if !ok || entry.Unit != priceUnitGBMonth {
+		return Cost{}
+	}
+
+	return NewHourlyCost(entry.PricePerUnit * float64(ebsVolume.SizeGiB) / hoursPerMonth)
+}
+
+func (catalog *PriceCatalog) GetEKSClusterCost(eksCluster EKSCluster) Cost {
+	entry, ok := catalog.Find(awsServiceCodeEKS, productFamilyCompute, eksCluster.Region, nil)
+
+	if !ok || entry.Unit != priceUnitHours {
+		return Cost{}
+	}
+
+	return NewHourlyCost(entry.PricePerUnit)
+}
+
+// ResourcePricer prices one kind of provider neutral resource, kinds without a pricer are left unpriced
+type ResourcePricer func(catalog *PriceCatalog, resource ReportableResource) Cost
+
+var resourcePricerRegistry = map[string]ResourcePricer{}
+
+func RegisterResourcePricer(kind string, pricer ResourcePricer) {
+	resourcePricerRegistry[kind] = pricer
+}
+
+// ResourceKindPriced reports whether the resources of a kind get a cost estimate, so views can leave out the cost of
+// the others rather than show it as unknown
+func ResourceKindPriced(kind string) bool {
+	_, ok := resourcePricerRegistry[kind]
+	return ok
+}
+
+// GetRDSInstanceCost covers the instance and its allocated storage. Stopped instances are only billed for storage, and
+// Aurora storage is billed by use so it is not priced.
+func (catalog *PriceCatalog) GetRDSInstanceCost(rdsInstance RDSInstance) Cost {
+	deploymentOption := "Single-AZ"
+	if rdsInstance.MultiAZ && !strings.HasPrefix(rdsInstance.Engine, auroraEnginePrefix) {
+		deploymentOption = "Multi-AZ"
+	}
+
+	cost := NewHourlyCost(0)
+
+	if rdsInstance.State != "stopped" {
+		entry, ok := catalog.Find(awsServiceCodeRDS, productFamilyRDSInstance, rdsInstance.Region, map[string]string{
+			"instanceType":     rdsInstance.InstanceClass,
+			"databaseEngine":   rdsPriceEngines[rdsInstance.Engine],
+			"deploymentOption": deploymentOption,
+		})
+
+		if !ok || entry.Unit != priceUnitHours {
+			return Cost{}
+		}
+
+		cost = NewHourlyCost(entry.PricePerUnit)
+	}
+
+	if rdsInstance.StorageGiB > 0 && rdsPriceVolumeTypes[rdsInstance.StorageType] != "" {
+		entry, ok := catalog.Find(awsServiceCodeRDS, productFamilyRDSStorage, rdsInstance.Region, map[string]string{
+			"volumeType":       rdsPriceVolumeTypes[rdsInstance.StorageType],
+			"deploymentOption": deploymentOption,
+		})
+
+		if ok && entry.Unit == priceUnitGBMonth {
+			cost = cost.Add(NewHourlyCost(entry.PricePerUnit * float64(rdsInstance.StorageGiB) / hoursPerMonth))
+		}
+	}
+
+	return cost
+}
+
+func (catalog *PriceCatalog) GetElastiCacheClusterCost(elastiCacheCluster ElastiCacheCluster) Cost {
+	entry, ok := catalog.Find(awsServiceCodeElastiCache, productFamilyElastiCache, elastiCacheCluster.Region, map[string]string{
+		"instanceType": elastiCacheCluster.InstanceClass,
+		"cacheEngine":  elastiCacheCluster.Engine,
+	})
+
+	if !ok || entry.Unit != priceUnitHours {
+		return Cost{}
+	}
+
+	return NewHourlyCost(entry.PricePerUnit * float64(elastiCacheCluster.NodeCount))
+}
+
+// GetOpenSearchDomainCost covers the data instances and their EBS storage, which is priced as gp2. Newer instance types
+// end in .search, the offer files still name them .elasticsearch.
+func (catalog *PriceCatalog) GetOpenSearchDomainCost(openSearchDomain OpenSearchDomain) Cost {
+	instanceType := openSearchDomain.InstanceClass
+	if strings.HasSuffix(instanceType, ".search") {
+		instanceType = strings.TrimSuffix(instanceType, ".search") + ".elasticsearch"
+	}
+
+	entry, ok := catalog.Find(awsServiceCodeOpenSearch, productFamilyOpenSearch, openSearchDomain.Region, map[string]string{
+		"instanceType": instanceType,
+	})
+
+	if !ok || entry.Unit != priceUnitHours {
+		return Cost{}
+	}
+
+	cost := NewHourlyCost(entry.PricePerUnit * float64(openSearchDomain.InstanceCount))
+
+	if openSearchDomain.StorageGiB > 0 {
+		entry, ok := catalog.Find(awsServiceCodeOpenSearch, productFamilyOpenSearchStorage, openSearchDomain.Region, map[string]string{
+			"storageMedia": "GP2",
+		})
+
+		if ok && entry.Unit == priceUnitGBMonth {
+			cost = cost.Add(NewHourlyCost(entry.PricePerUnit * float64(openSearchDomain.StorageGiB) / hoursPerMonth))
+		}
+	}
+
+	return cost
+}
+
+// GetLoadBalancerCost only covers the hourly charge, capacity units depend on traffic and are not priced
+func (catalog *PriceCatalog) GetLoadBalancerCost(loadBalancer LoadBalancer) Cost {
+	productFamily, ok := loadBalancerPriceProductFamilies[loadBalancer.Type]
+	if !ok {
+		return Cost{}
+	}
+
+	entry, ok := catalog.Find(awsServiceCodeELB, productFamily, loadBalancer.Region, nil)
+
+	if !ok || entry.Unit != priceUnitHours {
+		return Cost{}
+	}
+
+	return NewHourlyCost(entry.PricePerUnit)
+}
+
+// GetNATGatewayCost only covers the hourly charge, processed data is not priced. Failed NAT gateways are not billed.
+func (catalog *PriceCatalog) GetNATGatewayCost(natGateway NATGateway) Cost {
+	if natGateway.State == ec2.NatGatewayStateFailed {
+		return NewHourlyCost(0)
+	}
+
+	entry, ok := catalog.Find(awsServiceCodeEC2, productFamilyNATGateway, natGateway.Region, nil)
+
+	if !ok || entry.Unit != priceUnitHours {
+		return Cost{}
+	}
+
+	return NewHourlyCost(entry.PricePerUnit)
+}
+
+// GetElasticIPCost charges the idle address rate for Elastic IPs that are not associated with anything, an associated
+// address is free
+func (catalog *PriceCatalog) GetElasticIPCost(elasticIP ElasticIP) Cost {
+	if elasticIP.AssociationID != "" {
+		return NewHourlyCost(0)
+	}
+
+	entry, ok := catalog.Find(awsServiceCodeEC2, productFamilyElasticIP, elasticIP.Region, nil)
+
+	if !ok || entry.Unit != priceUnitHours {
+		return Cost{}
+	}
+
+	return NewHourlyCost(entry.PricePerUnit)
+}
+
+func (catalog *PriceCatalog) getAzureVirtualMachineSizeCost(region string, size string) Cost {
+	entry, ok := catalog.Find(azureServiceCodeVirtualMachines, productFamilyCompute, region, map[string]string{
+		"armSkuName": size,
+	})
+
+	if !ok || entry.Unit != priceUnitHours {
+		return Cost{}
+	}
+
+	return NewHourlyCost(entry.PricePerUnit)
+}
+
+// GetAzureVirtualMachineCost only covers compute, which deallocated virtual machines are not billed for. Stopped
+// virtual machines still hold their hardware and are billed as if running.
+func (catalog *PriceCatalog) GetAzureVirtualMachineCost(virtualMachine AzureVirtualMachine) Cost {
+	if virtualMachine.State == "deallocated" {
+		return NewHourlyCost(0)
+	}
+
+	return catalog.getAzureVirtualMachineSizeCost(virtualMachine.Region, virtualMachine.InstanceType)
+}
+
+func (catalog *PriceCatalog) GetAzureVirtualMachineScaleSetCost(scaleSet AzureVirtualMachineScaleSet) Cost {
+	cost := catalog.getAzureVirtualMachineSizeCost(scaleSet.Region, scaleSet.InstanceType)
+	if !cost.Priced {
+		return cost
+	}
+
+	return NewHourlyCost(cost.Hourly * float64(scaleSet.Capacity))
+}
+
+type CostEnricher struct {
+	Catalog *PriceCatalog
+}
+
+func NewCostEnricher(catalog *PriceCatalog) *CostEnricher {
+	return &CostEnricher{Catalog: catalog}
+}
+
+func (enricher *CostEnricher) Name() string {
+	return "cost estimates"
+}
+
+func (enricher *CostEnricher) Enrich(scope *ScanScope, ctx *RegionalCloudContext) error {
+	regionalCost := Cost{}
+
+	for id, ebsVolume := range ctx.EBSVolumes {
+		ebsVolume.EstimatedCost = enricher.Catalog.GetEBSVolumeCost(ebsVolume)
+		ctx.EBSVolumes[id] = ebsVolume
+		regionalCost = regionalCost.Add(ebsVolume.EstimatedCost)
+	}
+
+	for id, ec2Instance := range ctx.EC2Instances {
+		ec2Instance.EstimatedCost = enricher.Catalog.GetEC2InstanceCost(ec2Instance)
+		ctx.EC2Instances[id] = ec2Instance
+		regionalCost = regionalCost.Add(ec2Instance.EstimatedCost)
+	}
+
+	for name, eksCluster := range ctx.EKSClusters {
+		eksCluster.EstimatedCost = enricher.Catalog.GetEKSClusterCost(eksCluster)
+		ctx.EKSClusters[name] = eksCluster
+		regionalCost = regionalCost.Add(eksCluster.EstimatedCost)
+	}
+
+	ctx.updateCloudResources(func(resource ReportableResource) CloudResource {
+		cloudResource := resource.Resource()
+
+		if pricer, ok := resourcePricerRegistry[resource.Kind()]; ok {
+			cloudResource.EstimatedCost = pricer(enricher.Catalog, resource)
+			regionalCost = regionalCost.Add(cloudResource.EstimatedCost)
+		}
+
+		return cloudResource
+	})
+
+	ctx.EstimatedCost = ctx.EstimatedCost.Add(regionalCost)
+	return nil
+}
+
+func (ec2Instance EC2Instance) TotalCost() Cost {
+	total := ec2Instance.EstimatedCost
+
+	for _, ebsVolume := range ec2Instance.EBSVolumes {
+		total = total.Add(ebsVolume.EstimatedCost)
+	}
+
+	return total
+}
+
+func (couchbaseCloudCluster CouchbaseCloudCluster) TotalCost() Cost {
+	total := couchbaseCloudCluster.EstimatedCost
+
+	for _, ec2Instance := range couchbaseCloudCluster.EC2Instances {
+		total = total.Add(ec2Instance.TotalCost())
+	}
+
+	return total
+}
+
+func (eksCluster EKSCluster) TotalCost() Cost {
+	total := eksCluster.EstimatedCost
+
+	for _, ec2Instance := range eksCluster.EC2Instances {
+		total = total.Add(ec2Instance.TotalCost())
+	}
+
+	for _, couchbaseCloudCluster := range eksCluster.CouchbaseCloudClusters {
+		total = total.Add(couchbaseCloudCluster.TotalCost())
+	}
+
+	for _, loadBalancer := range eksCluster.LoadBalancers {
+		total = total.Add(GetReportableTotalCost(loadBalancer))
+	}
+
+	return total
+}
+
+func (cloudformationStack CloudformationStack) TotalCost() Cost {
+	total := cloudformationStack.EstimatedCost
+
+	for _, ec2Instance := range cloudformationStack.EC2Instances {
+		total = total.Add(ec2Instance.TotalCost())
+	}
+
+	for _, resources := range cloudformationStack.ReportableResources {
+		for _, resource := range resources {
+			total = total.Add(GetReportableTotalCost(resource))
+		}
+	}
+
+	return total
+}
+
+func (couchbaseCloud CouchbaseCloud) TotalCost() Cost {
+	total := couchbaseCloud.EstimatedCost
+
+	for _, eksCluster := range couchbaseCloud.EKSClusters {
+		total = total.Add(eksCluster.TotalCost())
+	}
+
+	if couchbaseCloud.CloudFormationStack != nil {
+		total = total.Add(couchbaseCloud.CloudFormationStack.TotalCost())
+	}
+
+	for _, aksCluster := range couchbaseCloud.AKSClusters {
+		total = total.Add(GetReportableTotalCost(aksCluster))
+	}
+
+	for _, virtualMachine := range couchbaseCloud.AzureVirtualMachines {
+		total = total.Add(GetReportableTotalCost(virtualMachine))
+	}
+
+	for _, gkeCluster := range couchbaseCloud.GKEClusters {
+		total = total.Add(GetReportableTotalCost(gkeCluster))
+	}
+
+	for _, instance := range couchbaseCloud.GCPInstances {
+		total = total.Add(GetReportableTotalCost(instance))
+	}
+
+	return total
+}
+
+// GetReportableTotalCost adds the cost of everything a reportable resource claimed to its own
+func GetReportableTotalCost(resource ReportableResource) Cost {
+	total := resource.Resource().EstimatedCost
+
+	if parent, ok := resource.(ReportableParent); ok {
+		for _, claimedResource := range parent.ClaimedResources() {
+			total = total.Add(GetReportableTotalCost(claimedResource))
+		}
+	}
+
+	return total
+}
+
+func (ctx *GlobalCloudContext) GetEstimatedCostByAccount() map[string]Cost {
+	costs := map[string]Cost{}
+
+	for _, regionalCtx := range ctx.RegionalCloudContexts {
+		costs[regionalCtx.Account] = costs[regionalCtx.Account].Add(regionalCtx.EstimatedCost)
+	}
+
+	return costs
+}
@@ -0,0 +1,97 @@
+package monitoring
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+const expiryReaperEnv = "EXPIRY_REAPER"
+const expiryReaperDryRunEnv = "EXPIRY_REAPER_DRY_RUN"
+const expiryReaperActionEnv = "EXPIRY_REAPER_ACTION"
+
+const expiryReaperActor = "cloud-monitoring-tool expiry reaper"
+
+type ReapResult struct {
+	Reference ResourceReference
+	Action    string
+	DryRun    bool
+	Outcome   string
+	Error     string
+}
+
+// ExpiryReaper stops or deletes expired resources. EC2 instances are stopped unless the action is delete, other resources can only be deleted.
+type ExpiryReaper struct {
+	Actioner *AWSResourceActioner
+	Action   string
+	DryRun   bool
+}
+
+func ExpiryReaperEnabled() bool {
+	return strings.ToLower(os.Getenv(expiryReaperEnv)) == "true"
+}
+
+// NewExpiryReaper only acts for real when EXPIRY_REAPER_DRY_RUN is explicitly set to false
+func NewExpiryReaper() (*ExpiryReaper, error) {
+	action := strings.ToLower(os.Getenv(expiryReaperActionEnv))
+
+	if action == "" {
+		action = ResourceActionStop
+	}
+
+	if action != ResourceActionStop && action != ResourceActionDelete {
+		return nil, fmt.Errorf("invalid %s %q, expected %s or %s", expiryReaperActionEnv, action, ResourceActionStop, ResourceActionDelete)
+	}
+
+	actioner, err := NewAWSResourceActioner()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExpiryReaper{
+		Actioner: actioner,
+		Action:   action,
+		DryRun:   strings.ToLower(os.Getenv(expiryReaperDryRunEnv)) != "false",
+	}, nil
+}
+
+func (reaper *ExpiryReaper) getAction(resource ResourceReference) string {
+	if reaper.Action == ResourceActionStop && resource.Type != NodeEC2Instance {
+		return ""
+	}
+
+	return reaper.Action
+}
+
+func (reaper *ExpiryReaper) Reap(ctx *GlobalCloudContext) []ReapResult {
+	var results []ReapResult
+
+	for _, expiredResource := range ctx.GetExpiredResources() {
+		reference := expiredResource.Reference
+		result := ReapResult{Reference: reference, DryRun: reaper.DryRun}
+
+		if keepUntil, ok := expiredResource.Resource.GetKeptUntil(); ok {
+			result.Outcome = fmt.Sprintf("Skipped, kept until %s", keepUntil.Format(keepUntilLayout))
+		} else if expiredResource.Resource.Provider != ProviderAWS {
+			result.Outcome = "Skipped, only AWS resources can be reaped"
+		} else if result.Action = reaper.getAction(reference); result.Action == "" {
+			result.Outcome = fmt.Sprintf("Skipped, %s cannot be stopped", reference.Type)
+		} else if reaper.DryRun {
+			result.Outcome = fmt.Sprintf("Would %s (dry run)", result.Action)
+		} else {
+			outcome, err := reaper.Actioner.Perform(reference, result.Action, expiryReaperActor)
+			result.Outcome = outcome
+
+			if err != nil {
+				result.Error = err.Error()
+			}
+		}
+
+		log.Printf("Expiry reaper: %s %s in account %s region %s: %s %s", reference.Type, reference.ID, reference.Account, reference.Region, result.Outcome, result.Error)
+		results = append(results, result)
+	}
+
+	ctx.ReapResults = results
+	return results
+}
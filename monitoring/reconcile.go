@@ -0,0 +1,139 @@
+package monitoring
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+const (
+	CouchbaseMatchUnmatched       = "unmatched"
+	CouchbaseMatchMatched         = "matched"
+	CouchbaseMatchMultipleRegions = "matched in multiple regions"
+)
+
+// RegionalCouchbaseView is the copy of the Couchbase Cloud data a single account region claims against.
+// Clouds and clusters are kept here as well as in the regional context, since claiming removes them from the latter.
+type RegionalCouchbaseView struct {
+	Account                string
+	Region                 string
+	CouchbaseClouds        map[string]*CouchbaseCloud
+	CouchbaseCloudClusters map[string]*CouchbaseCloudCluster
+}
+
+func NewRegionalCouchbaseView(account string, region string, clouds map[string]*CouchbaseCloud, clusters map[string]*CouchbaseCloudCluster) (*RegionalCouchbaseView, error) {
+	cloudsCopy, clustersCopy, err := deepCopyCouchbaseCloudData(clouds, clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegionalCouchbaseView{
+		Account:                account,
+		Region:                 region,
+		CouchbaseClouds:        cloudsCopy,
+		CouchbaseCloudClusters: clustersCopy,
+	}, nil
+}
+
+func (view *RegionalCouchbaseView) Attach(ctx *RegionalCloudContext) {
+	for id, cloud := range view.CouchbaseClouds {
+		ctx.CouchbaseClouds[id] = cloud
+	}
+
+	for id, cluster := range view.CouchbaseCloudClusters {
+		ctx.CouchbaseCloudClusters[id] = cluster
+	}
+}
+
+func (view *RegionalCouchbaseView) Location() string {
+	return fmt.Sprintf("%s/%s", view.Account, view.Region)
+}
+
+func ReconcileCouchbaseCloudData(globalCtx *GlobalCloudContext, views []RegionalCouchbaseView) {
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].Location() < views[j].Location()
+	})
+
+	for _, view := range views {
+		for id, regionalCloud := range view.CouchbaseClouds {
+			cloud, ok := globalCtx.CouchbaseClouds[id]
+			if !ok || !regionalCloud.Seen {
+				continue
+			}
+
+			cloud.Seen = true
+			cloud.MatchedRegions = append(cloud.MatchedRegions, view.Location())
+
+			for name, eksCluster := range regionalCloud.EKSClusters {
+				cloud.EKSClusters[name] = eksCluster
+			}
+
+			if cloud.CloudFormationStack == nil {
+				cloud.CloudFormationStack = regionalCloud.CloudFormationStack
+			}
+
+			for id, aksCluster := range regionalCloud.AKSClusters {
+				cloud.AKSClusters[id] = aksCluster
+			}
+
+			for id, virtualMachine := range regionalCloud.AzureVirtualMachines {
+				cloud.AzureVirtualMachines[id] = virtualMachine
+			}
+
+			for id, gkeCluster := range regionalCloud.GKEClusters {
+				cloud.GKEClusters[id] = gkeCluster
+			}
+
+			for id, instance := range regionalCloud.GCPInstances {
+				cloud.GCPInstances[id] = instance
+			}
+		}
+
+		for id, regionalCluster := range view.CouchbaseCloudClusters {
+			cluster, ok := globalCtx.CouchbaseCloudClusters[id]
+			if !ok || !regionalCluster.Seen {
+				continue
+			}
+
+			cluster.Seen = true
+			cluster.MatchedRegions = append(cluster.MatchedRegions, view.Location())
+
+			for ec2Id, ec2Instance := range regionalCluster.EC2Instances {
+				cluster.EC2Instances[ec2Id] = ec2Instance
+			}
+
+			if cluster.EKSClusterName == "" {
+				cluster.EKSClusterName = regionalCluster.EKSClusterName
+			}
+		}
+	}
+
+	unmatchedClouds := 0
+	for _, cloud := range globalCtx.CouchbaseClouds {
+		cloud.MatchStatus = getCouchbaseMatchStatus(cloud.MatchedRegions)
+		if cloud.MatchStatus == CouchbaseMatchUnmatched {
+			unmatchedClouds++
+		}
+	}
+
+	unmatchedClusters := 0
+	for _, cluster := range globalCtx.CouchbaseCloudClusters {
+		cluster.MatchStatus = getCouchbaseMatchStatus(cluster.MatchedRegions)
+		if cluster.MatchStatus == CouchbaseMatchUnmatched {
+			unmatchedClusters++
+		}
+	}
+
+	log.Printf("Reconciled Couchbase Cloud data across %d regions (%d unmatched clouds, %d unmatched clusters)", len(views), unmatchedClouds, unmatchedClusters)
+}
+
+func getCouchbaseMatchStatus(matchedRegions []string) string {
+	switch len(matchedRegions) {
+	case 0:
+		return CouchbaseMatchUnmatched
+	case 1:
+		return CouchbaseMatchMatched
+	default:
+		return CouchbaseMatchMultipleRegions
+	}
+}
@@ -0,0 +1,95 @@
+package monitoring
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"os"
+	"sort"
+	"strings"
+)
+
+const awsRegionsAllowEnv = "AWS_REGIONS_ALLOW"
+const awsRegionsDenyEnv = "AWS_REGIONS_DENY"
+
+// DescribeRegions is a global call, any enabled region can answer it
+const regionDiscoveryRegion = "us-east-1"
+
+// Only used when the enabled regions for an account cannot be discovered
+var fallbackRegions = []string{
+	"us-east-1", "us-east-2", "us-west-2", "eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1", "eu-north-1",
+	"ca-central-1", "us-west-1", "ap-south-1", "ap-northeast-2", "ap-southeast-1", "ap-southeast-2", "ap-northeast-1",
+}
+
+type RegionFilter struct {
+	Allow map[string]bool
+	Deny  map[string]bool
+}
+
+func NewRegionFilter(allow []string, deny []string) *RegionFilter {
+	return &RegionFilter{
+		Allow: toRegionSet(allow),
+		Deny:  toRegionSet(deny),
+	}
+}
+
+func getRegionFilter() *RegionFilter {
+	return NewRegionFilter(split(os.Getenv(awsRegionsAllowEnv)), split(os.Getenv(awsRegionsDenyEnv)))
+}
+
+func toRegionSet(regions []string) map[string]bool {
+	regionSet := map[string]bool{}
+
+	for _, region := range regions {
+		region = strings.TrimSpace(region)
+		if region != "" {
+			regionSet[region] = true
+		}
+	}
+
+	return regionSet
+}
+
+func (filter *RegionFilter) Apply(regions []string) []string {
+	var filtered []string
+
+	for _, region := range regions {
+		if len(filter.Allow) > 0 && !filter.Allow[region] {
+			continue
+		}
+
+		if filter.Deny[region] {
+			continue
+		}
+
+		filtered = append(filtered, region)
+	}
+
+	sort.Strings(filtered)
+	return filtered
+}
+
+func getEnabledRegions(sess *session.Session, awsCredentials *sts.Credentials) ([]string, error) {
+	ec2Service := getEC2Service(sess, awsCredentials, regionDiscoveryRegion)
+
+	// Without AllRegions only regions that are enabled for the account are returned, including opted-in ones
+	result, err := ec2Service.DescribeRegions(&ec2.DescribeRegionsInput{
+		AllRegions: aws.Bool(false),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe regions %w", err)
+	}
+
+	var enabledRegions []string
+
+	for _, region := range result.Regions {
+		if region.RegionName != nil {
+			enabledRegions = append(enabledRegions, *region.RegionName)
+		}
+	}
+
+	return enabledRegions, nil
+}
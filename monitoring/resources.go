@@ -1,41 +1,43 @@
 package monitoring
 
 import (
-	"github.com/aws/aws-sdk-go/service/cloudformation"
-	"github.com/aws/aws-sdk-go/service/ec2"
 	couchbasecapella "github.com/couchbaselabs/couchbase-cloud-go-client"
 	"time"
 )
 
 type CloudResourceClaimer interface {
-	Claim(ctx *RegionalCloudContext, resource interface{}) bool
+	Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult
 }
 
 type CloudResource struct {
-	ID         string
-	Name       string
-	LaunchedBy string
-	Region     string
-	Tags       map[string]string
-	CreatedAt  time.Time
-	Account    string
+	ID               string
+	Name             string
+	Provider         string
+	LaunchedBy       string
+	LaunchedBySource string
+	Region           string
+	Tags             map[string]string
+	CreatedAt        time.Time
+	Account          string
+	EstimatedCost    Cost
+	Expiry           Expiry
 }
 
 type EBSVolume struct {
-	CloudResource
-	Type    *string
-	SizeGiB int64
-	State   string
+	Volume
+	SnapshotID       string
+	DetachedAt       time.Time
+	DetachedAtSource string
 }
 
 type EC2Instance struct {
-	CloudResource
-	SubnetID                    string
-	InstanceType                string
-	KeyName                     string
-	Platform                    string
-	EBSVolumes                  map[string]EBSVolume
-	InstanceBlockDeviceMappings []*ec2.InstanceBlockDeviceMapping
+	Compute
+	KeyName               string
+	Platform              string
+	EBSVolumes            map[string]EBSVolume
+	Utilisation           Utilisation
+	StateTransitionReason string
+	StoppedAt             time.Time
 }
 
 type CouchbaseCloudCluster struct {
@@ -48,24 +50,27 @@ type CouchbaseCloudCluster struct {
 	CloudID        *string
 	ProjectID      string
 	Environment    string
+	MatchStatus    string
+	MatchedRegions []string
 }
 
 type EKSCluster struct {
-	CloudResource
-	VpcId                  string
+	KubernetesCluster
 	Age                    time.Duration
-	Subnets                []*string
+	SubnetIDs              []string
 	EC2Instances           map[string]EC2Instance
 	CouchbaseCloudClusters map[string]CouchbaseCloudCluster
+	LoadBalancers          map[string]LoadBalancer
 }
 
 type CloudformationStack struct {
-	CloudResource
-	CreationDuration  time.Duration
-	Parameters        map[string]string
-	StackResourceList []*cloudformation.StackResourceSummary
-	EC2Instances      map[string]EC2Instance
-	EKSClusters       map[string]EKSCluster
+	Stack
+	CreationDuration time.Duration
+	Parameters       map[string]string
+	EC2Instances     map[string]EC2Instance
+	EKSClusters      map[string]EKSCluster
+	// ReportableResources holds claimed resources of the kinds kept in RegionalCloudContext.Resources, by kind and ID
+	ReportableResources map[string]map[string]ReportableResource
 }
 
 type CloudRegion struct {
@@ -75,18 +80,19 @@ type CloudRegion struct {
 
 type CouchbaseCloud struct {
 	CloudResource
-	Provider            string
-	Status              string
-	VirtualNetworkCIDR  string
-	VirtualNetworkID    string
-	EKSClusters         map[string]EKSCluster
-	CloudFormationStack *CloudformationStack
-	CloudRegion         CloudRegion
-	Seen                bool
-}
-
-type VPC struct {
-	CloudResource
+	Status               string
+	VirtualNetworkCIDR   string
+	VirtualNetworkID     string
+	EKSClusters          map[string]EKSCluster
+	CloudFormationStack  *CloudformationStack
+	AKSClusters          map[string]AKSCluster
+	AzureVirtualMachines map[string]AzureVirtualMachine
+	GKEClusters          map[string]GKECluster
+	GCPInstances         map[string]GCPInstance
+	CloudRegion          CloudRegion
+	Seen                 bool
+	MatchStatus          string
+	MatchedRegions       []string
 }
 
 func NewEBSVolume() *EBSVolume {
@@ -109,19 +115,25 @@ func NewEKSCluster() *EKSCluster {
 	return &EKSCluster{
 		EC2Instances:           make(map[string]EC2Instance),
 		CouchbaseCloudClusters: make(map[string]CouchbaseCloudCluster),
+		LoadBalancers:          make(map[string]LoadBalancer),
 	}
 }
 
 func NewCloudFormationStack() *CloudformationStack {
 	return &CloudformationStack{
-		EC2Instances: make(map[string]EC2Instance),
-		EKSClusters:  make(map[string]EKSCluster),
+		EC2Instances:        make(map[string]EC2Instance),
+		EKSClusters:         make(map[string]EKSCluster),
+		ReportableResources: make(map[string]map[string]ReportableResource),
 	}
 }
 
 func NewCouchbaseCloud() *CouchbaseCloud {
 	return &CouchbaseCloud{
-		EKSClusters: make(map[string]EKSCluster),
+		EKSClusters:          make(map[string]EKSCluster),
+		AKSClusters:          make(map[string]AKSCluster),
+		AzureVirtualMachines: make(map[string]AzureVirtualMachine),
+		GKEClusters:          make(map[string]GKECluster),
+		GCPInstances:         make(map[string]GCPInstance),
 	}
 }
 
@@ -145,58 +157,139 @@ func ParseServices(services []couchbasecapella.CouchbaseServices) []string {
 	return strings
 }
 
-func (ec2Instance *EC2Instance) Claim(ctx *RegionalCloudContext, resource interface{}) {
+func (ec2Instance *EC2Instance) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
 	switch resource.(type) {
 	case EBSVolume:
-		ctx.Claim(resource)
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
 		ebsVolume := resource.(EBSVolume)
 		ec2Instance.EBSVolumes[ebsVolume.ID] = ebsVolume
+		return claimed()
 	}
+
+	return cannotClaim(resource)
 }
 
-func (couchbaseCloudCluster *CouchbaseCloudCluster) Claim(ctx *RegionalCloudContext, resource interface{}) {
+func (couchbaseCloudCluster *CouchbaseCloudCluster) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
 	switch resource.(type) {
 	case EC2Instance:
-		ctx.Claim(resource)
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
 		ec2Instance := resource.(EC2Instance)
 		couchbaseCloudCluster.EC2Instances[ec2Instance.ID] = ec2Instance
 		couchbaseCloudCluster.Seen = true
+		return claimed()
 	}
+
+	return cannotClaim(resource)
 }
 
-func (eksCluster *EKSCluster) Claim(ctx *RegionalCloudContext, resource interface{}) {
+func (eksCluster *EKSCluster) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
 	switch resource.(type) {
 	case EC2Instance:
-		ctx.Claim(resource)
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
 		ec2Instance := resource.(EC2Instance)
 		eksCluster.EC2Instances[ec2Instance.ID] = ec2Instance
+		return claimed()
+	case LoadBalancer:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		loadBalancer := resource.(LoadBalancer)
+		eksCluster.LoadBalancers[loadBalancer.ID] = loadBalancer
+		return claimed()
 	case CouchbaseCloudCluster:
-		ctx.Claim(resource)
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
 		couchbaseCloudCluster := resource.(CouchbaseCloudCluster)
 		eksCluster.CouchbaseCloudClusters[couchbaseCloudCluster.ID] = couchbaseCloudCluster
+		return claimed()
 	}
+
+	return cannotClaim(resource)
 }
 
-func (cloudFormationStack *CloudformationStack) Claim(ctx *RegionalCloudContext, resource interface{}) {
+func (cloudFormationStack *CloudformationStack) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
 	switch resource.(type) {
 	case EC2Instance:
-		ctx.Claim(resource)
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
 		ec2Instance := resource.(EC2Instance)
 		cloudFormationStack.EC2Instances[ec2Instance.ID] = ec2Instance
+		return claimed()
+	case ReportableResource:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		reportableResource := resource.(ReportableResource)
+		kind := reportableResource.Kind()
+		if _, ok := cloudFormationStack.ReportableResources[kind]; !ok {
+			cloudFormationStack.ReportableResources[kind] = make(map[string]ReportableResource)
+		}
+		cloudFormationStack.ReportableResources[kind][reportableResource.Resource().ID] = reportableResource
+		return claimed()
 	}
+
+	return cannotClaim(resource)
 }
 
-func (couchbaseCloud *CouchbaseCloud) Claim(ctx *RegionalCloudContext, resource interface{}) {
+func (couchbaseCloud *CouchbaseCloud) Claim(ctx *RegionalCloudContext, resource interface{}) ClaimResult {
 	switch resource.(type) {
 	case EKSCluster:
-		ctx.Claim(resource)
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
 		eksCluster := resource.(EKSCluster)
 		couchbaseCloud.EKSClusters[eksCluster.Name] = eksCluster
 		couchbaseCloud.Seen = true
+		return claimed()
 	case CloudformationStack:
-		ctx.Claim(resource)
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
 		cloudFormationStack := resource.(CloudformationStack)
 		couchbaseCloud.CloudFormationStack = &cloudFormationStack
 		couchbaseCloud.Seen = true
+		return claimed()
+	case AKSCluster:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		aksCluster := resource.(AKSCluster)
+		couchbaseCloud.AKSClusters[aksCluster.ID] = aksCluster
+		couchbaseCloud.Seen = true
+		return claimed()
+	case AzureVirtualMachine:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		virtualMachine := resource.(AzureVirtualMachine)
+		couchbaseCloud.AzureVirtualMachines[virtualMachine.ID] = virtualMachine
+		couchbaseCloud.Seen = true
+		return claimed()
+	case GKECluster:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		gkeCluster := resource.(GKECluster)
+		couchbaseCloud.GKEClusters[gkeCluster.ID] = gkeCluster
+		couchbaseCloud.Seen = true
+		return claimed()
+	case GCPInstance:
+		if !ctx.Claim(resource) {
+			return alreadyClaimed()
+		}
+		instance := resource.(GCPInstance)
+		couchbaseCloud.GCPInstances[instance.ID] = instance
+		couchbaseCloud.Seen = true
+		return claimed()
 	}
+
+	return cannotClaim(resource)
 }
@@ -0,0 +1,104 @@
+package monitoring
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const scanWorkersEnv = "SCAN_WORKERS"
+const defaultScanWorkers = 4
+
+// regionScanner runs the collectors and enrichers of each scope's provider
+type regionScanner struct {
+	collectors             map[string][]Collector
+	enrichers              map[string][]Enricher
+	couchbaseClouds        map[string]*CouchbaseCloud
+	couchbaseCloudClusters map[string]*CouchbaseCloudCluster
+	globalCtx              *GlobalCloudContext
+	regionalViews          []RegionalCouchbaseView
+	regionalViewsMutex     sync.Mutex
+}
+
+func getScanWorkers() int {
+	value := os.Getenv(scanWorkersEnv)
+
+	if value == "" {
+		return defaultScanWorkers
+	}
+
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers < 1 {
+		log.Printf("Invalid %s value %q, using %d workers", scanWorkersEnv, value, defaultScanWorkers)
+		return defaultScanWorkers
+	}
+
+	return workers
+}
+
+func (scanner *regionScanner) scan(scope *ScanScope) {
+	start := time.Now()
+	log.Printf("Analysing %s account %s region %s", scope.Provider, scope.Account, scope.Region)
+
+	ctx, scanErrors := collectRegion(scope, scanner.collectors[scope.Provider])
+	scanErrors = append(scanErrors, enrichRegion(scope, ctx, scanner.enrichers[scope.Provider])...)
+
+	for _, scanError := range scanErrors {
+		scanner.globalCtx.AddScanError(scanError)
+	}
+
+	// Every region claims against its own copy of the Couchbase Cloud data, the copies are reconciled once all regions are scanned
+	view, err := NewRegionalCouchbaseView(scope.Account, scope.Region, scanner.couchbaseClouds, scanner.couchbaseCloudClusters)
+	if err != nil {
+		scanner.globalCtx.AddScanError(ScanError{
+			Account:   scope.Account,
+			Region:    scope.Region,
+			Kind:      ScanErrorCollect,
+			Operation: "copy Couchbase Cloud data",
+			Message:   err.Error(),
+		})
+	} else {
+		view.Attach(ctx)
+		scanner.globalCtx.OwnershipGraph.Merge(BuildOwnershipGraph(ctx))
+		log.Printf("Processing claims for account %s region %s", scope.Account, scope.Region)
+		processClaims(ctx)
+
+		scanner.regionalViewsMutex.Lock()
+		scanner.regionalViews = append(scanner.regionalViews, *view)
+		scanner.regionalViewsMutex.Unlock()
+	}
+
+	scanner.globalCtx.Add(*ctx)
+
+	log.Printf("Scanned account %s region %s in %s", scope.Account, scope.Region, time.Since(start).Round(time.Millisecond))
+}
+
+func (scanner *regionScanner) run(scopes []*ScanScope, workers int) {
+	start := time.Now()
+	jobs := make(chan *ScanScope)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for scope := range jobs {
+				scanner.scan(scope)
+			}
+		}()
+	}
+
+	for _, scope := range scopes {
+		jobs <- scope
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	scanner.globalCtx.SortRegionalCloudContexts()
+	ReconcileCouchbaseCloudData(scanner.globalCtx, scanner.regionalViews)
+	log.Printf("Scanned %d account regions with %d workers in %s", len(scopes), workers, time.Since(start).Round(time.Millisecond))
+}
@@ -0,0 +1,177 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// The same fakes serve every scope, so they only hold resources whose claims write to per-scope copies
+func newTestRegionScanner(couchbaseCloudClusters map[string]*CouchbaseCloudCluster) *regionScanner {
+	virtualMachine := NewAzureVirtualMachine()
+	virtualMachine.ID = "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Compute/virtualMachines/vm-1"
+	virtualMachine.Name = "vm-1"
+	virtualMachine.Tags = map[string]string{}
+
+	natGateway := NewNATGateway()
+	natGateway.ID = "nat-1"
+	natGateway.Tags = map[string]string{}
+
+	// Reconciling merges the regional copies back into the global Couchbase Cloud data the scan started from
+	globalCtx := NewGlobalCloudContext()
+	globalCtx.CouchbaseCloudClusters = couchbaseCloudClusters
+
+	return &regionScanner{
+		collectors: map[string][]Collector{
+			ProviderAWS: {
+				&FakeCollector{
+					CollectorName: "Fake EC2 instances",
+					EC2Instances: []EC2Instance{
+						{Compute: Compute{CloudResource: CloudResource{ID: "i-db", Tags: map[string]string{EC2ClusterIdTagName: "db-1"}}}},
+						{Compute: Compute{CloudResource: CloudResource{ID: "i-other", Tags: map[string]string{}}}},
+					},
+					Resources: []ReportableResource{*natGateway},
+				},
+				&FakeCollector{CollectorName: "Broken", Err: errors.New("access denied")},
+			},
+			ProviderAzure: {
+				&FakeCollector{CollectorName: "Fake Azure virtual machines", Resources: []ReportableResource{*virtualMachine}},
+			},
+		},
+		enrichers: map[string][]Enricher{
+			ProviderAWS:   {NewExpiryEnricher(&ExpiryPolicy{})},
+			ProviderAzure: {NewExpiryEnricher(&ExpiryPolicy{})},
+		},
+		couchbaseClouds:        map[string]*CouchbaseCloud{},
+		couchbaseCloudClusters: couchbaseCloudClusters,
+		globalCtx:              globalCtx,
+	}
+}
+
+func TestRegionScannerRun(t *testing.T) {
+	var scopes []*ScanScope
+	var awsLocations []string
+
+	for _, account := range []string{"222222222222", "111111111111"} {
+		for _, region := range []string{"us-west-2", "eu-west-1", "ap-south-1"} {
+			scopes = append(scopes, &ScanScope{Provider: ProviderAWS, Account: account, Region: region})
+			awsLocations = append(awsLocations, fmt.Sprintf("%s/%s", account, region))
+		}
+	}
+	scopes = append(scopes, &ScanScope{Provider: ProviderAzure, Account: "sub-1", Region: "eastus"})
+	sort.Strings(awsLocations)
+
+	couchbaseCloudCluster := newTestCouchbaseCloudCluster("db-1")
+	scanner := newTestRegionScanner(map[string]*CouchbaseCloudCluster{"db-1": couchbaseCloudCluster})
+	globalCtx := scanner.globalCtx
+
+	scanner.run(scopes, 4)
+
+	if len(globalCtx.RegionalCloudContexts) != len(scopes) {
+		t.Fatalf("expected %d regional contexts, got %d", len(scopes), len(globalCtx.RegionalCloudContexts))
+	}
+
+	for i := 1; i < len(globalCtx.RegionalCloudContexts); i++ {
+		previous, current := globalCtx.RegionalCloudContexts[i-1], globalCtx.RegionalCloudContexts[i]
+		if previous.Account > current.Account || (previous.Account == current.Account && previous.Region > current.Region) {
+			t.Errorf("regional contexts are not sorted, %s/%s comes before %s/%s", previous.Account, previous.Region, current.Account, current.Region)
+		}
+	}
+
+	for _, ctx := range globalCtx.RegionalCloudContexts {
+		if ctx.ClaimReport == nil {
+			t.Errorf("expected claims to be processed in %s/%s", ctx.Account, ctx.Region)
+			continue
+		}
+
+		if ctx.Account == "sub-1" {
+			if len(ctx.EC2Instances) != 0 || len(ctx.Resources[ResourceTypeAzureVirtualMachine]) != 1 {
+				t.Errorf("expected the Azure scope to only run the Azure collectors, got %d EC2 instances and %d virtual machines",
+					len(ctx.EC2Instances), len(ctx.Resources[ResourceTypeAzureVirtualMachine]))
+			}
+			if ctx.ExpiryCounts[ExpiryMissingTTL] != 1 {
+				t.Errorf("expected the Azure enrichers to run, got expiry counts %v", ctx.ExpiryCounts)
+			}
+			continue
+		}
+
+		if _, ok := ctx.EC2Instances["i-db"]; ok {
+			t.Errorf("expected i-db to be claimed by db-1 in %s/%s", ctx.Account, ctx.Region)
+		}
+		if ec2Instance, ok := ctx.EC2Instances["i-other"]; !ok || ec2Instance.Account != ctx.Account || ec2Instance.Region != ctx.Region {
+			t.Errorf("expected i-other to be left unclaimed in %s/%s, got %+v", ctx.Account, ctx.Region, ec2Instance.CloudResource)
+		}
+		if ctx.ClaimReport.Unclaimed[ResourceTypeNATGateway] != 1 {
+			t.Errorf("expected nat-1 to be left unclaimed in %s/%s", ctx.Account, ctx.Region)
+		}
+		// i-db is counted before it is claimed, i-other and nat-1 after
+		if ctx.ExpiryCounts[ExpiryMissingTTL] != 3 {
+			t.Errorf("expected 3 resources without a TTL in %s/%s, got %v", ctx.Account, ctx.Region, ctx.ExpiryCounts)
+		}
+	}
+
+	awsScopes := len(scopes) - 1
+	if len(globalCtx.ScanErrors) != awsScopes {
+		t.Fatalf("expected a scan error for every AWS scope, got %+v", globalCtx.ScanErrors)
+	}
+	for i, scanError := range globalCtx.ScanErrors {
+		if scanError.Operation != "get Broken" || scanError.Message != "access denied" {
+			t.Errorf("unexpected scan error %+v", scanError)
+		}
+		if location := fmt.Sprintf("%s/%s", scanError.Account, scanError.Region); location != awsLocations[i] {
+			t.Errorf("expected scan errors sorted by account and region, got %s at %d", location, i)
+		}
+	}
+
+	if couchbaseCloudCluster.MatchStatus != CouchbaseMatchMultipleRegions {
+		t.Errorf("expected db-1 to be matched in multiple regions, got %s", couchbaseCloudCluster.MatchStatus)
+	}
+	if !reflect.DeepEqual(couchbaseCloudCluster.MatchedRegions, awsLocations) {
+		t.Errorf("expected db-1 to be matched in every AWS scope in order, got %v", couchbaseCloudCluster.MatchedRegions)
+	}
+	if _, ok := couchbaseCloudCluster.EC2Instances["i-db"]; !ok {
+		t.Errorf("expected the reconciled db-1 to hold i-db")
+	}
+
+	ec2Nodes := 0
+	for _, node := range globalCtx.OwnershipGraph.Nodes {
+		if node.Type == NodeEC2Instance {
+			ec2Nodes++
+		}
+	}
+	if ec2Nodes != 2*awsScopes {
+		t.Errorf("expected the EC2 instances of every scope in the ownership graph, got %d nodes", ec2Nodes)
+	}
+}
+
+// TestRegionScannerRunWorkers checks that the result does not depend on the number of workers, run it with -race
+func TestRegionScannerRunWorkers(t *testing.T) {
+	var scopes []*ScanScope
+	for i := 0; i < 20; i++ {
+		scopes = append(scopes, &ScanScope{Provider: ProviderAWS, Account: fmt.Sprintf("%012d", i%4), Region: fmt.Sprintf("region-%d", i)})
+	}
+
+	var matchedRegions [][]string
+
+	for _, workers := range []int{1, 3, 8} {
+		couchbaseCloudCluster := newTestCouchbaseCloudCluster("db-1")
+		scanner := newTestRegionScanner(map[string]*CouchbaseCloudCluster{"db-1": couchbaseCloudCluster})
+
+		scanner.run(scopes, workers)
+
+		if len(scanner.globalCtx.RegionalCloudContexts) != len(scopes) || len(scanner.globalCtx.ScanErrors) != len(scopes) {
+			t.Errorf("with %d workers expected %d regional contexts and scan errors, got %d and %d", workers, len(scopes),
+				len(scanner.globalCtx.RegionalCloudContexts), len(scanner.globalCtx.ScanErrors))
+		}
+
+		matchedRegions = append(matchedRegions, couchbaseCloudCluster.MatchedRegions)
+	}
+
+	for i := 1; i < len(matchedRegions); i++ {
+		if !reflect.DeepEqual(matchedRegions[0], matchedRegions[i]) {
+			t.Errorf("matched regions differ between worker counts: %v and %v", matchedRegions[0], matchedRegions[i])
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package monitoring
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const utilisationWindowEnv = "UTILISATION_WINDOW"
+const reportEC2UtilisationEnv = "REPORT_EC2_UTILISATION"
+
+const defaultUtilisationWindow = 14 * 24 * time.Hour
+const utilisationPeriod = time.Hour
+
+// GetMetricData accepts at most 500 queries per request, and every instance needs 4
+const maxMetricDataQueries = 500
+const metricQueriesPerInstance = 4
+
+const (
+	UtilisationIdle          = "idle"
+	UtilisationUnderUtilised = "under-utilised"
+	UtilisationActive        = "active"
+	UtilisationUnknown       = "unknown"
+)
+
+// Idle instances barely use CPU at any point and move less than 5 MB a day, under-utilised ones never come close to their capacity
+const (
+	idleCPUAverage          = 2.0
+	idleCPUMax              = 10.0
+	idleNetworkBytesPerDay  = 5 * 1000 * 1000
+	underUtilisedCPUAverage = 10.0
+	underUtilisedCPUMax     = 40.0
+)
+
+// Utilisation is measured over Window, Covered is the part of it that CloudWatch has network data for, which is shorter
+// for instances launched or stopped during the window
+type Utilisation struct {
+	Class      string
+	Window     time.Duration
+	Covered    time.Duration
+	CPUAverage float64
+	CPUMax     float64
+	NetworkIn  float64
+	NetworkOut float64
+	DataPoints int
+}
+
+func (utilisation Utilisation) GetNetworkBytesPerDay() float64 {
+	days := utilisation.Covered.Hours() / 24
+	if days <= 0 {
+		return 0
+	}
+
+	return (utilisation.NetworkIn + utilisation.NetworkOut) / days
+}
+
+func (utilisation Utilisation) classify() string {
+	if utilisation.DataPoints == 0 {
+		return UtilisationUnknown
+	}
+
+	if utilisation.CPUAverage < idleCPUAverage && utilisation.CPUMax < idleCPUMax && utilisation.GetNetworkBytesPerDay() < idleNetworkBytesPerDay {
+		return UtilisationIdle
+	}
+
+	if utilisation.CPUAverage < underUtilisedCPUAverage && utilisation.CPUMax < underUtilisedCPUMax {
+		return UtilisationUnderUtilised
+	}
+
+	return UtilisationActive
+}
+
+func getUtilisationWindow() time.Duration {
+	value := os.Getenv(utilisationWindowEnv)
+	if value == "" {
+		return defaultUtilisationWindow
+	}
+
+	window, err := parseTTL(value)
+	if err != nil || window < utilisationPeriod {
+		log.Printf("Invalid %s %q, using %s", utilisationWindowEnv, value, defaultUtilisationWindow)
+		return defaultUtilisationWindow
+	}
+
+	return window
+}
+
+// GetReportedUtilisationClasses returns the classes EC2 instances are limited to in the report, or nil to report them all
+func GetReportedUtilisationClasses() map[string]bool {
+	classes := split(os.Getenv(reportEC2UtilisationEnv))
+	if len(classes) == 0 {
+		return nil
+	}
+
+	reported := map[string]bool{}
+	for _, class := range classes {
+		reported[strings.ToLower(class)] = true
+	}
+
+	return reported
+}
+
+type UtilisationEnricher struct {
+	Window time.Duration
+}
+
+func NewUtilisationEnricher() *UtilisationEnricher {
+	return &UtilisationEnricher{Window: getUtilisationWindow()}
+}
+
+func (enricher *UtilisationEnricher) Name() string {
+	return "utilisation from CloudWatch"
+}
+
+func (enricher *UtilisationEnricher) Enrich(scope *ScanScope, ctx *RegionalCloudContext) error {
+	var ids []string
+	for id, ec2Instance := range ctx.EC2Instances {
+		if !ec2Instance.IsStopped() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cloudWatchService := cloudwatch.New(scope.Session, getAWSConfig(scope.Credentials, scope.Region))
+	end := time.Now().Truncate(utilisationPeriod)
+	start := end.Add(-enricher.Window)
+	batchSize := maxMetricDataQueries / metricQueriesPerInstance
+
+	// A failed batch leaves its instances unknown and the rest are still measured
+	var batchErr error
+	failed := 0
+
+	for i := 0; i < len(ids); i += batchSize {
+		batch := ids[i:minInt(i+batchSize, len(ids))]
+		utilisations, err := getUtilisations(cloudWatchService, batch, start, end)
+
+		if err != nil {
+			log.Printf("Unable to get CloudWatch metrics for %d EC2 instances in account %s region %s: %s", len(batch), scope.Account, scope.Region, err)
+			batchErr = err
+			failed += len(batch)
+
+			for _, id := range batch {
+				ec2Instance := ctx.EC2Instances[id]
+				ec2Instance.Utilisation = Utilisation{Class: UtilisationUnknown, Window: enricher.Window}
+				ctx.EC2Instances[id] = ec2Instance
+			}
+			continue
+		}
+
+		for id, utilisation := range utilisations {
+			utilisation.Window = enricher.Window
+			utilisation.Class = utilisation.classify()
+
+			ec2Instance := ctx.EC2Instances[id]
+			ec2Instance.Utilisation = utilisation
+			ctx.EC2Instances[id] = ec2Instance
+		}
+	}
+
+	log.Printf("Found utilisation for %d EC2 instances in account %s region %s", len(ids)-failed, scope.Account, scope.Region)
+
+	if batchErr != nil {
+		return fmt.Errorf("unable to get CloudWatch metrics for %d of %d EC2 instances %w", failed, len(ids), batchErr)
+	}
+
+	return nil
+}
+
+func getMetricDataQuery(queryId string, instanceId string, metricName string, stat string) *cloudwatch.MetricDataQuery {
+	return &cloudwatch.MetricDataQuery{
+		Id: aws.String(queryId),
+		MetricStat: &cloudwatch.MetricStat{
+			Metric: &cloudwatch.Metric{
+				Namespace:  aws.String("AWS/EC2"),
+				MetricName: aws.String(metricName),
+				Dimensions: []*cloudwatch.Dimension{{Name: aws.String("InstanceId"), Value: aws.String(instanceId)}},
+			},
+			Period: aws.Int64(int64(utilisationPeriod.Seconds())),
+			Stat:   aws.String(stat),
+		},
+	}
+}
+
+func getUtilisations(cloudWatchService *cloudwatch.CloudWatch, instanceIds []string, start time.Time, end time.Time) (map[string]Utilisation, error) {
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(start),
+		EndTime:   aws.Time(end),
+	}
+
+	// Query ids must start with a lower case letter, so instances are referred to by their position in the batch
+	for i, instanceId := range instanceIds {
+		input.MetricDataQueries = append(input.MetricDataQueries,
+			getMetricDataQuery(fmt.Sprintf("i%d_cpuavg", i), instanceId, "CPUUtilization", cloudwatch.StatisticAverage),
+			getMetricDataQuery(fmt.Sprintf("i%d_cpumax", i), instanceId, "CPUUtilization", cloudwatch.StatisticMaximum),
+			getMetricDataQuery(fmt.Sprintf("i%d_netin", i), instanceId, "NetworkIn", cloudwatch.StatisticSum),
+			getMetricDataQuery(fmt.Sprintf("i%d_netout", i), instanceId, "NetworkOut", cloudwatch.StatisticSum),
+		)
+	}
+
+	utilisations := make([]Utilisation, len(instanceIds))
+	cpuSums := make([]float64, len(instanceIds))
+	networkPeriods := make([]map[int64]bool, len(instanceIds))
+	for i := range networkPeriods {
+		networkPeriods[i] = map[int64]bool{}
+	}
+
+	err := cloudWatchService.GetMetricDataPages(input, func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+		for _, result := range page.MetricDataResults {
+			var index int
+			var metric string
+
+			if _, err := fmt.Sscanf(strings.Replace(aws.StringValue(result.Id), "_", " ", 1), "i%d %s", &index, &metric); err != nil || index >= len(instanceIds) {
+				continue
+			}
+
+			timestamps := aws.TimeValueSlice(result.Timestamps)
+
+			for idx, value := range aws.Float64ValueSlice(result.Values) {
+				if (metric == "netin" || metric == "netout") && idx < len(timestamps) {
+					networkPeriods[index][timestamps[idx].Unix()] = true
+				}
+
+				switch metric {
+				case "cpuavg":
+					cpuSums[index] += value
+					utilisations[index].DataPoints++
+				case "cpumax":
+					if value > utilisations[index].CPUMax {
+						utilisations[index].CPUMax = value
+					}
+				case "netin":
+					utilisations[index].NetworkIn += value
+				case "netout":
+					utilisations[index].NetworkOut += value
+				}
+			}
+		}
+
+		return !lastPage
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	utilisationsById := map[string]Utilisation{}
+	for i, instanceId := range instanceIds {
+		if utilisations[i].DataPoints > 0 {
+			utilisations[i].CPUAverage = cpuSums[i] / float64(utilisations[i].DataPoints)
+		}
+		utilisations[i].Covered = time.Duration(len(networkPeriods[i])) * utilisationPeriod
+
+		utilisationsById[instanceId] = utilisations[i]
+	}
+
+	return utilisationsById, nil
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
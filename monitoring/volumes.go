@@ -0,0 +1,141 @@
+package monitoring
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"log"
+	"sort"
+	"time"
+)
+
+const EBSVolumeStateAvailable = "available"
+
+const (
+	DetachedAtSourceCloudTrail = "CloudTrail"
+	// No detach event was found since the volume was created, so as far as CloudTrail knows it was never attached
+	DetachedAtSourceCreated = "created"
+)
+
+const (
+	RecommendDelete            = "delete"
+	RecommendSnapshotAndDelete = "snapshot and delete"
+)
+
+// IsUnattached is true for volumes that are not attached to any instance but are still billed
+func (ebsVolume EBSVolume) IsUnattached() bool {
+	return ebsVolume.State == EBSVolumeStateAvailable
+}
+
+// GetDaysDetached returns false when the volume was detached before CloudTrail's retention
+func (ebsVolume EBSVolume) GetDaysDetached(now time.Time) (int, bool) {
+	if ebsVolume.DetachedAt.IsZero() {
+		return 0, false
+	}
+
+	return int(now.Sub(ebsVolume.DetachedAt).Hours() / 24), true
+}
+
+// GetRecommendedAction only recommends deleting outright when the volume has never been attached, so holds no data of its own
+func (ebsVolume EBSVolume) GetRecommendedAction() string {
+	if ebsVolume.DetachedAtSource == DetachedAtSourceCreated {
+		return RecommendDelete
+	}
+
+	return RecommendSnapshotAndDelete
+}
+
+type DetachedVolumeEnricher struct{}
+
+func (enricher *DetachedVolumeEnricher) Name() string {
+	return "detach times from CloudTrail"
+}
+
+func (enricher *DetachedVolumeEnricher) Enrich(scope *ScanScope, ctx *RegionalCloudContext) error {
+	oldestRetained := time.Now().Add(-cloudTrailRetention)
+	since := time.Now()
+	unattached := map[string]bool{}
+
+	for id, ebsVolume := range ctx.EBSVolumes {
+		if !ebsVolume.IsUnattached() {
+			continue
+		}
+
+		unattached[id] = true
+
+		if ebsVolume.CreatedAt.Before(since) {
+			since = ebsVolume.CreatedAt
+		}
+	}
+
+	if len(unattached) == 0 {
+		return nil
+	}
+
+	if since.Before(oldestRetained) {
+		since = oldestRetained
+	}
+
+	cloudTrailService := cloudtrail.New(scope.Session, getAWSConfig(scope.Credentials, scope.Region))
+	detachedAt := map[string]time.Time{}
+
+	// Events are returned newest first, so the first detach seen for a volume is its last
+	lookupErr := lookupCloudTrailEvents(cloudTrailService, "DetachVolume", "ec2.amazonaws.com", since, func(event *cloudtrail.Event, detail cloudTrailEventDetail) bool {
+		ids := getRequestParameterIds("volumeId")(detail)
+		for _, resource := range event.Resources {
+			ids = append(ids, aws.StringValue(resource.ResourceName))
+		}
+
+		for _, id := range ids {
+			if _, ok := detachedAt[id]; unattached[id] && !ok && event.EventTime != nil {
+				detachedAt[id] = *event.EventTime
+			}
+		}
+
+		return len(detachedAt) < len(unattached)
+	})
+
+	if lookupErr != nil {
+		lookupErr = fmt.Errorf("unable to look up DetachVolume events %w", lookupErr)
+	}
+
+	for id := range unattached {
+		ebsVolume := ctx.EBSVolumes[id]
+
+		if eventTime, ok := detachedAt[id]; ok {
+			ebsVolume.DetachedAt = eventTime
+			ebsVolume.DetachedAtSource = DetachedAtSourceCloudTrail
+		} else if lookupErr == nil && ebsVolume.CreatedAt.After(oldestRetained) {
+			ebsVolume.DetachedAt = ebsVolume.CreatedAt
+			ebsVolume.DetachedAtSource = DetachedAtSourceCreated
+		}
+
+		ctx.EBSVolumes[id] = ebsVolume
+	}
+
+	log.Printf("Found detach times for %d of %d unattached EBS volumes in account %s region %s", len(detachedAt), len(unattached), scope.Account, scope.Region)
+	return lookupErr
+}
+
+// GetUnattachedEBSVolumes returns every unattached volume, longest detached first
+func (ctx *GlobalCloudContext) GetUnattachedEBSVolumes() []EBSVolume {
+	var ebsVolumes []EBSVolume
+
+	for _, regionalCtx := range ctx.RegionalCloudContexts {
+		for _, ebsVolume := range regionalCtx.EBSVolumes {
+			if ebsVolume.IsUnattached() {
+				ebsVolumes = append(ebsVolumes, ebsVolume)
+			}
+		}
+	}
+
+	sort.SliceStable(ebsVolumes, func(i, j int) bool {
+		if ebsVolumes[i].DetachedAt.IsZero() != ebsVolumes[j].DetachedAt.IsZero() {
+			return ebsVolumes[i].DetachedAt.IsZero()
+		}
+
+		return ebsVolumes[i].DetachedAt.Before(ebsVolumes[j].DetachedAt)
+	})
+
+	return ebsVolumes
+}
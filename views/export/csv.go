@@ -0,0 +1,193 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvColumns = []string{"id", "name", "provider", "account", "region", "created_at", "age_days", "launched_by", "estimated_hourly_cost", "estimated_monthly_cost", "parent_type", "parent_id", "tags"}
+
+// FlatResource is a resource taken out of the hierarchy. Parent is the resource that claimed it, nil for unclaimed ones.
+type FlatResource struct {
+	Resource Resource
+	Parent   *Resource
+}
+
+// Flatten lists every resource in the hierarchy once by type. Resources reported both on their own and under an owner,
+// such as Couchbase Cloud clusters, keep their owner.
+func Flatten(resources []Resource) map[string][]FlatResource {
+	flatByType := map[string][]FlatResource{}
+	indexes := map[string]int{}
+
+	var visit func(resource Resource, parent *Resource)
+	visit = func(resource Resource, parent *Resource) {
+		if index, ok := indexes[resource.String()]; ok {
+			if parent != nil && flatByType[resource.Type][index].Parent == nil {
+				flatByType[resource.Type][index].Parent = parent
+			}
+			return
+		}
+
+		indexes[resource.String()] = len(flatByType[resource.Type])
+		flatByType[resource.Type] = append(flatByType[resource.Type], FlatResource{Resource: resource, Parent: parent})
+
+		for _, child := range resource.Children {
+			owner := resource
+			visit(child, &owner)
+		}
+	}
+
+	for _, resource := range resources {
+		visit(resource, nil)
+	}
+
+	return flatByType
+}
+
+func getDetailColumns(rows []FlatResource) []string {
+	columns := map[string]bool{}
+	for _, row := range rows {
+		for key := range row.Resource.Details {
+			columns[key] = true
+		}
+	}
+
+	var sorted []string
+	for column := range columns {
+		sorted = append(sorted, column)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func getCSVRecord(row FlatResource, detailColumns []string) []string {
+	resource := row.Resource
+	record := []string{resource.ID, resource.Name, resource.Provider, resource.Account, resource.Region, "", "", resource.LaunchedBy, "", "", "", "", getTagsValue(resource.Tags)}
+
+	if resource.CreatedAt != nil {
+		record[5] = resource.CreatedAt.Format(time.RFC3339)
+		record[6] = strconv.Itoa(*resource.AgeDays)
+	}
+
+	if resource.EstimatedHourlyCost != nil {
+		record[8] = strconv.FormatFloat(*resource.EstimatedHourlyCost, 'f', 4, 64)
+		record[9] = strconv.FormatFloat(*resource.EstimatedMonthlyCost, 'f', 2, 64)
+	}
+
+	if row.Parent != nil {
+		record[10] = row.Parent.Type
+		record[11] = row.Parent.ID
+	}
+
+	for _, column := range detailColumns {
+		record = append(record, resource.Details[column])
+	}
+
+	return record
+}
+
+func getTagsValue(tags map[string]string) string {
+	var pairs []string
+	for key, value := range tags {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ";")
+}
+
+func writeCSVRows(writer io.Writer, rows []FlatResource, withType bool) error {
+	csvWriter := csv.NewWriter(writer)
+	detailColumns := getDetailColumns(rows)
+
+	header := append(append([]string{}, csvColumns...), detailColumns...)
+	if withType {
+		header = append([]string{"type"}, header...)
+	}
+
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := getCSVRecord(row, detailColumns)
+		if withType {
+			record = append([]string{row.Resource.Type}, record...)
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// WriteCSV writes every resource type to a single CSV with a leading type column
+func WriteCSV(writer io.Writer, inventory *Inventory) error {
+	rowsByType := Flatten(inventory.Resources)
+
+	var rows []FlatResource
+	for _, resourceType := range getSortedTypes(rowsByType) {
+		rows = append(rows, rowsByType[resourceType]...)
+	}
+
+	return writeCSVRows(writer, rows, true)
+}
+
+// WriteCSVFiles writes one <type>.csv file per resource type into directory
+func WriteCSVFiles(directory string, inventory *Inventory) ([]string, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+
+	rowsByType := Flatten(inventory.Resources)
+	var paths []string
+
+	for _, resourceType := range getSortedTypes(rowsByType) {
+		path := filepath.Join(directory, fmt.Sprintf("%s.csv", getFileName(resourceType)))
+
+		if err := writeFile(path, func(writer io.Writer) error { return writeCSVRows(writer, rowsByType[resourceType], false) }); err != nil {
+			return paths, err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+func getSortedTypes(flatByType map[string][]FlatResource) []string {
+	var resourceTypes []Resource
+	for resourceType := range flatByType {
+		resourceTypes = append(resourceTypes, Resource{Type: resourceType})
+	}
+	sortResources(resourceTypes)
+
+	var sorted []string
+	for _, resource := range resourceTypes {
+		sorted = append(sorted, resource.Type)
+	}
+
+	return sorted
+}
+
+// Kinds added by collectors are free text, so they are made safe to use as file names
+func getFileName(resourceType string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+
+		return '-'
+	}, strings.ToLower(resourceType))
+}
@@ -0,0 +1,322 @@
+package export
+
+import (
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resource is a single resource in the inventory. Children are the resources it claimed in the report hierarchy.
+type Resource struct {
+	Type                 string            `json:"type"`
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name,omitempty"`
+	Provider             string            `json:"provider,omitempty"`
+	Account              string            `json:"account,omitempty"`
+	Region               string            `json:"region,omitempty"`
+	CreatedAt            *time.Time        `json:"createdAt,omitempty"`
+	AgeDays              *int              `json:"ageDays,omitempty"`
+	LaunchedBy           string            `json:"launchedBy,omitempty"`
+	EstimatedHourlyCost  *float64          `json:"estimatedHourlyCost,omitempty"`
+	EstimatedMonthlyCost *float64          `json:"estimatedMonthlyCost,omitempty"`
+	Tags                 map[string]string `json:"tags,omitempty"`
+	Details              map[string]string `json:"details,omitempty"`
+	Children             []Resource        `json:"children,omitempty"`
+	// OwnCost excludes the resources this one claimed, so it can be summed without counting anything twice
+	OwnCost monitoring.Cost `json:"-"`
+}
+
+type Inventory struct {
+	GeneratedAt    time.Time           `json:"generatedAt"`
+	AccountRegions map[string][]string `json:"accountRegions,omitempty"`
+	Resources      []Resource          `json:"resources"`
+}
+
+// Top level resources are ordered the same way as the sections of the Slack report
+var resourceTypeOrder = map[string]int{
+	string(monitoring.NodeCouchbaseCloud):        0,
+	string(monitoring.NodeCouchbaseCloudCluster): 1,
+	string(monitoring.NodeCloudformationStack):   2,
+	string(monitoring.NodeEKSCluster):            3,
+	string(monitoring.NodeEC2Instance):           4,
+	string(monitoring.NodeEBSVolume):             5,
+}
+
+func NewInventory(ctx *monitoring.GlobalCloudContext) *Inventory {
+	now := time.Now()
+	inventory := &Inventory{
+		GeneratedAt:    now.UTC(),
+		AccountRegions: ctx.AccountRegions,
+	}
+
+	for _, couchbaseCloud := range ctx.CouchbaseClouds {
+		inventory.Resources = append(inventory.Resources, getCouchbaseCloudResource(*couchbaseCloud, now))
+	}
+
+	for _, couchbaseCloudCluster := range ctx.CouchbaseCloudClusters {
+		inventory.Resources = append(inventory.Resources, getCouchbaseCloudClusterResource(*couchbaseCloudCluster, now))
+	}
+
+	for _, regionalCtx := range ctx.RegionalCloudContexts {
+		for _, cloudformationStack := range regionalCtx.CloudFormationStacks {
+			inventory.Resources = append(inventory.Resources, getCloudformationStackResource(cloudformationStack, now))
+		}
+
+		for _, eksCluster := range regionalCtx.EKSClusters {
+			inventory.Resources = append(inventory.Resources, getEKSClusterResource(eksCluster, now))
+		}
+
+		for _, ec2Instance := range regionalCtx.EC2Instances {
+			inventory.Resources = append(inventory.Resources, getEC2InstanceResource(ec2Instance, now))
+		}
+
+		for _, ebsVolume := range regionalCtx.EBSVolumes {
+			inventory.Resources = append(inventory.Resources, getEBSVolumeResource(ebsVolume, now))
+		}
+
+		for kind, resources := range regionalCtx.Resources {
+			for _, resource := range resources {
+				inventory.Resources = append(inventory.Resources, getReportableResource(kind, resource, now))
+			}
+		}
+	}
+
+	sortResources(inventory.Resources)
+	return inventory
+}
+
+func sortResources(resources []Resource) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Type != resources[j].Type {
+			iOrder, iOk := resourceTypeOrder[resources[i].Type]
+			jOrder, jOk := resourceTypeOrder[resources[j].Type]
+
+			if iOk != jOk {
+				return iOk
+			}
+
+			if iOrder != jOrder {
+				return iOrder < jOrder
+			}
+
+			return resources[i].Type < resources[j].Type
+		}
+
+		if resources[i].Account != resources[j].Account {
+			return resources[i].Account < resources[j].Account
+		}
+
+		if resources[i].Region != resources[j].Region {
+			return resources[i].Region < resources[j].Region
+		}
+
+		return resources[i].ID < resources[j].ID
+	})
+}
+
+func newResource(resourceType string, cloudResource monitoring.CloudResource, cost monitoring.Cost, now time.Time) Resource {
+	resource := Resource{
+		Type:       resourceType,
+		ID:         cloudResource.ID,
+		Name:       cloudResource.Name,
+		Provider:   cloudResource.Provider,
+		Account:    cloudResource.Account,
+		Region:     cloudResource.Region,
+		LaunchedBy: cloudResource.LaunchedBy,
+		Tags:       cloudResource.Tags,
+		Details:    map[string]string{},
+		OwnCost:    cloudResource.EstimatedCost,
+	}
+
+	if !cloudResource.CreatedAt.IsZero() {
+		createdAt := cloudResource.CreatedAt.UTC()
+		ageDays := int(now.Sub(createdAt).Hours() / 24)
+		resource.CreatedAt = &createdAt
+		resource.AgeDays = &ageDays
+	}
+
+	if cost.Priced {
+		hourly := cost.Hourly
+		monthly := cost.Monthly()
+		resource.EstimatedHourlyCost = &hourly
+		resource.EstimatedMonthlyCost = &monthly
+	}
+
+	resource.setDetail("expiry", cloudResource.Expiry.Status)
+
+	return resource
+}
+
+func getCouchbaseCloudResource(couchbaseCloud monitoring.CouchbaseCloud, now time.Time) Resource {
+	resource := newResource(string(monitoring.NodeCouchbaseCloud), couchbaseCloud.CloudResource, couchbaseCloud.TotalCost(), now)
+	resource.setDetail("status", couchbaseCloud.Status)
+	resource.setDetail("virtualNetworkCIDR", couchbaseCloud.VirtualNetworkCIDR)
+	resource.setDetail("matchStatus", couchbaseCloud.MatchStatus)
+	resource.setDetail("matchedRegions", strings.Join(couchbaseCloud.MatchedRegions, " "))
+
+	for _, eksCluster := range couchbaseCloud.EKSClusters {
+		resource.Children = append(resource.Children, getEKSClusterResource(eksCluster, now))
+	}
+
+	if couchbaseCloud.CloudFormationStack != nil {
+		resource.Children = append(resource.Children, getCloudformationStackResource(*couchbaseCloud.CloudFormationStack, now))
+	}
+
+	for _, aksCluster := range couchbaseCloud.AKSClusters {
+		resource.Children = append(resource.Children, getReportableResource(aksCluster.Kind(), aksCluster, now))
+	}
+
+	for _, virtualMachine := range couchbaseCloud.AzureVirtualMachines {
+		resource.Children = append(resource.Children, getReportableResource(virtualMachine.Kind(), virtualMachine, now))
+	}
+
+	for _, gkeCluster := range couchbaseCloud.GKEClusters {
+		resource.Children = append(resource.Children, getReportableResource(gkeCluster.Kind(), gkeCluster, now))
+	}
+
+	for _, instance := range couchbaseCloud.GCPInstances {
+		resource.Children = append(resource.Children, getReportableResource(instance.Kind(), instance, now))
+	}
+
+	sortResources(resource.Children)
+	return resource
+}
+
+func getCouchbaseCloudClusterResource(couchbaseCloudCluster monitoring.CouchbaseCloudCluster, now time.Time) Resource {
+	resource := newResource(string(monitoring.NodeCouchbaseCloudCluster), couchbaseCloudCluster.CloudResource, couchbaseCloudCluster.TotalCost(), now)
+	resource.setDetail("nodeCount", strconv.Itoa(couchbaseCloudCluster.NodeCount))
+	resource.setDetail("services", strings.Join(couchbaseCloudCluster.Services, " "))
+	resource.setDetail("environment", couchbaseCloudCluster.Environment)
+	resource.setDetail("matchStatus", couchbaseCloudCluster.MatchStatus)
+
+	for _, ec2Instance := range couchbaseCloudCluster.EC2Instances {
+		resource.Children = append(resource.Children, getEC2InstanceResource(ec2Instance, now))
+	}
+
+	sortResources(resource.Children)
+	return resource
+}
+
+func getCloudformationStackResource(cloudformationStack monitoring.CloudformationStack, now time.Time) Resource {
+	resource := newResource(string(monitoring.NodeCloudformationStack), cloudformationStack.CloudResource, cloudformationStack.TotalCost(), now)
+	resource.setDetail("resourceCount", strconv.Itoa(len(cloudformationStack.Resources)))
+
+	for _, eksCluster := range cloudformationStack.EKSClusters {
+		resource.Children = append(resource.Children, getEKSClusterResource(eksCluster, now))
+	}
+
+	for _, ec2Instance := range cloudformationStack.EC2Instances {
+		resource.Children = append(resource.Children, getEC2InstanceResource(ec2Instance, now))
+	}
+
+	for kind, reportableResources := range cloudformationStack.ReportableResources {
+		for _, reportableResource := range reportableResources {
+			resource.Children = append(resource.Children, getReportableResource(kind, reportableResource, now))
+		}
+	}
+
+	sortResources(resource.Children)
+	return resource
+}
+
+func getEKSClusterResource(eksCluster monitoring.EKSCluster, now time.Time) Resource {
+	// EKS clusters are identified by name everywhere else in the tool
+	cloudResource := eksCluster.CloudResource
+	cloudResource.ID = eksCluster.Name
+
+	resource := newResource(string(monitoring.NodeEKSCluster), cloudResource, eksCluster.TotalCost(), now)
+	resource.setDetail("vpcId", eksCluster.NetworkID)
+	resource.setDetail("version", eksCluster.Version)
+	resource.setDetail("state", eksCluster.State)
+	resource.setDetail("subnets", strconv.Itoa(len(eksCluster.SubnetIDs)))
+
+	for _, couchbaseCloudCluster := range eksCluster.CouchbaseCloudClusters {
+		resource.Children = append(resource.Children, getCouchbaseCloudClusterResource(couchbaseCloudCluster, now))
+	}
+
+	for _, ec2Instance := range eksCluster.EC2Instances {
+		resource.Children = append(resource.Children, getEC2InstanceResource(ec2Instance, now))
+	}
+
+	for _, loadBalancer := range eksCluster.LoadBalancers {
+		resource.Children = append(resource.Children, getReportableResource(loadBalancer.Kind(), loadBalancer, now))
+	}
+
+	sortResources(resource.Children)
+	return resource
+}
+
+func getEC2InstanceResource(ec2Instance monitoring.EC2Instance, now time.Time) Resource {
+	resource := newResource(string(monitoring.NodeEC2Instance), ec2Instance.CloudResource, ec2Instance.TotalCost(), now)
+	resource.setDetail("instanceType", ec2Instance.InstanceType)
+	resource.setDetail("state", ec2Instance.State)
+	resource.setDetail("platform", ec2Instance.Platform)
+	resource.setDetail("keyName", ec2Instance.KeyName)
+	resource.setDetail("subnetId", ec2Instance.SubnetID)
+	resource.setDetail("utilisation", ec2Instance.Utilisation.Class)
+
+	if !ec2Instance.StoppedAt.IsZero() {
+		resource.setDetail("stoppedAt", ec2Instance.StoppedAt.UTC().Format(time.RFC3339))
+	}
+
+	for _, ebsVolume := range ec2Instance.EBSVolumes {
+		resource.Children = append(resource.Children, getEBSVolumeResource(ebsVolume, now))
+	}
+
+	sortResources(resource.Children)
+	return resource
+}
+
+func getEBSVolumeResource(ebsVolume monitoring.EBSVolume, now time.Time) Resource {
+	resource := newResource(string(monitoring.NodeEBSVolume), ebsVolume.CloudResource, ebsVolume.EstimatedCost, now)
+	resource.setDetail("sizeGiB", strconv.FormatInt(ebsVolume.SizeGiB, 10))
+	resource.setDetail("state", ebsVolume.State)
+
+	resource.setDetail("volumeType", ebsVolume.VolumeType)
+
+	if ebsVolume.IsUnattached() {
+		resource.setDetail("recommendedAction", ebsVolume.GetRecommendedAction())
+
+		if days, ok := ebsVolume.GetDaysDetached(now); ok {
+			resource.setDetail("daysDetached", strconv.Itoa(days))
+		}
+	}
+
+	return resource
+}
+
+func getReportableResource(kind string, reportableResource monitoring.ReportableResource, now time.Time) Resource {
+	resource := newResource(kind, reportableResource.Resource(), monitoring.GetReportableTotalCost(reportableResource), now)
+
+	for _, field := range reportableResource.ReportFields() {
+		resource.setDetail(field.Label, field.Value)
+	}
+
+	if orphanCandidate, ok := reportableResource.(monitoring.OrphanCandidate); ok {
+		resource.setDetail("orphaned", orphanCandidate.OrphanReason())
+	}
+
+	if parent, ok := reportableResource.(monitoring.ReportableParent); ok {
+		for _, claimedResource := range parent.ClaimedResources() {
+			resource.Children = append(resource.Children, getReportableResource(claimedResource.Kind(), claimedResource, now))
+		}
+
+		sortResources(resource.Children)
+	}
+
+	return resource
+}
+
+// setDetail leaves out empty values so they do not clutter the JSON
+func (resource *Resource) setDetail(key string, value string) {
+	if value != "" {
+		resource.Details[key] = value
+	}
+}
+
+func (resource Resource) String() string {
+	return fmt.Sprintf("%s:%s", resource.Type, resource.ID)
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"io"
+	"log"
+	"os"
+)
+
+// StdoutPath writes an export to standard output instead of a file
+const StdoutPath = "-"
+
+type InventoryExporter struct {
+	GlobalCloudContext *monitoring.GlobalCloudContext
+}
+
+func (exporter *InventoryExporter) getInventory() (*Inventory, error) {
+	if exporter.GlobalCloudContext == nil {
+		return nil, fmt.Errorf("unable to export inventory, no cloud context found")
+	}
+
+	return NewInventory(exporter.GlobalCloudContext), nil
+}
+
+// ExportJSON writes the inventory with its claim hierarchy to path, or stdout
+func (exporter *InventoryExporter) ExportJSON(path string) error {
+	inventory, err := exporter.getInventory()
+	if err != nil {
+		return err
+	}
+
+	if path == StdoutPath {
+		return WriteJSON(os.Stdout, inventory)
+	}
+
+	if err := writeFile(path, func(writer io.Writer) error { return WriteJSON(writer, inventory) }); err != nil {
+		return fmt.Errorf("unable to write inventory JSON file %s: %s", path, err)
+	}
+
+	log.Printf("Wrote inventory JSON file %s", path)
+	return nil
+}
+
+// ExportCSV writes one CSV per resource type into the directory at path, or a single CSV to stdout
+func (exporter *InventoryExporter) ExportCSV(path string) error {
+	inventory, err := exporter.getInventory()
+	if err != nil {
+		return err
+	}
+
+	if path == StdoutPath {
+		return WriteCSV(os.Stdout, inventory)
+	}
+
+	paths, err := WriteCSVFiles(path, inventory)
+	if err != nil {
+		return fmt.Errorf("unable to write inventory CSV files to %s: %s", path, err)
+	}
+
+	log.Printf("Wrote %d inventory CSV files to %s", len(paths), path)
+	return nil
+}
+
+func writeFile(path string, write func(writer io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,12 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+)
+
+func WriteJSON(writer io.Writer, inventory *Inventory) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(inventory)
+}
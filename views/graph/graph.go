@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"io"
+	"log"
+	"os"
+)
+
+const graphDotPathEnv = "OWNERSHIP_GRAPH_DOT_PATH"
+const graphJsonPathEnv = "OWNERSHIP_GRAPH_JSON_PATH"
+const graphAccountEnv = "OWNERSHIP_GRAPH_ACCOUNT"
+
+var nodeShapes = map[monitoring.NodeType]string{
+	monitoring.NodeCouchbaseCloud:              "doubleoctagon",
+	monitoring.NodeCouchbaseCloudCluster:       "octagon",
+	monitoring.NodeCloudformationStack:         "folder",
+	monitoring.NodeEKSCluster:                  "component",
+	monitoring.NodeEC2Instance:                 "box",
+	monitoring.NodeEBSVolume:                   "cylinder",
+	monitoring.NodeAzureResourceGroup:          "folder",
+	monitoring.NodeAKSCluster:                  "component",
+	monitoring.NodeAzureVirtualMachine:         "box",
+	monitoring.NodeAzureScaleSet:               "box3d",
+	monitoring.NodeAzureManagedDisk:            "cylinder",
+	monitoring.NodeDeploymentManagerDeployment: "folder",
+	monitoring.NodeGKECluster:                  "component",
+	monitoring.NodeGCPInstance:                 "box",
+	monitoring.NodeGCPPersistentDisk:           "cylinder",
+	monitoring.NodeRDSCluster:                  "box3d",
+	monitoring.NodeRDSInstance:                 "cylinder",
+	monitoring.NodeElastiCacheCluster:          "cylinder",
+	monitoring.NodeOpenSearchDomain:            "cylinder",
+	monitoring.NodeVPC:                         "tab",
+	monitoring.NodeLoadBalancer:                "invtrapezium",
+	monitoring.NodeNATGateway:                  "cds",
+	monitoring.NodeElasticIP:                   "oval",
+}
+
+type OwnershipGraphExporter struct {
+	GlobalCloudContext *monitoring.GlobalCloudContext
+}
+
+type ownershipGraphDocument struct {
+	Account string                 `json:"account,omitempty"`
+	Nodes   []monitoring.GraphNode `json:"nodes"`
+	Edges   []monitoring.GraphEdge `json:"edges"`
+}
+
+// Export writes the ownership graph to the paths configured in the environment, nothing is written if none are set
+func (exporter *OwnershipGraphExporter) Export() error {
+	if exporter.GlobalCloudContext == nil || exporter.GlobalCloudContext.OwnershipGraph == nil {
+		return fmt.Errorf("unable to export ownership graph, no cloud context found")
+	}
+
+	account := os.Getenv(graphAccountEnv)
+	ownershipGraph := exporter.GlobalCloudContext.OwnershipGraph
+
+	if account != "" {
+		ownershipGraph = ownershipGraph.ForAccount(account)
+	}
+
+	if path := os.Getenv(graphDotPathEnv); path != "" {
+		if err := writeFile(path, func(writer io.Writer) error { return WriteDOT(writer, ownershipGraph) }); err != nil {
+			return fmt.Errorf("unable to write ownership graph DOT file %s: %s", path, err)
+		}
+
+		log.Printf("Wrote ownership graph DOT file %s", path)
+	}
+
+	if path := os.Getenv(graphJsonPathEnv); path != "" {
+		if err := writeFile(path, func(writer io.Writer) error { return WriteJSON(writer, ownershipGraph, account) }); err != nil {
+			return fmt.Errorf("unable to write ownership graph JSON file %s: %s", path, err)
+		}
+
+		log.Printf("Wrote ownership graph JSON file %s", path)
+	}
+
+	return nil
+}
+
+func WriteDOT(writer io.Writer, ownershipGraph *monitoring.OwnershipGraph) error {
+	bufferedWriter := bufio.NewWriter(writer)
+
+	fmt.Fprintln(bufferedWriter, "digraph ownership {")
+	fmt.Fprintln(bufferedWriter, "  rankdir=RL;")
+
+	for _, node := range ownershipGraph.SortedNodes() {
+		label := fmt.Sprintf("%s\\n%s", node.Type, getNodeDisplayName(node))
+
+		if node.Region != "" {
+			label = fmt.Sprintf("%s\\n%s/%s", label, node.Account, node.Region)
+		}
+
+		shape, ok := nodeShapes[node.Type]
+		if !ok {
+			shape = "ellipse"
+		}
+
+		fmt.Fprintf(bufferedWriter, "  %q [label=%q, shape=%s];\n", node.ID, label, shape)
+	}
+
+	for _, edge := range ownershipGraph.SortedEdges() {
+		fmt.Fprintf(bufferedWriter, "  %q -> %q [label=%q];\n", edge.From, edge.To, edge.Type)
+	}
+
+	fmt.Fprintln(bufferedWriter, "}")
+	return bufferedWriter.Flush()
+}
+
+func WriteJSON(writer io.Writer, ownershipGraph *monitoring.OwnershipGraph, account string) error {
+	document := ownershipGraphDocument{
+		Account: account,
+		Nodes:   ownershipGraph.SortedNodes(),
+		Edges:   ownershipGraph.SortedEdges(),
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+func getNodeDisplayName(node monitoring.GraphNode) string {
+	if node.Name != "" {
+		return node.Name
+	}
+
+	return node.ID
+}
+
+func writeFile(path string, write func(writer io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,227 @@
+package html
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/couchbaselabs/cloud-monitoring-tool/views/export"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed report.html.tmpl
+var reportTemplateText string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportTemplateText))
+
+type reportRow struct {
+	RowID       string
+	ParentRowID string
+	Depth       int
+	HasChildren bool
+	Type        string
+	ID          string
+	Name        string
+	Account     string
+	Region      string
+	LaunchedBy  string
+	AgeDays     int
+	HasAge      bool
+	MonthlyCost float64
+	Priced      bool
+	Details     string
+}
+
+// reportGroup is a top level resource along with everything it claimed, groups are what get filtered and sorted
+type reportGroup struct {
+	Rows     []reportRow
+	Accounts string
+	Regions  string
+}
+
+type reportCost struct {
+	Account     string
+	Region      string
+	MonthlyCost float64
+}
+
+type reportPage struct {
+	GeneratedAt  string
+	Groups       []reportGroup
+	Accounts     []string
+	Regions      []string
+	Types        []string
+	Costs        []reportCost
+	TotalMonthly float64
+	ScanErrors   []monitoring.ScanError
+}
+
+type HTMLReport struct {
+	GlobalCloudContext *monitoring.GlobalCloudContext
+}
+
+func (report *HTMLReport) Render(writer io.Writer) error {
+	if report.GlobalCloudContext == nil {
+		return fmt.Errorf("unable to render HTML report, no cloud context found")
+	}
+
+	return reportTemplate.Execute(writer, getReportPage(report.GlobalCloudContext))
+}
+
+func (report *HTMLReport) RenderBytes() ([]byte, error) {
+	var buffer bytes.Buffer
+
+	if err := report.Render(&buffer); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (report *HTMLReport) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to write HTML report %s: %s", path, err)
+	}
+
+	if err := report.Render(file); err != nil {
+		file.Close()
+		return fmt.Errorf("unable to write HTML report %s: %s", path, err)
+	}
+
+	log.Printf("Wrote HTML report %s", path)
+	return file.Close()
+}
+
+func getReportPage(ctx *monitoring.GlobalCloudContext) *reportPage {
+	inventory := export.NewInventory(ctx)
+	page := &reportPage{
+		GeneratedAt: inventory.GeneratedAt.Format("2 Jan 2006 15:04 MST"),
+		ScanErrors:  ctx.ScanErrors,
+	}
+
+	accounts := map[string]bool{}
+	regions := map[string]bool{}
+	types := map[string]bool{}
+
+	for i, resource := range inventory.Resources {
+		group := reportGroup{}
+		addReportRows(&group, resource, fmt.Sprintf("r%d", i), "", 0)
+
+		groupAccounts := map[string]bool{}
+		groupRegions := map[string]bool{}
+
+		for _, row := range group.Rows {
+			types[row.Type] = true
+
+			if row.Account != "" {
+				groupAccounts[row.Account] = true
+				accounts[row.Account] = true
+			}
+
+			if row.Region != "" {
+				groupRegions[row.Region] = true
+				regions[row.Region] = true
+			}
+		}
+
+		group.Accounts = strings.Join(getSortedKeys(groupAccounts), " ")
+		group.Regions = strings.Join(getSortedKeys(groupRegions), " ")
+		page.Groups = append(page.Groups, group)
+	}
+
+	page.Accounts = getSortedKeys(accounts)
+	page.Regions = getSortedKeys(regions)
+	page.Types = getSortedKeys(types)
+
+	// Resource costs include everything they claimed, so totals come from the regional contexts to avoid counting twice
+	for _, regionalCtx := range ctx.RegionalCloudContexts {
+		if !regionalCtx.EstimatedCost.Priced {
+			continue
+		}
+
+		page.Costs = append(page.Costs, reportCost{
+			Account:     regionalCtx.Account,
+			Region:      regionalCtx.Region,
+			MonthlyCost: regionalCtx.EstimatedCost.Monthly(),
+		})
+		page.TotalMonthly += regionalCtx.EstimatedCost.Monthly()
+	}
+
+	return page
+}
+
+func addReportRows(group *reportGroup, resource export.Resource, rowId string, parentRowId string, depth int) {
+	row := reportRow{
+		RowID:       rowId,
+		ParentRowID: parentRowId,
+		Depth:       depth,
+		HasChildren: len(resource.Children) > 0,
+		Type:        resource.Type,
+		ID:          resource.ID,
+		Name:        resource.Name,
+		Account:     resource.Account,
+		Region:      resource.Region,
+		LaunchedBy:  resource.LaunchedBy,
+		Details:     getDetailsText(resource.Details),
+	}
+
+	if row.Name == "" {
+		row.Name = resource.ID
+	}
+
+	if resource.AgeDays != nil {
+		row.AgeDays = *resource.AgeDays
+		row.HasAge = true
+	}
+
+	if resource.EstimatedMonthlyCost != nil {
+		row.MonthlyCost = *resource.EstimatedMonthlyCost
+		row.Priced = true
+	}
+
+	group.Rows = append(group.Rows, row)
+
+	for i, child := range resource.Children {
+		addReportRows(group, child, fmt.Sprintf("%s-%d", rowId, i), rowId, depth+1)
+	}
+}
+
+func getDetailsText(details map[string]string) string {
+	var pairs []string
+	for _, key := range getSortedKeys(toSet(details)) {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", key, details[key]))
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+func toSet(values map[string]string) map[string]bool {
+	set := map[string]bool{}
+	for key := range values {
+		set[key] = true
+	}
+
+	return set
+}
+
+func getSortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// GetReportFileName names uploaded reports after the day they were generated
+func GetReportFileName(now time.Time) string {
+	return fmt.Sprintf("cloud-report-%s.html", now.UTC().Format("2006-01-02"))
+}
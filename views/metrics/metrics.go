@@ -0,0 +1,257 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/couchbaselabs/cloud-monitoring-tool/views/export"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const metricsAddrEnv = "METRICS_ADDR"
+const metricsRefreshIntervalEnv = "METRICS_REFRESH_INTERVAL"
+
+const defaultMetricsAddr = ":2112"
+const defaultMetricsRefreshInterval = time.Hour
+const metricsPath = "/metrics"
+
+const (
+	claimStatusClaimed   = "claimed"
+	claimStatusUnclaimed = "unclaimed"
+)
+
+var resourceLabels = []string{"account", "region", "resource_type", "owner", "claim_status"}
+
+var (
+	resourcesDesc = prometheus.NewDesc("cloud_monitoring_resources", "Number of resources found by the last scan.", resourceLabels, nil)
+	ebsSizeDesc   = prometheus.NewDesc("cloud_monitoring_ebs_volume_size_gibibytes", "Total size of EBS volumes found by the last scan.", resourceLabels, nil)
+	costDesc      = prometheus.NewDesc("cloud_monitoring_estimated_cost_dollars_per_hour", "Estimated on-demand cost of resources, excluding the resources they claimed.", resourceLabels, nil)
+	oldestAgeDesc = prometheus.NewDesc("cloud_monitoring_oldest_resource_age_seconds", "Age of the oldest resource found by the last scan.", resourceLabels, nil)
+	scanErrorDesc = prometheus.NewDesc("cloud_monitoring_scan_errors", "Number of accounts or regions the last scan could not fully cover.", []string{"account"}, nil)
+	refreshDesc   = prometheus.NewDesc("cloud_monitoring_last_refresh_timestamp_seconds", "Unix time the last successful scan finished.", nil, nil)
+	durationDesc  = prometheus.NewDesc("cloud_monitoring_last_refresh_duration_seconds", "How long the last successful scan took.", nil, nil)
+	failuresDesc  = prometheus.NewDesc("cloud_monitoring_refresh_failures_total", "Number of scans that failed since the exporter started.", nil, nil)
+)
+
+type resourceKey struct {
+	Account     string
+	Region      string
+	Type        string
+	Owner       string
+	ClaimStatus string
+}
+
+func (key resourceKey) labels() []string {
+	return []string{key.Account, key.Region, key.Type, key.Owner, key.ClaimStatus}
+}
+
+type resourceStats struct {
+	Count         int
+	EBSSizeGiB    float64
+	HourlyCost    float64
+	Priced        bool
+	OldestCreated time.Time
+}
+
+// snapshot holds the values of the last successful scan, so scrapes never see a half updated set of metrics
+type snapshot struct {
+	Resources       map[resourceKey]*resourceStats
+	ScanErrors      map[string]int
+	RefreshedAt     time.Time
+	RefreshDuration time.Duration
+}
+
+// Exporter serves metrics about the last scan and periodically replaces them with a new one
+type Exporter struct {
+	analyse         func() (*monitoring.GlobalCloudContext, error)
+	refreshInterval time.Duration
+	snapshot        *snapshot
+	failures        int
+	mutex           sync.Mutex
+}
+
+func NewExporter(analyse func() (*monitoring.GlobalCloudContext, error)) *Exporter {
+	return &Exporter{
+		analyse:         analyse,
+		refreshInterval: getRefreshInterval(),
+	}
+}
+
+func getRefreshInterval() time.Duration {
+	value := os.Getenv(metricsRefreshIntervalEnv)
+	if value == "" {
+		return defaultMetricsRefreshInterval
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < time.Minute {
+		log.Printf("Invalid %s %q, using %s", metricsRefreshIntervalEnv, value, defaultMetricsRefreshInterval)
+		return defaultMetricsRefreshInterval
+	}
+
+	return interval
+}
+
+func (exporter *Exporter) Describe(descs chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{resourcesDesc, ebsSizeDesc, costDesc, oldestAgeDesc, scanErrorDesc, refreshDesc, durationDesc, failuresDesc} {
+		descs <- desc
+	}
+}
+
+func (exporter *Exporter) Collect(metrics chan<- prometheus.Metric) {
+	exporter.mutex.Lock()
+	current := exporter.snapshot
+	failures := exporter.failures
+	exporter.mutex.Unlock()
+
+	metrics <- prometheus.MustNewConstMetric(failuresDesc, prometheus.CounterValue, float64(failures))
+
+	if current == nil {
+		return
+	}
+
+	now := time.Now()
+
+	for key, stats := range current.Resources {
+		labels := key.labels()
+		metrics <- prometheus.MustNewConstMetric(resourcesDesc, prometheus.GaugeValue, float64(stats.Count), labels...)
+
+		if key.Type == string(monitoring.NodeEBSVolume) {
+			metrics <- prometheus.MustNewConstMetric(ebsSizeDesc, prometheus.GaugeValue, stats.EBSSizeGiB, labels...)
+		}
+
+		if stats.Priced {
+			metrics <- prometheus.MustNewConstMetric(costDesc, prometheus.GaugeValue, stats.HourlyCost, labels...)
+		}
+
+		if !stats.OldestCreated.IsZero() {
+			metrics <- prometheus.MustNewConstMetric(oldestAgeDesc, prometheus.GaugeValue, now.Sub(stats.OldestCreated).Seconds(), labels...)
+		}
+	}
+
+	for account, count := range current.ScanErrors {
+		metrics <- prometheus.MustNewConstMetric(scanErrorDesc, prometheus.GaugeValue, float64(count), account)
+	}
+
+	metrics <- prometheus.MustNewConstMetric(refreshDesc, prometheus.GaugeValue, float64(current.RefreshedAt.Unix()))
+	metrics <- prometheus.MustNewConstMetric(durationDesc, prometheus.GaugeValue, current.RefreshDuration.Seconds())
+}
+
+func newSnapshot(ctx *monitoring.GlobalCloudContext) *snapshot {
+	current := &snapshot{
+		Resources:  map[resourceKey]*resourceStats{},
+		ScanErrors: map[string]int{},
+	}
+
+	for resourceType, flatResources := range export.Flatten(export.NewInventory(ctx).Resources) {
+		for _, flatResource := range flatResources {
+			resource := flatResource.Resource
+
+			key := resourceKey{
+				Account:     resource.Account,
+				Region:      resource.Region,
+				Type:        resourceType,
+				Owner:       monitoring.GetPrincipalName(resource.LaunchedBy),
+				ClaimStatus: claimStatusUnclaimed,
+			}
+
+			if key.Owner == "" {
+				key.Owner = "unknown"
+			}
+
+			if flatResource.Parent != nil {
+				key.ClaimStatus = claimStatusClaimed
+			}
+
+			stats, ok := current.Resources[key]
+			if !ok {
+				stats = &resourceStats{}
+				current.Resources[key] = stats
+			}
+
+			stats.Count++
+
+			if sizeGiB, err := strconv.ParseFloat(resource.Details["sizeGiB"], 64); err == nil {
+				stats.EBSSizeGiB += sizeGiB
+			}
+
+			if resource.OwnCost.Priced {
+				stats.HourlyCost += resource.OwnCost.Hourly
+				stats.Priced = true
+			}
+
+			if resource.CreatedAt != nil && (stats.OldestCreated.IsZero() || resource.CreatedAt.Before(stats.OldestCreated)) {
+				stats.OldestCreated = *resource.CreatedAt
+			}
+		}
+	}
+
+	for _, scanError := range ctx.ScanErrors {
+		current.ScanErrors[scanError.Account]++
+	}
+
+	return current
+}
+
+func (exporter *Exporter) refresh() {
+	start := time.Now()
+	ctx, err := exporter.analyse()
+
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	if err != nil {
+		exporter.failures++
+		log.Printf("Unable to refresh metrics, keeping the previous scan: %s", err)
+		return
+	}
+
+	current := newSnapshot(ctx)
+	current.RefreshedAt = time.Now()
+	current.RefreshDuration = current.RefreshedAt.Sub(start)
+	exporter.snapshot = current
+
+	log.Printf("Refreshed metrics for %d resource groups in %s", len(current.Resources), current.RefreshDuration)
+}
+
+// Run serves /metrics and rescans on the refresh interval until the server fails
+func (exporter *Exporter) Run() error {
+	addr := os.Getenv(metricsAddrEnv)
+	if addr == "" {
+		addr = defaultMetricsAddr
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(exporter); err != nil {
+		return fmt.Errorf("unable to register metrics: %s", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		log.Printf("Serving metrics on %s%s, refreshing every %s", addr, metricsPath, exporter.refreshInterval)
+		serverErrors <- http.ListenAndServe(addr, mux)
+	}()
+
+	exporter.refresh()
+
+	ticker := time.NewTicker(exporter.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-serverErrors:
+			return err
+		case <-ticker.C:
+			exporter.refresh()
+		}
+	}
+}
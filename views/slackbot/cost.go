@@ -0,0 +1,87 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"sort"
+)
+
+func sortByCost(couchbaseClouds []monitoring.CouchbaseCloud, couchbaseCloudClusters []monitoring.CouchbaseCloudCluster, cloudformationStacks []monitoring.CloudformationStack, eksClusters []monitoring.EKSCluster, ec2Instances []monitoring.EC2Instance, ebsVolumes []monitoring.EBSVolume) {
+	sort.SliceStable(couchbaseClouds, func(i, j int) bool {
+		return couchbaseClouds[i].TotalCost().Hourly > couchbaseClouds[j].TotalCost().Hourly
+	})
+	sort.SliceStable(couchbaseCloudClusters, func(i, j int) bool {
+		return couchbaseCloudClusters[i].TotalCost().Hourly > couchbaseCloudClusters[j].TotalCost().Hourly
+	})
+	sort.SliceStable(cloudformationStacks, func(i, j int) bool {
+		return cloudformationStacks[i].TotalCost().Hourly > cloudformationStacks[j].TotalCost().Hourly
+	})
+	sort.SliceStable(eksClusters, func(i, j int) bool {
+		return eksClusters[i].TotalCost().Hourly > eksClusters[j].TotalCost().Hourly
+	})
+	sort.SliceStable(ec2Instances, func(i, j int) bool {
+		return ec2Instances[i].TotalCost().Hourly > ec2Instances[j].TotalCost().Hourly
+	})
+	sort.SliceStable(ebsVolumes, func(i, j int) bool {
+		return ebsVolumes[i].EstimatedCost.Hourly > ebsVolumes[j].EstimatedCost.Hourly
+	})
+}
+
+func sortResourcesByCost(resources []monitoring.ReportableResource) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		return monitoring.GetReportableTotalCost(resources[i]).Hourly > monitoring.GetReportableTotalCost(resources[j]).Hourly
+	})
+}
+
+func getCostText(cost monitoring.Cost) string {
+	return fmt.Sprintf("*Estimated Cost*: `%s`\n", cost)
+}
+
+func getEstimatedCostText(ctx *monitoring.GlobalCloudContext) string {
+	costsByAccount := ctx.GetEstimatedCostByAccount()
+
+	var accounts []string
+	for account := range costsByAccount {
+		accounts = append(accounts, account)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return costsByAccount[accounts[i]].Hourly > costsByAccount[accounts[j]].Hourly
+	})
+
+	var message bytes.Buffer
+	message.WriteString(":moneybag:  *Estimated cost*\n")
+
+	for _, account := range accounts {
+		message.WriteString(fmt.Sprintf("*%s*: `%s`", account, costsByAccount[account]))
+
+		var regionalCtxs []monitoring.RegionalCloudContext
+		for _, regionalCtx := range ctx.RegionalCloudContexts {
+			if regionalCtx.Account == account && regionalCtx.EstimatedCost.Hourly > 0 {
+				regionalCtxs = append(regionalCtxs, regionalCtx)
+			}
+		}
+
+		sort.Slice(regionalCtxs, func(i, j int) bool {
+			return regionalCtxs[i].EstimatedCost.Hourly > regionalCtxs[j].EstimatedCost.Hourly
+		})
+
+		for idx, regionalCtx := range regionalCtxs {
+			separator := ", "
+			if idx == 0 {
+				separator = " ("
+			}
+
+			message.WriteString(fmt.Sprintf("%s%s $%.2f/month", separator, regionalCtx.Region, regionalCtx.EstimatedCost.Monthly()))
+		}
+
+		if len(regionalCtxs) > 0 {
+			message.WriteString(")")
+		}
+
+		message.WriteString("\n")
+	}
+
+	return message.String()
+}
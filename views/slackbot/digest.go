@@ -0,0 +1,225 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/couchbaselabs/cloud-monitoring-tool/views/export"
+	"github.com/slack-go/slack"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const slackOwnerDigestsEnv = "SLACK_OWNER_DIGESTS"
+const slackUnownedChannelIdEnv = "SLACK_UNOWNED_CHANNEL_ID"
+
+// Keeps digests well within the Slack message size limit
+const maxDigestItems = 50
+
+type digestItem struct {
+	ResourceType string
+	Resource     monitoring.CloudResource
+	Cost         monitoring.Cost
+}
+
+func OwnerDigestsEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(slackOwnerDigestsEnv))
+	return enabled
+}
+
+// Digests name the resource types of the report hierarchy the way the Slack report does
+var digestResourceTypes = map[string]string{
+	string(monitoring.NodeCouchbaseCloud):        "Couchbase Cloud",
+	string(monitoring.NodeCouchbaseCloudCluster): "Couchbase Cloud cluster",
+	string(monitoring.NodeCloudformationStack):   "Cloudformation stack",
+	string(monitoring.NodeEKSCluster):            "EKS cluster",
+	string(monitoring.NodeEC2Instance):           "EC2 instance",
+	string(monitoring.NodeEBSVolume):             "EBS volume",
+}
+
+// getDigestItems lists every resource once, including those claimed by a stack, an EKS cluster or a Couchbase Cloud.
+// Each one only carries its own cost, so the total of a digest does not count claimed resources twice.
+func getDigestItems(ctx *monitoring.GlobalCloudContext) []digestItem {
+	var items []digestItem
+
+	for _, flatResources := range export.Flatten(export.NewInventory(ctx).Resources) {
+		for _, flatResource := range flatResources {
+			resource := flatResource.Resource
+			items = append(items, digestItem{getDigestResourceType(resource), getDigestCloudResource(resource), resource.OwnCost})
+		}
+	}
+
+	return items
+}
+
+func getDigestResourceType(resource export.Resource) string {
+	resourceType, ok := digestResourceTypes[resource.Type]
+	if !ok {
+		return resource.Type
+	}
+
+	switch resource.Type {
+	case string(monitoring.NodeEC2Instance):
+		var ec2Instance monitoring.EC2Instance
+		ec2Instance.State = resource.Details["state"]
+
+		if ec2Instance.IsStopped() {
+			return "stopped " + resourceType
+		}
+	case string(monitoring.NodeEBSVolume):
+		var ebsVolume monitoring.EBSVolume
+		ebsVolume.State = resource.Details["state"]
+
+		if ebsVolume.IsUnattached() {
+			return "unattached " + resourceType
+		}
+	}
+
+	return resourceType
+}
+
+// getDigestCloudResource keeps the fields owners are resolved from and the digest text is built from
+func getDigestCloudResource(resource export.Resource) monitoring.CloudResource {
+	cloudResource := monitoring.CloudResource{
+		ID:            resource.ID,
+		Name:          resource.Name,
+		Provider:      resource.Provider,
+		Account:       resource.Account,
+		Region:        resource.Region,
+		LaunchedBy:    resource.LaunchedBy,
+		Tags:          resource.Tags,
+		EstimatedCost: resource.OwnCost,
+	}
+
+	if resource.CreatedAt != nil {
+		cloudResource.CreatedAt = *resource.CreatedAt
+	}
+
+	return cloudResource
+}
+
+// PostOwnerDigests sends every owner a direct message listing only their resources. Resources without a known owner
+// are posted to the unowned channel when one is configured.
+func (bot *CloudMonitoringSlackBot) PostOwnerDigests() error {
+	slackToken := os.Getenv(slackBotTokenEnv)
+
+	if slackToken == "" {
+		return fmt.Errorf("unable to start Slack bot, %s environment variable not found", slackBotTokenEnv)
+	}
+
+	if bot.GlobalCloudContext == nil {
+		return fmt.Errorf("unable to post messages to Slack, no cloud context found")
+	}
+
+	client := slack.New(slackToken)
+
+	owners, err := NewOwnerResolver(os.Getenv(slackOwnerMapPathEnv), client)
+	if err != nil {
+		return err
+	}
+
+	itemsByOwner := map[string][]digestItem{}
+	var unownedItems []digestItem
+
+	for _, item := range getDigestItems(bot.GlobalCloudContext) {
+		if userId := owners.Resolve(item.Resource); userId != "" {
+			itemsByOwner[userId] = append(itemsByOwner[userId], item)
+		} else {
+			unownedItems = append(unownedItems, item)
+		}
+	}
+
+	log.Printf("Sending throttled Slack digests to %d owners", len(itemsByOwner))
+
+	for userId, items := range itemsByOwner {
+		channel, _, _, err := client.OpenConversation(&slack.OpenConversationParameters{Users: []string{userId}})
+		if err != nil {
+			log.Printf("Unable to open direct message with %s: %s", userId, err)
+			continue
+		}
+
+		intro := fmt.Sprintf("Hi %s, here are the cloud resources you own.", getSlackMention(userId))
+
+		if err := sendSlackText(client, channel.ID, getDigestText(intro, items)); err != nil {
+			log.Printf("Unable to send Slack digest to %s: %s", userId, err)
+		}
+	}
+
+	unownedChannel := os.Getenv(slackUnownedChannelIdEnv)
+
+	if unownedChannel == "" || len(unownedItems) == 0 {
+		return nil
+	}
+
+	log.Printf("Sending %d resources without a known owner to %s", len(unownedItems), unownedChannel)
+
+	if err := sendSlackText(client, unownedChannel, getDigestText("These cloud resources have no known owner.", unownedItems)); err != nil {
+		return handleSlackMessageError(err)
+	}
+
+	return nil
+}
+
+func getDigestText(intro string, items []digestItem) string {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Cost.Hourly > items[j].Cost.Hourly
+	})
+
+	total := monitoring.Cost{}
+	var oldest time.Time
+
+	for _, item := range items {
+		total = total.Add(item.Cost)
+
+		if !item.Resource.CreatedAt.IsZero() && (oldest.IsZero() || item.Resource.CreatedAt.Before(oldest)) {
+			oldest = item.Resource.CreatedAt
+		}
+	}
+
+	var message bytes.Buffer
+	message.WriteString(fmt.Sprintf("%s\n\n", intro))
+	message.WriteString(fmt.Sprintf("*Resources*: `%d`\n", len(items)))
+	message.WriteString(getCostText(total))
+
+	if !oldest.IsZero() {
+		message.WriteString(fmt.Sprintf("*Oldest*: `%s`\n", getAgeAsString(time.Since(oldest))))
+	}
+
+	message.WriteString("\n")
+
+	for idx, item := range items {
+		if idx == maxDigestItems {
+			message.WriteString(fmt.Sprintf("…and %d more\n", len(items)-maxDigestItems))
+			break
+		}
+
+		name := item.Resource.Name
+		if name == "" {
+			name = item.Resource.ID
+		}
+
+		message.WriteString(fmt.Sprintf("• %s `%s` in `%s/%s`, %s", item.ResourceType, name, item.Resource.Account, item.Resource.Region, item.Cost))
+
+		if !item.Resource.CreatedAt.IsZero() {
+			message.WriteString(fmt.Sprintf(", created %s", item.Resource.CreatedAt.UTC().Format(dateLayout)))
+		}
+
+		message.WriteString("\n")
+	}
+
+	return message.String()
+}
+
+func sendSlackText(client *slack.Client, channelId string, text string) error {
+	_, _, _, err := client.SendMessage(channelId, slack.MsgOptionText(text, false))
+
+	if err != nil {
+		return err
+	}
+
+	time.Sleep(throttleDuration)
+	return nil
+}
@@ -0,0 +1,70 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/slack-go/slack"
+	"log"
+)
+
+func getExpiredParentBlocks(expiredResources []monitoring.ExpiredResource, expiryCounts map[string]int) []slack.Block {
+	var blocks []slack.Block
+	blocks = append(blocks, getSlackDividerBlock())
+	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf(":hourglass:  *Expired Resources* (%d, %d expiring soon, %d without an `%s` or `%s` tag)",
+		len(expiredResources), expiryCounts[monitoring.ExpiryExpiringSoon], expiryCounts[monitoring.ExpiryMissingTTL], monitoring.ExpiresOnTag, monitoring.TTLTag)))
+	return blocks
+}
+
+func getExpiryText(expiry monitoring.Expiry) string {
+	switch expiry.Status {
+	case monitoring.ExpiryExpired:
+		return fmt.Sprintf(":hourglass: *Expired*: `%s` (%s)\n", expiry.ExpiresAt.UTC().Format(dateLayout), expiry.Source)
+	case monitoring.ExpiryExpiringSoon:
+		return fmt.Sprintf(":hourglass_flowing_sand: *Expires*: `%s` (%s)\n", expiry.ExpiresAt.UTC().Format(dateLayout), expiry.Source)
+	case monitoring.ExpiryValid:
+		return fmt.Sprintf("*Expires*: `%s` (%s)\n", expiry.ExpiresAt.UTC().Format(dateLayout), expiry.Source)
+	}
+
+	return ""
+}
+
+func sendExpiredReplies(client *slack.Client, owners *OwnerResolver, channelId string, expiredResources []monitoring.ExpiredResource, reapResults []monitoring.ReapResult, timestamp string) {
+	log.Println("Sending throttled slack replies for expired resources")
+
+	reapResultsByReference := map[monitoring.ResourceReference]monitoring.ReapResult{}
+	for _, reapResult := range reapResults {
+		reapResultsByReference[reapResult.Reference] = reapResult
+	}
+
+	for _, expiredResource := range expiredResources {
+		resource := expiredResource.Resource
+		var message bytes.Buffer
+
+		if resource.Name != "" {
+			message.WriteString(fmt.Sprintf("*Name*: `%s`\n", resource.Name))
+		} else {
+			message.WriteString(fmt.Sprintf("*ID*: `%s`\n", resource.ID))
+		}
+
+		message.WriteString(fmt.Sprintf("*Type*: `%s`\n", expiredResource.Reference.Type))
+		message.WriteString(fmt.Sprintf("*Region*: `%s`\n", resource.Region))
+		message.WriteString(getExpiryText(resource.Expiry))
+
+		if reapResult, ok := reapResultsByReference[expiredResource.Reference]; ok {
+			if reapResult.Error != "" {
+				message.WriteString(fmt.Sprintf(":x: *Reaper*: `%s`\n", reapResult.Error))
+			} else {
+				message.WriteString(fmt.Sprintf("*Reaper*: `%s`\n", reapResult.Outcome))
+			}
+		}
+
+		message.WriteString(getCostText(resource.EstimatedCost))
+		message.WriteString(getLaunchedByText(owners, resource))
+		message.WriteString(fmt.Sprintf("*Account*: `%s`\n", resource.Account))
+
+		if err := sendSlackActionReply(client, owners, channelId, timestamp, message.String(), expiredResource.Reference, resource); err != nil {
+			log.Printf("Unable to send Slack reply: %s", err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/slack-go/slack"
+	"log"
+)
+
+// Slack messages are limited in length, so long lists are cut short
+const maxHistoryItems = 30
+
+func getHistoryParentBlocks(diff *monitoring.HistoryDiff) []slack.Block {
+	var blocks []slack.Block
+	blocks = append(blocks, getSlackDividerBlock())
+	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf(":repeat:  *Since last run* on %s\n`%d` new, `%d` removed, :rotating_light: `%d` reported in %d or more consecutive runs",
+		diff.PreviousRunAt.UTC().Format(dateLayout), len(diff.New), len(diff.Removed), len(diff.PersistentOffenders), diff.PersistentRuns)))
+	return blocks
+}
+
+func getHistoryResourceText(resource monitoring.GraphNode) string {
+	name := resource.Name
+	if name == "" {
+		name = resource.ID
+	}
+
+	return fmt.Sprintf("`%s` %s (%s %s)", name, resource.Type, resource.Account, resource.Region)
+}
+
+func getHistoryListText(title string, resources []monitoring.GraphNode) string {
+	var message bytes.Buffer
+	message.WriteString(fmt.Sprintf("*%s* (%d)\n", title, len(resources)))
+
+	for i, resource := range resources {
+		if i == maxHistoryItems {
+			message.WriteString(fmt.Sprintf("…and %d more\n", len(resources)-maxHistoryItems))
+			break
+		}
+
+		message.WriteString(fmt.Sprintf("• %s\n", getHistoryResourceText(resource)))
+	}
+
+	return message.String()
+}
+
+func sendHistoryReplies(client *slack.Client, channelId string, diff *monitoring.HistoryDiff, timestamp string) {
+	log.Println("Sending throttled slack replies for changes since the last run")
+
+	var replies []string
+
+	if len(diff.PersistentOffenders) > 0 {
+		var message bytes.Buffer
+		message.WriteString(fmt.Sprintf(":rotating_light: *Persistent offenders* (%d)\n", len(diff.PersistentOffenders)))
+
+		for i, offender := range diff.PersistentOffenders {
+			if i == maxHistoryItems {
+				message.WriteString(fmt.Sprintf("…and %d more\n", len(diff.PersistentOffenders)-maxHistoryItems))
+				break
+			}
+
+			message.WriteString(fmt.Sprintf("• %s, *%d runs in a row*\n", getHistoryResourceText(offender.Resource), offender.Runs))
+		}
+
+		replies = append(replies, message.String())
+	}
+
+	if len(diff.New) > 0 {
+		replies = append(replies, getHistoryListText(":new: New", diff.New))
+	}
+
+	if len(diff.Removed) > 0 {
+		replies = append(replies, getHistoryListText(":white_check_mark: Removed", diff.Removed))
+	}
+
+	for _, reply := range replies {
+		if err := sendSlackReply(client, channelId, timestamp, reply); err != nil {
+			log.Printf("Unable to send Slack reply: %s", err)
+		}
+	}
+}
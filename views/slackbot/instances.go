@@ -0,0 +1,64 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/slack-go/slack"
+	"log"
+	"time"
+)
+
+func getStoppedEC2ParentBlocks(ec2Instances []monitoring.EC2Instance) []slack.Block {
+	stillBilled := monitoring.Cost{}
+	for _, ec2Instance := range ec2Instances {
+		stillBilled = stillBilled.Add(ec2Instance.TotalCost())
+	}
+
+	var blocks []slack.Block
+	blocks = append(blocks, getSlackDividerBlock())
+	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf(":black_square_for_stop:  *Stopped EC2 Instances* (%d, EBS still billed at `$%.2f/month`)", len(ec2Instances), stillBilled.Monthly())))
+	return blocks
+}
+
+func getStoppedText(ec2Instance monitoring.EC2Instance, now time.Time) string {
+	if ec2Instance.StoppedAt.IsZero() {
+		return "*Stopped*: `unknown`\n"
+	}
+
+	return fmt.Sprintf("*Stopped*: `%s` (%d days ago)\n", ec2Instance.StoppedAt.UTC().Format(dateLayout), int(now.Sub(ec2Instance.StoppedAt).Hours()/24))
+}
+
+func sendStoppedEC2InstanceReplies(client *slack.Client, owners *OwnerResolver, channelId string, ec2Instances []monitoring.EC2Instance, timestamp string) {
+	log.Println("Sending throttled slack replies for stopped EC2 instances")
+	now := time.Now()
+
+	for _, ec2Instance := range ec2Instances {
+		var message bytes.Buffer
+
+		if ec2Instance.Name != "" {
+			message.WriteString(fmt.Sprintf("*Name*: `%s`\n", ec2Instance.Name))
+		} else {
+			message.WriteString(fmt.Sprintf("*ID*: `%s`\n", ec2Instance.ID))
+		}
+
+		message.WriteString(fmt.Sprintf("*Region*: `%s`\n", ec2Instance.Region))
+		message.WriteString(fmt.Sprintf("*Type*: `%s`\n", ec2Instance.InstanceType))
+		message.WriteString(getStoppedText(ec2Instance, now))
+
+		if ec2Instance.StateTransitionReason != "" {
+			message.WriteString(fmt.Sprintf("*Reason*: `%s`\n", ec2Instance.StateTransitionReason))
+		}
+
+		message.WriteString(fmt.Sprintf("*EBS Volumes*: `%d`\n", len(ec2Instance.EBSVolumes)))
+		message.WriteString(fmt.Sprintf("*Still Billed*: `%s`\n", ec2Instance.TotalCost()))
+		message.WriteString(getExpiryText(ec2Instance.Expiry))
+		message.WriteString(getLaunchedByText(owners, ec2Instance.CloudResource))
+		message.WriteString(fmt.Sprintf("*Account*: `%s`\n", ec2Instance.Account))
+
+		reference := monitoring.ResourceReference{Account: ec2Instance.Account, Region: ec2Instance.Region, Type: monitoring.NodeEC2Instance, ID: ec2Instance.ID}
+		if err := sendSlackActionReply(client, owners, channelId, timestamp, message.String(), reference, ec2Instance.CloudResource); err != nil {
+			log.Printf("Unable to send Slack reply: %s", err)
+		}
+	}
+}
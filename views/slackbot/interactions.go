@@ -0,0 +1,287 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/slack-go/slack"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const slackSigningSecretEnv = "SLACK_SIGNING_SECRET"
+const slackInteractionsAddrEnv = "SLACK_INTERACTIONS_ADDR"
+const slackActionAdminsEnv = "SLACK_ACTION_ADMINS"
+
+const defaultInteractionsAddr = ":8080"
+const slackInteractionsPath = "/slack/interactions"
+const maxInteractionBodyBytes = 1 << 20
+
+const resourceActionsBlockId = "resource-actions"
+
+// resourceActionValue is carried by the buttons, along with the Slack user ID of the owner resolved when they were posted.
+// Slack signs the interaction payload, so the owner cannot be changed by whoever clicks.
+type resourceActionValue struct {
+	monitoring.ResourceReference
+	Owner string `json:"o,omitempty"`
+}
+
+// ResourceActioner carries out a button click against the cloud provider and describes the outcome
+type ResourceActioner interface {
+	Perform(resource monitoring.ResourceReference, action string, actor string) (string, error)
+}
+
+type InteractionServer struct {
+	client        *slack.Client
+	signingSecret string
+	actioner      ResourceActioner
+	admins        map[string]bool
+}
+
+func NewInteractionServer(actioner ResourceActioner) (*InteractionServer, error) {
+	slackToken := os.Getenv(slackBotTokenEnv)
+
+	if slackToken == "" {
+		return nil, fmt.Errorf("unable to start Slack interaction server, %s environment variable not found", slackBotTokenEnv)
+	}
+
+	signingSecret := os.Getenv(slackSigningSecretEnv)
+
+	if signingSecret == "" {
+		return nil, fmt.Errorf("unable to start Slack interaction server, %s environment variable not found", slackSigningSecretEnv)
+	}
+
+	admins := map[string]bool{}
+	for _, userId := range strings.Split(os.Getenv(slackActionAdminsEnv), ",") {
+		if userId = strings.TrimSpace(userId); userId != "" {
+			admins[userId] = true
+		}
+	}
+
+	return &InteractionServer{
+		client:        slack.New(slackToken),
+		signingSecret: signingSecret,
+		actioner:      actioner,
+		admins:        admins,
+	}, nil
+}
+
+func (server *InteractionServer) ListenAndServe() error {
+	addr := os.Getenv(slackInteractionsAddrEnv)
+	if addr == "" {
+		addr = defaultInteractionsAddr
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(slackInteractionsPath, server)
+
+	log.Printf("Listening for Slack interactions on %s%s", addr, slackInteractionsPath)
+	return http.ListenAndServe(addr, mux)
+}
+
+func (server *InteractionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxInteractionBodyBytes))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	verifier, err := slack.NewSecretsVerifier(r.Header, server.signingSecret)
+	if err != nil {
+		log.Printf("Rejected Slack interaction: %s", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if _, err := verifier.Write(body); err != nil || verifier.Ensure() != nil {
+		log.Printf("Rejected Slack interaction with an invalid signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(form.Get("payload")), &callback); err != nil {
+		log.Printf("Unable to parse Slack interaction: %s", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Slack expects an acknowledgement within 3 seconds, AWS calls can take longer
+	w.WriteHeader(http.StatusOK)
+
+	if callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+
+	for _, action := range callback.ActionCallback.BlockActions {
+		if action.BlockID == resourceActionsBlockId {
+			go server.handleResourceAction(callback, *action)
+		}
+	}
+}
+
+func (server *InteractionServer) handleResourceAction(callback slack.InteractionCallback, action slack.BlockAction) {
+	var value resourceActionValue
+	if err := json.Unmarshal([]byte(action.Value), &value); err != nil {
+		log.Printf("Unable to parse resource from Slack action %s: %s", action.ActionID, err)
+		return
+	}
+	resource := value.ResourceReference
+
+	actor := callback.User.Name
+	if actor == "" {
+		actor = callback.User.ID
+	}
+
+	log.Printf("Slack user %s (%s) clicked %s for %s %s", actor, callback.User.ID, action.ActionID, resource.Type, resource.ID)
+
+	channelId := callback.Container.ChannelID
+	if channelId == "" {
+		channelId = callback.Channel.ID
+	}
+
+	if !server.isAuthorized(callback.User.ID, value.Owner) {
+		log.Printf("Slack user %s (%s) is not allowed to %s %s %s", actor, callback.User.ID, action.ActionID, resource.Type, resource.ID)
+
+		text := fmt.Sprintf(":no_entry: Only the owner of this resource or an admin can %s it", getActionLabel(action.ActionID))
+		if _, err := server.client.PostEphemeral(channelId, callback.User.ID, slack.MsgOptionText(text, false)); err != nil {
+			log.Printf("Unable to tell Slack user %s their action was rejected: %s", callback.User.ID, err)
+		}
+		return
+	}
+
+	// The not mine tag holds the Slack user ID, which is what owners are resolved to when the next report is sent
+	performedBy := actor
+	if action.ActionID == monitoring.ResourceActionNotMine {
+		performedBy = callback.User.ID
+	}
+
+	outcome, err := server.actioner.Perform(resource, action.ActionID, performedBy)
+
+	var text string
+	if err != nil {
+		log.Printf("Unable to %s %s %s: %s", action.ActionID, resource.Type, resource.ID, err)
+		text = fmt.Sprintf(":x: %s tried to %s this resource but it failed: `%s`", getSlackMention(callback.User.ID), getActionLabel(action.ActionID), err)
+	} else {
+		text = fmt.Sprintf(":white_check_mark: %s by %s on %s", outcome, getSlackMention(callback.User.ID), time.Now().UTC().Format(dateLayout))
+	}
+
+	// Buttons are kept after a failure or a "not mine" so someone else can still act on the resource
+	keepActions := err != nil || action.ActionID == monitoring.ResourceActionNotMine
+
+	var blocks []slack.Block
+	for _, block := range callback.Message.Blocks.BlockSet {
+		if block.BlockType() == slack.MBTAction && !keepActions {
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", text, false, false)))
+
+	timestamp := callback.Container.MessageTs
+	if timestamp == "" {
+		timestamp = callback.Message.Timestamp
+	}
+
+	if _, _, _, err := server.client.UpdateMessage(channelId, timestamp, slack.MsgOptionBlocks(blocks...), slack.MsgOptionText(callback.Message.Text, false)); err != nil {
+		log.Printf("Unable to update Slack message %s: %s", timestamp, err)
+	}
+}
+
+// isAuthorized lets the owner of a resource and the admins act on it. Resources without a known owner can only be acted
+// on by admins.
+func (server *InteractionServer) isAuthorized(userId string, owner string) bool {
+	if server.admins[userId] {
+		return true
+	}
+
+	return owner != "" && owner == userId
+}
+
+func getActionLabel(action string) string {
+	switch action {
+	case monitoring.ResourceActionKeep:
+		return "keep"
+	case monitoring.ResourceActionStop:
+		return "stop"
+	case monitoring.ResourceActionDelete:
+		return "delete"
+	case monitoring.ResourceActionNotMine:
+		return "disown"
+	}
+
+	return action
+}
+
+func getResourceActionBlock(resource monitoring.ResourceReference, owner string) *slack.ActionBlock {
+	value, _ := json.Marshal(resourceActionValue{ResourceReference: resource, Owner: owner})
+
+	var elements []slack.BlockElement
+	elements = append(elements, slack.NewButtonBlockElement(monitoring.ResourceActionKeep, string(value), slack.NewTextBlockObject("plain_text", "Keep 7 days", false, false)))
+
+	if resource.Type == monitoring.NodeEC2Instance {
+		elements = append(elements, slack.NewButtonBlockElement(monitoring.ResourceActionStop, string(value), slack.NewTextBlockObject("plain_text", "Stop", false, false)))
+	}
+
+	deleteButton := slack.NewButtonBlockElement(monitoring.ResourceActionDelete, string(value), slack.NewTextBlockObject("plain_text", "Delete", false, false)).WithStyle(slack.StyleDanger)
+	deleteButton.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject("plain_text", "Delete resource?", false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("This will permanently delete `%s` in `%s` and cannot be undone.", resource.ID, resource.Region), false, false),
+		slack.NewTextBlockObject("plain_text", "Delete", false, false),
+		slack.NewTextBlockObject("plain_text", "Cancel", false, false),
+	)
+	elements = append(elements, deleteButton)
+
+	elements = append(elements, slack.NewButtonBlockElement(monitoring.ResourceActionNotMine, string(value), slack.NewTextBlockObject("plain_text", "Not mine", false, false)))
+
+	return slack.NewActionBlock(resourceActionsBlockId, elements...)
+}
+
+func getKeptUntilText(resource monitoring.CloudResource) string {
+	keepUntil, ok := resource.GetKeptUntil()
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf(":lock: *Kept Until*: `%s` by `%s`\n", keepUntil.Format("2 Jan, 2006"), resource.Tags[monitoring.KeptByTag])
+}
+
+// sendSlackActionReply sends a threaded reply with buttons to act on the resource, unless someone has already chosen to keep it
+func sendSlackActionReply(client *slack.Client, owners *OwnerResolver, channelId string, timestamp string, text string, reference monitoring.ResourceReference, resource monitoring.CloudResource) error {
+	keptUntilText := getKeptUntilText(resource)
+
+	if keptUntilText != "" {
+		return sendSlackReply(client, channelId, timestamp, text+keptUntilText)
+	}
+
+	// The actions are only implemented for AWS
+	if resource.Provider != monitoring.ProviderAWS {
+		return sendSlackReply(client, channelId, timestamp, text)
+	}
+
+	blocks := []slack.Block{getSlackSectionBlock(text), getResourceActionBlock(reference, owners.Resolve(resource))}
+	_, _, _, err := client.SendMessage(channelId, slack.MsgOptionCompose(slack.MsgOptionText(text, false), slack.MsgOptionBlocks(blocks...), slack.MsgOptionTS(timestamp)))
+
+	if err != nil {
+		return err
+	}
+
+	time.Sleep(throttleDuration)
+	return nil
+}
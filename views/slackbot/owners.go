@@ -0,0 +1,142 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/slack-go/slack"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+const slackOwnerMapPathEnv = "SLACK_OWNER_MAP_PATH"
+
+var ownerEmailTags = []string{"Email", "email", "OwnerEmail", "owner-email", "Owner", "owner", "CreatedBy", "created-by"}
+
+// OwnerMap is read from the file at SLACK_OWNER_MAP_PATH
+type OwnerMap struct {
+	// IAM user names, role session names or full principal ARNs to Slack user IDs
+	Principals map[string]string `json:"principals"`
+	// Email addresses to Slack user IDs, for people whose Slack email differs from the one they use in AWS
+	Emails map[string]string `json:"emails"`
+	// Appended to principal names that are not email addresses before looking them up in Slack
+	EmailDomain string `json:"emailDomain"`
+}
+
+type OwnerResolver struct {
+	ownerMap      OwnerMap
+	lookupByEmail func(email string) (string, error)
+	cache         map[string]string
+}
+
+func NewOwnerResolver(path string, client *slack.Client) (*OwnerResolver, error) {
+	var ownerMap OwnerMap
+
+	if path != "" {
+		ownerMapJson, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read Slack owner map %s: %s", path, err)
+		}
+
+		if err := json.Unmarshal(ownerMapJson, &ownerMap); err != nil {
+			return nil, fmt.Errorf("unable to parse Slack owner map %s: %s", path, err)
+		}
+	}
+
+	resolver := &OwnerResolver{
+		ownerMap: ownerMap,
+		cache:    make(map[string]string),
+	}
+
+	if client != nil {
+		resolver.lookupByEmail = func(email string) (string, error) {
+			user, err := client.GetUserByEmail(email)
+			if err != nil {
+				return "", err
+			}
+
+			return user.ID, nil
+		}
+	}
+
+	return resolver, nil
+}
+
+// Resolve returns the Slack user ID of whoever owns the resource, or an empty string if they cannot be found. Someone
+// who marked the resource as not theirs is skipped, so it falls to the next candidate or to the unowned channel.
+func (resolver *OwnerResolver) Resolve(resource monitoring.CloudResource) string {
+	disowned := resource.Tags[monitoring.NotMineTag]
+
+	isOwner := func(userId string) bool {
+		return userId != "" && userId != disowned
+	}
+
+	if resource.LaunchedBy != "" {
+		if userId := resolver.ResolvePrincipal(resource.LaunchedBy); isOwner(userId) {
+			return userId
+		}
+	}
+
+	for _, tag := range ownerEmailTags {
+		if value, ok := resource.Tags[tag]; ok && strings.Contains(value, "@") {
+			if userId := resolver.resolveEmail(value); isOwner(userId) {
+				return userId
+			}
+		}
+	}
+
+	return ""
+}
+
+func (resolver *OwnerResolver) ResolvePrincipal(principal string) string {
+	if userId, ok := resolver.ownerMap.Principals[principal]; ok {
+		return userId
+	}
+
+	name := monitoring.GetPrincipalName(principal)
+
+	if userId, ok := resolver.ownerMap.Principals[name]; ok {
+		return userId
+	}
+
+	if strings.Contains(name, "@") {
+		return resolver.resolveEmail(name)
+	}
+
+	if resolver.ownerMap.EmailDomain != "" {
+		return resolver.resolveEmail(fmt.Sprintf("%s@%s", name, resolver.ownerMap.EmailDomain))
+	}
+
+	return ""
+}
+
+func (resolver *OwnerResolver) resolveEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	for mappedEmail, userId := range resolver.ownerMap.Emails {
+		if strings.EqualFold(mappedEmail, email) {
+			return userId
+		}
+	}
+
+	if userId, ok := resolver.cache[email]; ok {
+		return userId
+	}
+
+	if resolver.lookupByEmail == nil {
+		return ""
+	}
+
+	userId, err := resolver.lookupByEmail(email)
+	if err != nil {
+		log.Printf("Unable to find Slack user for %s: %s", email, err)
+	}
+
+	resolver.cache[email] = userId
+	return userId
+}
+
+func getSlackMention(userId string) string {
+	return fmt.Sprintf("<@%s>", userId)
+}
@@ -0,0 +1,82 @@
+package slackbot
+
+import (
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+)
+
+// reportResources holds the top level of the cascading report, claimed resources are only reachable through their owners
+type reportResources struct {
+	CouchbaseClouds        []monitoring.CouchbaseCloud
+	CouchbaseCloudClusters []monitoring.CouchbaseCloudCluster
+	CloudformationStacks   []monitoring.CloudformationStack
+	EKSClusters            []monitoring.EKSCluster
+	EC2Instances           []monitoring.EC2Instance
+	StoppedEC2Instances    []monitoring.EC2Instance
+	EBSVolumes             []monitoring.EBSVolume
+	UnattachedEBSVolumes   []monitoring.EBSVolume
+	ResourcesByKind        map[string][]monitoring.ReportableResource
+}
+
+func getReportResources(ctx *monitoring.GlobalCloudContext) *reportResources {
+	report := &reportResources{
+		ResourcesByKind: map[string][]monitoring.ReportableResource{},
+	}
+
+	for _, couchbaseCloud := range ctx.CouchbaseClouds {
+		report.CouchbaseClouds = append(report.CouchbaseClouds, *couchbaseCloud)
+	}
+
+	for _, couchbaseCluster := range ctx.CouchbaseCloudClusters {
+		report.CouchbaseCloudClusters = append(report.CouchbaseCloudClusters, *couchbaseCluster)
+	}
+
+	reportedUtilisationClasses := monitoring.GetReportedUtilisationClasses()
+
+	for _, regionalCtx := range ctx.RegionalCloudContexts {
+		for _, cloudformationStack := range regionalCtx.CloudFormationStacks {
+			report.CloudformationStacks = append(report.CloudformationStacks, cloudformationStack)
+		}
+
+		for _, eksCluster := range regionalCtx.EKSClusters {
+			report.EKSClusters = append(report.EKSClusters, eksCluster)
+		}
+
+		for _, ec2Instance := range regionalCtx.EC2Instances {
+			if ec2Instance.IsStopped() {
+				continue
+			}
+
+			if reportedUtilisationClasses != nil && !reportedUtilisationClasses[ec2Instance.Utilisation.Class] {
+				continue
+			}
+
+			report.EC2Instances = append(report.EC2Instances, ec2Instance)
+		}
+
+		for _, ebsVolume := range regionalCtx.EBSVolumes {
+			if !ebsVolume.IsUnattached() {
+				report.EBSVolumes = append(report.EBSVolumes, ebsVolume)
+			}
+		}
+
+		for kind, resources := range regionalCtx.Resources {
+			for _, resource := range resources {
+				report.ResourcesByKind[kind] = append(report.ResourcesByKind[kind], resource)
+			}
+		}
+	}
+
+	report.StoppedEC2Instances = ctx.GetStoppedEC2Instances()
+	report.UnattachedEBSVolumes = ctx.GetUnattachedEBSVolumes()
+
+	sortByCost(report.CouchbaseClouds, report.CouchbaseCloudClusters, report.CloudformationStacks, report.EKSClusters, report.EC2Instances, report.EBSVolumes)
+
+	// Kinds that are not priced keep their scan order rather than being sorted on zero costs
+	for kind, resources := range report.ResourcesByKind {
+		if monitoring.ResourceKindPriced(kind) {
+			sortResourcesByCost(resources)
+		}
+	}
+
+	return report
+}
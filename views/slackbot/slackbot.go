@@ -7,6 +7,7 @@ import (
 	"github.com/slack-go/slack"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -39,55 +40,69 @@ func (bot *CloudMonitoringSlackBot) PostThreadedReport() error {
 		return fmt.Errorf("unable to post messages to Slack, no cloud context found")
 	}
 
-	var couchbaseClouds []monitoring.CouchbaseCloud
-	var couchbaseCloudClusters []monitoring.CouchbaseCloudCluster
-	var cloudformationStacks []monitoring.CloudformationStack
-	var eksClusters []monitoring.EKSCluster
-	var ec2Instances []monitoring.EC2Instance
-	var ebsVolumes []monitoring.EBSVolume
+	report := getReportResources(bot.GlobalCloudContext)
+	couchbaseClouds := report.CouchbaseClouds
+	couchbaseCloudClusters := report.CouchbaseCloudClusters
+	cloudformationStacks := report.CloudformationStacks
+	eksClusters := report.EKSClusters
+	ec2Instances := report.EC2Instances
+	stoppedEC2Instances := report.StoppedEC2Instances
+	ebsVolumes := report.EBSVolumes
+	unattachedEBSVolumes := report.UnattachedEBSVolumes
+	resourcesByKind := report.ResourcesByKind
 
-	for _, couchbaseCloud := range bot.GlobalCloudContext.CouchbaseClouds {
-		couchbaseClouds = append(couchbaseClouds, *couchbaseCloud)
-	}
+	client := slack.New(slackToken)
 
-	for _, couchbaseCluster := range bot.GlobalCloudContext.CouchbaseCloudClusters {
-		couchbaseCloudClusters = append(couchbaseCloudClusters, *couchbaseCluster)
+	owners, err := NewOwnerResolver(os.Getenv(slackOwnerMapPathEnv), client)
+	if err != nil {
+		return err
 	}
 
-	for _, regionalCtx := range bot.GlobalCloudContext.RegionalCloudContexts {
-		for _, cloudformationStack := range regionalCtx.CloudFormationStacks {
-			cloudformationStacks = append(cloudformationStacks, cloudformationStack)
-		}
+	header := getReportHeaderBlocks(bot.GlobalCloudContext)
+	couchbaseCloudBlocks := getCouchbaseCloudParentBlocks(couchbaseClouds)
+	couchbaseCloudClusterBlocks := getCouchbaseCloudClusterParentBlocks(couchbaseCloudClusters)
+	cloudformationBlocks := getCloudformationParentBlocks(cloudformationStacks)
+	eksBlocks := getEKSParentBlocks(eksClusters)
+	ec2Blocks := getEC2ParentBlocks(ec2Instances)
+	stoppedEC2Blocks := getStoppedEC2ParentBlocks(stoppedEC2Instances)
+	ebsBlocks := getEBSParentBlocks(ebsVolumes)
+	unattachedEBSBlocks := getUnattachedEBSParentBlocks(unattachedEBSVolumes)
 
-		for _, eksCluster := range regionalCtx.EKSClusters {
-			eksClusters = append(eksClusters, eksCluster)
-		}
+	_, err = sendSlackGroupMessage(client, slackChannel, header)
 
-		for _, ec2Instance := range regionalCtx.EC2Instances {
-			ec2Instances = append(ec2Instances, ec2Instance)
-		}
+	if err != nil {
+		return handleSlackMessageError(err)
+	}
+
+	if len(bot.GlobalCloudContext.ScanErrors) > 0 {
+		coverageGapBlocksTs, err := sendSlackGroupMessage(client, slackChannel, getCoverageGapParentBlocks(bot.GlobalCloudContext.ScanErrors))
 
-		for _, ebsVolume := range regionalCtx.EBSVolumes {
-			ebsVolumes = append(ebsVolumes, ebsVolume)
+		if err != nil {
+			return handleSlackMessageError(err)
 		}
+
+		sendCoverageGapReplies(client, slackChannel, bot.GlobalCloudContext.ScanErrors, coverageGapBlocksTs)
 	}
 
-	client := slack.New(slackToken)
+	if bot.GlobalCloudContext.History != nil && !bot.GlobalCloudContext.History.PreviousRunAt.IsZero() {
+		historyBlocksTs, err := sendSlackGroupMessage(client, slackChannel, getHistoryParentBlocks(bot.GlobalCloudContext.History))
 
-	header := getReportHeaderBlocks()
-	couchbaseCloudBlocks := getCouchbaseCloudParentBlocks(couchbaseClouds)
-	couchbaseCloudClusterBlocks := getCouchbaseCloudClusterParentBlocks(couchbaseCloudClusters)
-	cloudformationBlocks := getCloudformationParentBlocks(cloudformationStacks)
-	eksBlocks := getEKSParentBlocks(eksClusters)
-	ec2Blocks := getEC2ParentBlocks(ec2Instances)
-	ebsBlocks := getEBSParentBlocks(ebsVolumes)
+		if err != nil {
+			return handleSlackMessageError(err)
+		}
+
+		sendHistoryReplies(client, slackChannel, bot.GlobalCloudContext.History, historyBlocksTs)
+	}
 
-	_, err := sendSlackGroupMessage(client, slackChannel, header)
+	expiredResources := bot.GlobalCloudContext.GetExpiredResources()
+	expiredBlocksTs, err := sendSlackGroupMessage(client, slackChannel, getExpiredParentBlocks(expiredResources, bot.GlobalCloudContext.GetExpiryCounts()))
 
 	if err != nil {
 		return handleSlackMessageError(err)
 	}
 
+	sendExpiredReplies(client, owners, slackChannel, expiredResources, bot.GlobalCloudContext.ReapResults, expiredBlocksTs)
+
 	couchbaseCloudBlocksTs, err := sendSlackGroupMessage(client, slackChannel, couchbaseCloudBlocks)
 
 	if err != nil {
@@ -115,18 +130,48 @@ func (bot *CloudMonitoringSlackBot) PostThreadedReport() error {
 		return handleSlackMessageError(err)
 	}
 
+	stoppedEC2BlocksTs, err := sendSlackGroupMessage(client, slackChannel, stoppedEC2Blocks)
+
+	if err != nil {
+		return handleSlackMessageError(err)
+	}
+
 	ebsBlocksTs, err := sendSlackGroupMessage(client, slackChannel, ebsBlocks)
 
 	if err != nil {
 		return handleSlackMessageError(err)
 	}
 
+	unattachedEBSBlocksTs, err := sendSlackGroupMessage(client, slackChannel, unattachedEBSBlocks)
+
+	if err != nil {
+		return handleSlackMessageError(err)
+	}
+
 	sendCouchbaseCloudReplies(client, slackChannel, couchbaseClouds, couchbaseCloudBlocksTs)
 	sendCouchbaseCloudClusterReplies(client, slackChannel, couchbaseCloudClusters, couchbaseCloudClusterBlocksTs)
-	sendCloudformationStackReplies(client, slackChannel, cloudformationStacks, cloudformationBlocksTs)
-	sendEKSClusterReplies(client, slackChannel, eksClusters, eksBlocksTs)
-	sendEC2InstancesReplies(client, slackChannel, ec2Instances, ec2BlocksTs)
-	sendEBSInstancesReplies(client, slackChannel, ebsVolumes, ebsBlocksTs)
+	sendCloudformationStackReplies(client, owners, slackChannel, cloudformationStacks, cloudformationBlocksTs)
+	sendEKSClusterReplies(client, owners, slackChannel, eksClusters, eksBlocksTs)
+	sendEC2InstancesReplies(client, owners, slackChannel, ec2Instances, ec2BlocksTs)
+	sendStoppedEC2InstanceReplies(client, owners, slackChannel, stoppedEC2Instances, stoppedEC2BlocksTs)
+	sendEBSInstancesReplies(client, owners, slackChannel, ebsVolumes, ebsBlocksTs)
+	sendUnattachedEBSVolumeReplies(client, owners, slackChannel, unattachedEBSVolumes, unattachedEBSBlocksTs)
+
+	var kinds []string
+	for kind := range resourcesByKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		resourceBlocksTs, err := sendSlackGroupMessage(client, slackChannel, getResourceParentBlocks(kind, resourcesByKind[kind]))
+
+		if err != nil {
+			return handleSlackMessageError(err)
+		}
+
+		sendResourceReplies(client, owners, slackChannel, kind, resourcesByKind[kind], resourceBlocksTs)
+	}
 
 	return nil
 }
@@ -140,26 +185,90 @@ func sendSlackGroupMessage(client *slack.Client, channelId string, blocks []slac
 	return timestamp, nil
 }
 
-func getReportHeaderBlocks() []slack.Block {
+func getReportHeaderBlocks(ctx *monitoring.GlobalCloudContext) []slack.Block {
 	var blocks []slack.Block
-	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf("Below is a *cascading* report of all of our cloud infrastructure in AWS. If you have a cloud resource in the below list please take the time to consider if it is currently being used or will be used again today. If the answer is no, please delete the resource.\n\nIf you do have a need to keep a resource please try and ensure you are using as few resources as possible!\n")))
+	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf("Below is a *cascading* report of all of our cloud infrastructure across the scanned providers. If you have a cloud resource in the below list please take the time to consider if it is currently being used or will be used again today. If the answer is no, please delete the resource.\n\nIf you do have a need to keep a resource please try and ensure you are using as few resources as possible!\n")))
+
+	if len(ctx.AccountRegions) > 0 {
+		blocks = append(blocks, getSlackSectionBlock(getScannedRegionsText(ctx.AccountRegions)))
+	}
+
+	if len(ctx.RegionalCloudContexts) > 0 {
+		blocks = append(blocks, getSlackSectionBlock(getEstimatedCostText(ctx)))
+	}
+
+	return blocks
+}
+
+func getScannedRegionsText(accountRegions map[string][]string) string {
+	var accounts []string
+	for account := range accountRegions {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	var message bytes.Buffer
+	message.WriteString(":globe_with_meridians:  *Scanned regions*\n")
+
+	for _, account := range accounts {
+		regions := accountRegions[account]
+		message.WriteString(fmt.Sprintf("*%s* (%d): `%s`\n", account, len(regions), strings.Join(regions, ", ")))
+	}
+
+	return message.String()
+}
+
+func getCoverageGapParentBlocks(scanErrors []monitoring.ScanError) []slack.Block {
+	var blocks []slack.Block
+	blocks = append(blocks, getSlackDividerBlock())
+	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf(":warning:  *Coverage Gaps* (%d)\nThe following could not be scanned, so resources in them are missing from this report.", len(scanErrors))))
 	return blocks
 }
 
 func getCouchbaseCloudParentBlocks(couchbaseClouds []monitoring.CouchbaseCloud) []slack.Block {
+	unmatched := 0
+	for _, cloud := range couchbaseClouds {
+		if cloud.MatchStatus == monitoring.CouchbaseMatchUnmatched {
+			unmatched++
+		}
+	}
+
 	var blocks []slack.Block
 	blocks = append(blocks, getSlackDividerBlock())
-	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf("\n\n:thought_balloon:  *Couchbase Clouds* (%d)", len(couchbaseClouds))))
+	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf("\n\n:thought_balloon:  *Couchbase Clouds* (%d, %d with no cloud footprint)", len(couchbaseClouds), unmatched)))
 	return blocks
 }
 
 func getCouchbaseCloudClusterParentBlocks(couchbaseClusters []monitoring.CouchbaseCloudCluster) []slack.Block {
+	unmatched := 0
+	for _, cluster := range couchbaseClusters {
+		if isOrphanedCouchbaseCloudCluster(cluster) {
+			unmatched++
+		}
+	}
+
 	var blocks []slack.Block
 	blocks = append(blocks, getSlackDividerBlock())
-	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf(":snow_cloud:  *Couchbase Cloud Clusters* (%d)", len(couchbaseClusters))))
+	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf(":snow_cloud:  *Couchbase Cloud Clusters* (%d, %d with no cloud footprint)", len(couchbaseClusters), unmatched)))
 	return blocks
 }
 
+// Hosted clusters run outside of our cloud accounts so they are never expected to be matched
+func isOrphanedCouchbaseCloudCluster(cluster monitoring.CouchbaseCloudCluster) bool {
+	return cluster.Environment != "hosted" && cluster.MatchStatus == monitoring.CouchbaseMatchUnmatched
+}
+
+func getCouchbaseFootprintText(matchStatus string, matchedRegions []string) string {
+	switch matchStatus {
+	case monitoring.CouchbaseMatchUnmatched:
+		return ":warning: *Cloud footprint*: `none, orphaned in Couchbase Cloud`\n"
+	case monitoring.CouchbaseMatchMultipleRegions:
+		return fmt.Sprintf(":warning: *Cloud footprint*: `%s` (matched in %d regions)\n", strings.Join(matchedRegions, ", "), len(matchedRegions))
+	default:
+		return fmt.Sprintf("*Cloud footprint*: `%s`\n", strings.Join(matchedRegions, ", "))
+	}
+}
+
 func getCloudformationParentBlocks(cloudformationStacks []monitoring.CloudformationStack) []slack.Block {
 	var blocks []slack.Block
 	blocks = append(blocks, getSlackDividerBlock())
@@ -188,6 +297,13 @@ func getEBSParentBlocks(ebsVolumes []monitoring.EBSVolume) []slack.Block {
 	return blocks
 }
 
+func getResourceParentBlocks(kind string, resources []monitoring.ReportableResource) []slack.Block {
+	var blocks []slack.Block
+	blocks = append(blocks, getSlackDividerBlock())
+	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf(":package:  *%s* (%d)", kind, len(resources))))
+	return blocks
+}
+
 func handleSlackMessageError(err error) error {
 	return fmt.Errorf("unable to post messages to Slack: %s", err)
 }
@@ -200,6 +316,27 @@ func getSlackDividerBlock() *slack.DividerBlock {
 	return slack.NewDividerBlock()
 }
 
+func sendCoverageGapReplies(client *slack.Client, channelId string, scanErrors []monitoring.ScanError, timestamp string) {
+	log.Println("Sending throttled slack replies for coverage gaps")
+	for _, scanError := range scanErrors {
+		var message bytes.Buffer
+		message.WriteString(fmt.Sprintf("*Account*: `%s`\n", scanError.Account))
+
+		if scanError.Region != "" {
+			message.WriteString(fmt.Sprintf("*Region*: `%s`\n", scanError.Region))
+		} else {
+			message.WriteString("*Region*: `all`\n")
+		}
+
+		message.WriteString(fmt.Sprintf("*Failed to*: `%s`\n", scanError.Operation))
+		message.WriteString(fmt.Sprintf("*Reason*: ```%s```\n", scanError.Message))
+
+		if err := sendSlackReply(client, channelId, timestamp, message.String()); err != nil {
+			log.Printf("Unable to send Slack reply: %s", err)
+		}
+	}
+}
+
 func sendCouchbaseCloudReplies(client *slack.Client, channelId string, couchbaseClouds []monitoring.CouchbaseCloud, timestamp string) {
 	log.Println("Sending throttled slack replies for Couchbase Cloud")
 	for _, cloud := range couchbaseClouds {
@@ -209,7 +346,19 @@ func sendCouchbaseCloudReplies(client *slack.Client, channelId string, couchbase
 		message.WriteString(fmt.Sprintf("*Regions*: `AWS: [%s], Azure: [%s]`\n", cloud.CloudRegion.AwsRegion, cloud.CloudRegion.AzureRegion))
 		message.WriteString(fmt.Sprintf("*Virtual Network CIDR*: `%s`\n", cloud.VirtualNetworkCIDR))
 		message.WriteString(fmt.Sprintf("*EKS clusters*: `%d`\n", len(cloud.EKSClusters)))
+
+		if len(cloud.AKSClusters) > 0 || len(cloud.AzureVirtualMachines) > 0 {
+			message.WriteString(fmt.Sprintf("*AKS clusters*: `%d`\n", len(cloud.AKSClusters)))
+			message.WriteString(fmt.Sprintf("*Azure virtual machines*: `%d`\n", len(cloud.AzureVirtualMachines)))
+		}
+
+		if len(cloud.GKEClusters) > 0 || len(cloud.GCPInstances) > 0 {
+			message.WriteString(fmt.Sprintf("*GKE clusters*: `%d`\n", len(cloud.GKEClusters)))
+			message.WriteString(fmt.Sprintf("*Compute Engine instances*: `%d`\n", len(cloud.GCPInstances)))
+		}
 		message.WriteString(fmt.Sprintf("*Status*: `%s`\n", cloud.Status))
+		message.WriteString(getCouchbaseFootprintText(cloud.MatchStatus, cloud.MatchedRegions))
+		message.WriteString(getCostText(cloud.TotalCost()))
 
 		if err := sendSlackReply(client, channelId, timestamp, message.String()); err != nil {
 			log.Printf("Unable to send Slack reply: %s", err)
@@ -224,6 +373,8 @@ func sendCouchbaseCloudClusterReplies(client *slack.Client, channelId string, co
 		if cluster.Environment != "hosted" {
 			message.WriteString(fmt.Sprintf("*Node Count*: `%d`\n", cluster.NodeCount))
 			message.WriteString(fmt.Sprintf("*Services*: `%s`\n", strings.Join(cluster.Services, ", ")))
+			message.WriteString(getCouchbaseFootprintText(cluster.MatchStatus, cluster.MatchedRegions))
+			message.WriteString(getCostText(cluster.TotalCost()))
 		}
 
 		if err := sendSlackReply(client, channelId, timestamp, message.String()); err != nil {
@@ -232,7 +383,7 @@ func sendCouchbaseCloudClusterReplies(client *slack.Client, channelId string, co
 	}
 }
 
-func sendCloudformationStackReplies(client *slack.Client, channelId string, cloudformationStacks []monitoring.CloudformationStack, timestamp string) {
+func sendCloudformationStackReplies(client *slack.Client, owners *OwnerResolver, channelId string, cloudformationStacks []monitoring.CloudformationStack, timestamp string) {
 	log.Println("Sending throttled slack replies for Cloudformation stacks")
 	for _, cloudformationStack := range cloudformationStacks {
 		var message bytes.Buffer
@@ -244,34 +395,57 @@ func sendCloudformationStackReplies(client *slack.Client, channelId string, clou
 		}
 
 		message.WriteString(fmt.Sprintf("*Region*: `%s`\n", cloudformationStack.Region))
-		message.WriteString(fmt.Sprintf("*Resource Count*: `%d`\n", len(cloudformationStack.StackResourceList)))
+		message.WriteString(fmt.Sprintf("*Resource Count*: `%d`\n", len(cloudformationStack.Resources)))
 		message.WriteString(fmt.Sprintf("*Age*: `%s`\n", getAgeAsString(cloudformationStack.CreationDuration)))
 
 		if len(cloudformationStack.EC2Instances) > 0 {
 			message.WriteString(fmt.Sprintf("*EC2 Instances*: `%d`\n", len(cloudformationStack.EC2Instances)))
 		}
 
+		var kinds []string
+		for kind := range cloudformationStack.ReportableResources {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+
+		for _, kind := range kinds {
+			message.WriteString(fmt.Sprintf("*%s*: `%d`\n", kind, len(cloudformationStack.ReportableResources[kind])))
+		}
+
 		message.WriteString(fmt.Sprintf("*Created*: `%s`\n", cloudformationStack.CreatedAt.UTC().Format(dateLayout)))
+		message.WriteString(getCostText(cloudformationStack.TotalCost()))
+		message.WriteString(getExpiryText(cloudformationStack.Expiry))
+		message.WriteString(getLaunchedByText(owners, cloudformationStack.CloudResource))
 		message.WriteString(fmt.Sprintf("*Account*: `%s`\n", cloudformationStack.Account))
 
-		if err := sendSlackReply(client, channelId, timestamp, message.String()); err != nil {
+		reference := monitoring.ResourceReference{Account: cloudformationStack.Account, Region: cloudformationStack.Region, Type: monitoring.NodeCloudformationStack, ID: cloudformationStack.ID}
+		if err := sendSlackActionReply(client, owners, channelId, timestamp, message.String(), reference, cloudformationStack.CloudResource); err != nil {
 			log.Printf("Unable to send Slack reply: %s", err)
 		}
 	}
 }
 
-func sendEKSClusterReplies(client *slack.Client, channelId string, eksClusters []monitoring.EKSCluster, timestamp string) {
+func sendEKSClusterReplies(client *slack.Client, owners *OwnerResolver, channelId string, eksClusters []monitoring.EKSCluster, timestamp string) {
 	log.Println("Sending throttled slack replies for EKS clusters")
 	for _, eksCluster := range eksClusters {
 		var message bytes.Buffer
 		message.WriteString(fmt.Sprintf("*Name*: `%s`\n", eksCluster.Name))
 		message.WriteString(fmt.Sprintf("*Worker Nodes*: `%d`\n", len(eksCluster.EC2Instances)))
-		message.WriteString(fmt.Sprintf("*Subnets*: `%d`\n", len(eksCluster.Subnets)))
+
+		if len(eksCluster.LoadBalancers) > 0 {
+			message.WriteString(fmt.Sprintf("*Load Balancers*: `%d`\n", len(eksCluster.LoadBalancers)))
+		}
+
+		message.WriteString(fmt.Sprintf("*Subnets*: `%d`\n", len(eksCluster.SubnetIDs)))
 		message.WriteString(fmt.Sprintf("*Age*: `%s`\n", getAgeAsString(eksCluster.Age)))
 		message.WriteString(fmt.Sprintf("Created: `%s`\n", eksCluster.CreatedAt.UTC().Format(dateLayout)))
+		message.WriteString(getCostText(eksCluster.TotalCost()))
+		message.WriteString(getExpiryText(eksCluster.Expiry))
+		message.WriteString(getLaunchedByText(owners, eksCluster.CloudResource))
 		message.WriteString(fmt.Sprintf("*Account*: `%s`\n", eksCluster.Account))
 
-		if err := sendSlackReply(client, channelId, timestamp, message.String()); err != nil {
+		reference := monitoring.ResourceReference{Account: eksCluster.Account, Region: eksCluster.Region, Type: monitoring.NodeEKSCluster, ID: eksCluster.Name}
+		if err := sendSlackActionReply(client, owners, channelId, timestamp, message.String(), reference, eksCluster.CloudResource); err != nil {
 			log.Printf("Unable to send Slack reply: %s", err)
 		}
 	}
@@ -310,7 +484,7 @@ func getAgeAsString(age time.Duration) string {
 	return strconv.Itoa(months) + monthText + strconv.Itoa(days/7) + weekText
 }
 
-func sendEC2InstancesReplies(client *slack.Client, channelId string, ec2Instances []monitoring.EC2Instance, timestamp string) {
+func sendEC2InstancesReplies(client *slack.Client, owners *OwnerResolver, channelId string, ec2Instances []monitoring.EC2Instance, timestamp string) {
 	log.Println("Sending throttled slack replies for EC2 instances")
 	for _, ec2Instance := range ec2Instances {
 		var message bytes.Buffer
@@ -323,6 +497,7 @@ func sendEC2InstancesReplies(client *slack.Client, channelId string, ec2Instance
 
 		message.WriteString(fmt.Sprintf("*Region*: `%s`\n", ec2Instance.Region))
 		message.WriteString(fmt.Sprintf("*Type*: `%s`\n", ec2Instance.InstanceType))
+		message.WriteString(fmt.Sprintf("*State*: `%s`\n", ec2Instance.State))
 
 		if ec2Instance.Platform != "" {
 			message.WriteString(fmt.Sprintf("*Platform*: `%s`\n", ec2Instance.Platform))
@@ -333,15 +508,20 @@ func sendEC2InstancesReplies(client *slack.Client, channelId string, ec2Instance
 		}
 
 		message.WriteString(fmt.Sprintf("*Launch Time*: `%s`\n", ec2Instance.CreatedAt.UTC().Format(dateLayout)))
+		message.WriteString(getUtilisationText(ec2Instance.Utilisation))
+		message.WriteString(getCostText(ec2Instance.TotalCost()))
+		message.WriteString(getExpiryText(ec2Instance.Expiry))
+		message.WriteString(getLaunchedByText(owners, ec2Instance.CloudResource))
 		message.WriteString(fmt.Sprintf("*Account*: `%s`\n", ec2Instance.Account))
 
-		if err := sendSlackReply(client, channelId, timestamp, message.String()); err != nil {
+		reference := monitoring.ResourceReference{Account: ec2Instance.Account, Region: ec2Instance.Region, Type: monitoring.NodeEC2Instance, ID: ec2Instance.ID}
+		if err := sendSlackActionReply(client, owners, channelId, timestamp, message.String(), reference, ec2Instance.CloudResource); err != nil {
 			log.Printf("Unable to send Slack reply: %s", err)
 		}
 	}
 }
 
-func sendEBSInstancesReplies(client *slack.Client, channelId string, ebsVolumes []monitoring.EBSVolume, timestamp string) {
+func sendEBSInstancesReplies(client *slack.Client, owners *OwnerResolver, channelId string, ebsVolumes []monitoring.EBSVolume, timestamp string) {
 	log.Println("Sending throttled slack replies for EBS volumes")
 	for _, ebsVolume := range ebsVolumes {
 		var message bytes.Buffer
@@ -353,18 +533,77 @@ func sendEBSInstancesReplies(client *slack.Client, channelId string, ebsVolumes
 		}
 
 		message.WriteString(fmt.Sprintf("*Region*: `%s`\n", ebsVolume.Region))
-		message.WriteString(fmt.Sprintf("*Type*: `%s`\n", *ebsVolume.Type))
+		message.WriteString(fmt.Sprintf("*Type*: `%s`\n", ebsVolume.VolumeType))
 		message.WriteString(fmt.Sprintf("*Size GiB*: `%d`\n", ebsVolume.SizeGiB))
 		message.WriteString(fmt.Sprintf("*State*: `%s`\n", ebsVolume.State))
 		message.WriteString(fmt.Sprintf("*Created*: `%s`\n", ebsVolume.CreatedAt.UTC().Format(dateLayout)))
+		message.WriteString(getCostText(ebsVolume.EstimatedCost))
+		message.WriteString(getExpiryText(ebsVolume.Expiry))
+		message.WriteString(getLaunchedByText(owners, ebsVolume.CloudResource))
 		message.WriteString(fmt.Sprintf("*Account*: `%s`\n", ebsVolume.Account))
 
+		reference := monitoring.ResourceReference{Account: ebsVolume.Account, Region: ebsVolume.Region, Type: monitoring.NodeEBSVolume, ID: ebsVolume.ID}
+		if err := sendSlackActionReply(client, owners, channelId, timestamp, message.String(), reference, ebsVolume.CloudResource); err != nil {
+			log.Printf("Unable to send Slack reply: %s", err)
+		}
+	}
+}
+
+func sendResourceReplies(client *slack.Client, owners *OwnerResolver, channelId string, kind string, resources []monitoring.ReportableResource, timestamp string) {
+	log.Printf("Sending throttled slack replies for %s", kind)
+	for _, resource := range resources {
+		var message bytes.Buffer
+		cloudResource := resource.Resource()
+
+		if cloudResource.Name != "" {
+			message.WriteString(fmt.Sprintf("*Name*: `%s`\n", cloudResource.Name))
+		} else {
+			message.WriteString(fmt.Sprintf("*ID*: `%s`\n", cloudResource.ID))
+		}
+
+		message.WriteString(fmt.Sprintf("*Region*: `%s`\n", cloudResource.Region))
+
+		for _, field := range resource.ReportFields() {
+			message.WriteString(fmt.Sprintf("*%s*: `%s`\n", field.Label, field.Value))
+		}
+
+		if orphanCandidate, ok := resource.(monitoring.OrphanCandidate); ok && orphanCandidate.OrphanReason() != "" {
+			message.WriteString(fmt.Sprintf(":warning: *Orphaned*: `%s`\n", orphanCandidate.OrphanReason()))
+		}
+
+		if !cloudResource.CreatedAt.IsZero() {
+			message.WriteString(fmt.Sprintf("*Created*: `%s`\n", cloudResource.CreatedAt.UTC().Format(dateLayout)))
+		}
+
+		if totalCost := monitoring.GetReportableTotalCost(resource); totalCost.Priced {
+			message.WriteString(getCostText(totalCost))
+		}
+
+		message.WriteString(getLaunchedByText(owners, cloudResource))
+		message.WriteString(fmt.Sprintf("*Account*: `%s`\n", cloudResource.Account))
+
 		if err := sendSlackReply(client, channelId, timestamp, message.String()); err != nil {
 			log.Printf("Unable to send Slack reply: %s", err)
 		}
 	}
 }
 
+func getLaunchedByText(owners *OwnerResolver, resource monitoring.CloudResource) string {
+	var message bytes.Buffer
+
+	if resource.LaunchedBy == "" {
+		message.WriteString("*Launched By*: `unknown`\n")
+	} else {
+		message.WriteString(fmt.Sprintf("*Launched By*: `%s` (%s)\n", resource.LaunchedBy, resource.LaunchedBySource))
+	}
+
+	if userId := owners.Resolve(resource); userId != "" {
+		message.WriteString(fmt.Sprintf("*Owner*: %s\n", getSlackMention(userId)))
+	}
+
+	return message.String()
+}
+
 func sendSlackReply(client *slack.Client, channelId string, timestamp string, text string) error {
 	_, _, _, err := client.SendMessage(channelId, slack.MsgOptionCompose(slack.MsgOptionText(text, false), slack.MsgOptionTS(timestamp)))
 
@@ -0,0 +1,40 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/slack-go/slack"
+	"log"
+	"os"
+)
+
+// UploadFile shares a generated file, such as the HTML report, in the report channel
+func UploadFile(filename string, title string, content []byte) error {
+	slackToken := os.Getenv(slackBotTokenEnv)
+
+	if slackToken == "" {
+		return fmt.Errorf("unable to upload to Slack, %s environment variable not found", slackBotTokenEnv)
+	}
+
+	slackChannel := os.Getenv(slackChannelIdEnv)
+
+	if slackChannel == "" {
+		return fmt.Errorf("unable to upload to Slack, %s environment variable not found", slackChannelIdEnv)
+	}
+
+	client := slack.New(slackToken)
+
+	_, err := client.UploadFile(slack.FileUploadParameters{
+		Reader:   bytes.NewReader(content),
+		Filename: filename,
+		Title:    title,
+		Channels: []string{slackChannel},
+	})
+
+	if err != nil {
+		return fmt.Errorf("unable to upload %s to Slack: %s", filename, err)
+	}
+
+	log.Printf("Uploaded %s to Slack", filename)
+	return nil
+}
@@ -0,0 +1,21 @@
+package slackbot
+
+import (
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+)
+
+func getUtilisationText(utilisation monitoring.Utilisation) string {
+	switch utilisation.Class {
+	case "", monitoring.UtilisationUnknown:
+		return "*Utilisation*: `unknown, no CloudWatch data`\n"
+	}
+
+	emoji := ""
+	if utilisation.Class == monitoring.UtilisationIdle {
+		emoji = ":zzz: "
+	}
+
+	return fmt.Sprintf("%s*Utilisation*: `%s` (CPU avg %.1f%%, max %.1f%%, network %.1f MB/day over %.1f of the last %d days)\n",
+		emoji, utilisation.Class, utilisation.CPUAverage, utilisation.CPUMax, utilisation.GetNetworkBytesPerDay()/1000/1000, utilisation.Covered.Hours()/24, int(utilisation.Window.Hours()/24))
+}
@@ -0,0 +1,72 @@
+package slackbot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/couchbaselabs/cloud-monitoring-tool/monitoring"
+	"github.com/slack-go/slack"
+	"log"
+	"time"
+)
+
+func getUnattachedEBSParentBlocks(ebsVolumes []monitoring.EBSVolume) []slack.Block {
+	waste := monitoring.Cost{}
+	for _, ebsVolume := range ebsVolumes {
+		waste = waste.Add(ebsVolume.EstimatedCost)
+	}
+
+	var blocks []slack.Block
+	blocks = append(blocks, getSlackDividerBlock())
+	blocks = append(blocks, getSlackSectionBlock(fmt.Sprintf(":wastebasket:  *Unattached EBS Volumes* (%d, wasting `$%.2f/month`)", len(ebsVolumes), waste.Monthly())))
+	return blocks
+}
+
+func getDetachedText(ebsVolume monitoring.EBSVolume, now time.Time) string {
+	days, ok := ebsVolume.GetDaysDetached(now)
+
+	if !ok {
+		return "*Detached For*: `over 90 days, before CloudTrail history`\n"
+	}
+
+	if ebsVolume.DetachedAtSource == monitoring.DetachedAtSourceCreated {
+		return fmt.Sprintf("*Detached For*: `%d days` (never attached since creation)\n", days)
+	}
+
+	return fmt.Sprintf("*Detached For*: `%d days` (since %s)\n", days, ebsVolume.DetachedAt.UTC().Format(dateLayout))
+}
+
+func sendUnattachedEBSVolumeReplies(client *slack.Client, owners *OwnerResolver, channelId string, ebsVolumes []monitoring.EBSVolume, timestamp string) {
+	log.Println("Sending throttled slack replies for unattached EBS volumes")
+	now := time.Now()
+
+	for _, ebsVolume := range ebsVolumes {
+		var message bytes.Buffer
+
+		if ebsVolume.Name != "" {
+			message.WriteString(fmt.Sprintf("*Name*: `%s`\n", ebsVolume.Name))
+		} else {
+			message.WriteString(fmt.Sprintf("*ID*: `%s`\n", ebsVolume.ID))
+		}
+
+		message.WriteString(fmt.Sprintf("*Region*: `%s`\n", ebsVolume.Region))
+		message.WriteString(fmt.Sprintf("*Type*: `%s`\n", ebsVolume.VolumeType))
+		message.WriteString(fmt.Sprintf("*Size GiB*: `%d`\n", ebsVolume.SizeGiB))
+		message.WriteString(getDetachedText(ebsVolume, now))
+
+		if ebsVolume.EstimatedCost.Priced {
+			message.WriteString(fmt.Sprintf("*Monthly Waste*: `$%.2f`\n", ebsVolume.EstimatedCost.Monthly()))
+		} else {
+			message.WriteString("*Monthly Waste*: `unknown`\n")
+		}
+
+		message.WriteString(fmt.Sprintf("*Recommended Action*: `%s`\n", ebsVolume.GetRecommendedAction()))
+		message.WriteString(getExpiryText(ebsVolume.Expiry))
+		message.WriteString(getLaunchedByText(owners, ebsVolume.CloudResource))
+		message.WriteString(fmt.Sprintf("*Account*: `%s`\n", ebsVolume.Account))
+
+		reference := monitoring.ResourceReference{Account: ebsVolume.Account, Region: ebsVolume.Region, Type: monitoring.NodeEBSVolume, ID: ebsVolume.ID}
+		if err := sendSlackActionReply(client, owners, channelId, timestamp, message.String(), reference, ebsVolume.CloudResource); err != nil {
+			log.Printf("Unable to send Slack reply: %s", err)
+		}
+	}
+}